@@ -0,0 +1,103 @@
+// Command abyssctl runs commands on the remote console of a running engine.
+//
+// With arguments, it runs them as one command line. Without, it runs each
+// line read from stdin. It exits with status 1 when a command fails.
+//
+//	abyssctl -addr unix:/tmp/abyss.sock scenes
+//	echo "get FpsCap" | abyssctl -json
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/console"
+)
+
+const defaultTimeout = 10 * time.Second
+
+func main() {
+	address := flag.String("addr", console.DefaultRemoteAddress(), "console address, unix:<path> or tcp:<host>:<port>")
+	timeout := flag.Duration("timeout", defaultTimeout, "connection and command timeout")
+	printJSON := flag.Bool("json", false, "print the responses as JSON lines")
+
+	flag.Parse()
+
+	client, err := console.Dial(*address, *timeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ok, err := run(client, flag.Args(), *printJSON)
+
+	_ = client.Close()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// run runs the command lines and tells whether they all succeeded
+func run(client *console.Client, args []string, printJSON bool) (bool, error) {
+	if len(args) > 0 {
+		return runLine(client, console.Join(args), printJSON)
+	}
+
+	allOK := true
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		ok, err := runLine(client, line, printJSON)
+		if err != nil {
+			return false, err
+		}
+
+		allOK = allOK && ok
+	}
+
+	return allOK, scanner.Err()
+}
+
+func runLine(client *console.Client, line string, printJSON bool) (bool, error) {
+	response, err := client.Run(line)
+	if err != nil {
+		return false, err
+	}
+
+	if printJSON {
+		data, err := json.Marshal(response)
+		if err != nil {
+			return false, err
+		}
+
+		fmt.Println(string(data))
+
+		return response.OK, nil
+	}
+
+	if response.Output != "" {
+		fmt.Println(response.Output)
+	}
+
+	if !response.OK {
+		fmt.Fprintln(os.Stderr, "error:", response.Error)
+	}
+
+	return response.OK, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/configuration"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+	"github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/mpqfile"
+	log "github.com/sirupsen/logrus"
+)
+
+// mpqLoader reads the game files from the mpq files, in the load order of
+// the configuration. The scenes load in the background while the loading
+// screen is shown, so the reads are serialized: an mpq file seeks and reads
+// from a single file handle.
+type mpqLoader struct {
+	archives []*mpqfile.MPQ
+	mutex    sync.Mutex
+}
+
+var _ resource.FileLoader = &mpqLoader{}
+
+// ReadFile reads a file from the first mpq file that has it
+func (l *mpqLoader) ReadFile(path string) ([]byte, error) {
+	// the mpq files name their files like data\global\ui\...
+	name := strings.TrimPrefix(strings.ReplaceAll(path, "/", "\\"), "\\")
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, archive := range l.archives {
+		if data, err := archive.ReadFile(name); err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s not found in the mpq files", path)
+}
+
+// Close closes the mpq files, the engine does it once shut down
+func (l *mpqLoader) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var firstErr error
+
+	for _, archive := range l.archives {
+		if err := archive.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// getFileLoader opens the mpq files, the missing ones are skipped so that the
+// engine starts without the game files
+func getFileLoader(config *configuration.Configuration) resource.FileLoader {
+	result := &mpqLoader{}
+
+	for _, name := range config.MpqLoadOrder {
+		archive, err := mpqfile.FromFile(filepath.Join(config.MpqPath, name))
+		if err != nil {
+			log.Warnf("skipping %s: %v", name, err)
+			continue
+		}
+
+		result.archives = append(result.archives, archive)
+	}
+
+	return result
+}
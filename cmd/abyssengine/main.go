@@ -1,28 +1,52 @@
 package main
 
 import (
+	"context"
+	"io"
 	"os"
+	"runtime"
 	"strings"
 
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine"
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend/headlessgraphicsbackend"
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend/sdl2graphicsbackend"
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend/headlessinputbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend/scriptedinputbackend"
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend/sdl2inputbackend"
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/configuration"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/console"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/scenemanager"
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/fx"
 )
 
-const sdl2BackendName = "sdl2"
+const (
+	sdl2BackendName     = "sdl2"
+	headlessBackendName = "headless"
+)
+
+// SDL expects the window and its events to be handled on the main thread, the
+// main goroutine is locked to it before main runs
+func init() {
+	runtime.LockOSThread()
+}
 
 func main() {
 	configureLogging()
 	log.Printf("AbyssEngine - The open source ARPG engine")
 
-	fx.New(
+	var (
+		e      *engine.Engine
+		config *configuration.Configuration
+	)
+
+	// the constructors run here, so the backends are created on the main thread
+	app := fx.New(
 		fx.Options(fx.NopLogger),
+		fx.StopTimeout(engine.DefaultShutdownTimeout),
 		fx.Provide(
 			// Standard instantiations
 			configuration.New,
@@ -30,15 +54,117 @@ func main() {
 			scenemanager.New,
 
 			// Implementation-specific instantiations
+			getFileLoader,
 			getGraphicsBackend,
 			getInputBackend,
 		),
-		fx.Invoke(run),
-	).Run()
+		fx.Invoke(registerShutdownHooks),
+		fx.Populate(&e, &config),
+	)
+
+	if err := run(app, e, config); err != nil {
+		log.Fatal(err)
+	}
 }
 
-func run(e *engine.Engine) error {
-	return e.Run()
+// run starts the application and runs the engine on the main thread until it
+// quits or SIGINT or SIGTERM is received.
+//
+// The loop isn't wired into fx start and stop hooks: SDL must be driven from
+// the main thread, which init locks, while a start hook has to return and fx
+// may run the stop hooks on another goroutine. So the engine runs and shuts
+// down here, and whatever it uses is closed by its own shutdown hooks, in
+// order, rather than by fx stop hooks.
+func run(app *fx.App, e *engine.Engine, config *configuration.Configuration) error {
+	startCtx, cancelStart := context.WithTimeout(context.Background(), app.StartTimeout())
+	defer cancelStart()
+
+	if err := app.Start(startCtx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := app.Done()
+
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	runErr := serveRemoteConsole(e, config.RemoteConsole)
+	if runErr == nil {
+		runErr = e.Run(ctx)
+	}
+
+	cancel()
+
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), app.StopTimeout())
+	defer cancelStop()
+
+	if err := e.Shutdown(stopCtx); err != nil {
+		log.Error(err)
+	}
+
+	if err := app.Stop(stopCtx); err != nil {
+		log.Error(err)
+	}
+
+	return runErr
+}
+
+func registerShutdownHooks(e *engine.Engine, config *configuration.Configuration, loader resource.FileLoader,
+	gfx graphicsbackend.Interface, input inputbackend.Interface) {
+	e.OnShutdown("configuration", engine.ShutdownOrderResources, func(context.Context) error {
+		return config.Save()
+	})
+
+	if closer, ok := loader.(io.Closer); ok {
+		e.OnShutdown("archives", engine.ShutdownOrderResources, func(context.Context) error {
+			return closer.Close()
+		})
+	}
+
+	if recording, ok := input.(*inputRecording); ok {
+		e.OnShutdown("input recording", engine.ShutdownOrderResources, func(context.Context) error {
+			return recording.Close()
+		})
+	}
+
+	if closer, ok := gfx.(io.Closer); ok {
+		e.OnShutdown("graphics", engine.ShutdownOrderBackends, func(context.Context) error {
+			return closer.Close()
+		})
+	}
+}
+
+// serveRemoteConsole exposes the console on the configured address, an empty
+// address leaves it off
+func serveRemoteConsole(e *engine.Engine, address string) error {
+	if address == "" {
+		return nil
+	}
+
+	server, err := console.Listen(e.Console(), address)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("remote console listening on %s", address)
+
+	go func() {
+		if err := server.Serve(); err != nil {
+			log.Error(err)
+		}
+	}()
+
+	e.OnShutdown("remote console", engine.ShutdownOrderGame, func(context.Context) error {
+		return server.Close()
+	})
+
+	return nil
 }
 
 func configureLogging() {
@@ -60,13 +186,60 @@ func getGraphicsBackend(config *configuration.Configuration) graphicsbackend.Int
 			log.Panic(err)
 		}
 
+		return result
+	case headlessBackendName:
+		result, err := headlessgraphicsbackend.Create(config.HeadlessFrames)
+		if err != nil {
+			log.Panic(err)
+		}
+
 		return result
 	default:
 		panic("unknown backend")
 	}
 }
 
+// inputRecording is the input backend when the input is recorded, Close
+// writes the recording and closes its file
+type inputRecording struct {
+	*scriptedinputbackend.Recorder
+	file *os.File
+}
+
+func (r *inputRecording) Close() error {
+	if err := r.Flush(); err != nil {
+		_ = r.file.Close()
+		return err
+	}
+
+	return r.file.Close()
+}
+
 func getInputBackend(config *configuration.Configuration) inputbackend.Interface {
+	result := createInputBackend(config)
+
+	if config.InputRecording == "" {
+		return result
+	}
+
+	file, err := os.Create(config.InputRecording)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	return &inputRecording{Recorder: scriptedinputbackend.NewRecorder(result, file), file: file}
+}
+
+func createInputBackend(config *configuration.Configuration) inputbackend.Interface {
+	if config.InputScript != "" {
+		result, err := scriptedinputbackend.CreateFromFile(config.InputScript)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		return result
+	}
+
 	switch strings.ToLower(config.Backend) {
 	case sdl2BackendName:
 		result, err := sdl2inputbackend.Create()
@@ -74,6 +247,13 @@ func getInputBackend(config *configuration.Configuration) inputbackend.Interface
 			log.Panic(err)
 		}
 
+		return result
+	case headlessBackendName:
+		result, err := headlessinputbackend.Create()
+		if err != nil {
+			log.Panic(err)
+		}
+
 		return result
 	default:
 		panic("unknown backend")
@@ -0,0 +1,238 @@
+package headlessgraphicsbackend
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
+)
+
+const (
+	screenWidth  = graphicsbackend.ScreenWidth
+	screenHeight = graphicsbackend.ScreenHeight
+)
+
+var _ graphicsbackend.Interface = &HeadlessGraphicsBackend{}
+var _ graphicsbackend.Surface = &HeadlessGraphicsBackend{}
+var _ graphicsbackend.Screenshotter = &HeadlessGraphicsBackend{}
+
+// HeadlessGraphicsBackend renders in memory, it needs no display nor GPU.
+// Surfaces are drawn onto the screen image, which Render presents as the
+// frame and then clears for the next one.
+type HeadlessGraphicsBackend struct {
+	screen       *image.RGBA
+	frame        *image.RGBA
+	frameCount   int
+	framePattern string
+	fullScreen   bool
+	vsync        bool
+	fps          float64
+	fpsFrames    int
+	fpsStart     time.Time
+	now          func() time.Time
+}
+
+// Create creates a headless backend. A non-empty framePattern saves each
+// frame as a PNG file, the pattern holds a %d verb for the frame number, such
+// as "frames/%06d.png".
+func Create(framePattern string) (*HeadlessGraphicsBackend, error) {
+	result := &HeadlessGraphicsBackend{
+		screen:       newScreen(),
+		frame:        newScreen(),
+		framePattern: framePattern,
+		now:          time.Now,
+	}
+
+	if framePattern != "" {
+		if err := checkFramePattern(framePattern); err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fmt.Sprintf(framePattern, 0)), 0o750); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func newScreen() *image.RGBA {
+	result := image.NewRGBA(image.Rect(0, 0, screenWidth, screenHeight))
+	draw.Draw(result, result.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	return result
+}
+
+// Render presents the frame drawn so far and clears the screen
+func (r *HeadlessGraphicsBackend) Render() error {
+	r.frame, r.screen = r.screen, r.frame
+	draw.Draw(r.screen, r.screen.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	r.updateFPS()
+
+	if r.framePattern != "" {
+		if err := r.SavePNG(fmt.Sprintf(r.framePattern, r.frameCount)); err != nil {
+			return err
+		}
+	}
+
+	r.frameCount++
+
+	return nil
+}
+
+func (r *HeadlessGraphicsBackend) updateFPS() {
+	now := r.now()
+
+	if r.fpsStart.IsZero() {
+		r.fpsStart = now
+	}
+
+	r.fpsFrames++
+
+	if elapsed := now.Sub(r.fpsStart); elapsed >= time.Second {
+		r.fps = float64(r.fpsFrames) / elapsed.Seconds()
+		r.fpsFrames = 0
+		r.fpsStart = now
+	}
+}
+
+// FrameCount returns the number of frames rendered
+func (r *HeadlessGraphicsBackend) FrameCount() int {
+	return r.frameCount
+}
+
+// Screenshot returns a copy of the last frame
+func (r *HeadlessGraphicsBackend) Screenshot() image.Image {
+	result := image.NewRGBA(r.frame.Bounds())
+	copy(result.Pix, r.frame.Pix)
+
+	return result
+}
+
+// SavePNG saves the last frame as a PNG file
+func (r *HeadlessGraphicsBackend) SavePNG(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, r.frame); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (r *HeadlessGraphicsBackend) GetRendererName() string {
+	return "Headless"
+}
+
+func (r *HeadlessGraphicsBackend) SetWindowIcon(fileName string) {
+}
+
+func (r *HeadlessGraphicsBackend) IsFullScreen() bool {
+	return r.fullScreen
+}
+
+func (r *HeadlessGraphicsBackend) SetFullScreen(fullScreen bool) {
+	r.fullScreen = fullScreen
+}
+
+func (r *HeadlessGraphicsBackend) SetVSyncEnabled(vsync bool) {
+	r.vsync = vsync
+}
+
+func (r *HeadlessGraphicsBackend) GetVSyncEnabled() bool {
+	return r.vsync
+}
+
+func (r *HeadlessGraphicsBackend) GetCursorPos() (int, int) {
+	return 0, 0
+}
+
+func (r *HeadlessGraphicsBackend) CurrentFPS() float64 {
+	return r.fps
+}
+
+// NewSurface creates a surface from ARGB8888 pixels stored little endian, the
+// same layout as the SDL2 backend
+func (r *HeadlessGraphicsBackend) NewSurface(width, height int, pixelData *[]byte) (graphicsbackend.Surface, error) {
+	if len(*pixelData) < width*height*4 {
+		return nil, fmt.Errorf("%d bytes of pixel data for a %dx%d surface", len(*pixelData), width, height)
+	}
+
+	result := CreateHeadlessSurface(width, height)
+	result.image.Pix = fromARGB(*pixelData, width*height)
+
+	return result, nil
+}
+
+func (r *HeadlessGraphicsBackend) RenderTo(graphicsbackend.Surface) error {
+	return errors.New("cannot render the output surface to another surface")
+}
+
+func (r *HeadlessGraphicsBackend) RenderToWithOpacity(surface graphicsbackend.Surface, _ float64) error {
+	return r.RenderTo(surface)
+}
+
+// fromARGB converts little endian ARGB8888 pixels to premultiplied RGBA
+func fromARGB(data []byte, pixels int) []byte {
+	result := make([]byte, pixels*4)
+
+	for idx := 0; idx < pixels; idx++ {
+		b, g, red, a := uint32(data[idx*4]), uint32(data[idx*4+1]), uint32(data[idx*4+2]), uint32(data[idx*4+3])
+
+		result[idx*4+0] = uint8(red * a / 0xff)
+		result[idx*4+1] = uint8(g * a / 0xff)
+		result[idx*4+2] = uint8(b * a / 0xff)
+		result[idx*4+3] = uint8(a)
+	}
+
+	return result
+}
+
+// checkFramePattern makes sure the pattern formats the frame number with
+// exactly one integer verb, and no other verb
+func checkFramePattern(pattern string) error {
+	verbs := 0
+
+	for idx := 0; idx < len(pattern); idx++ {
+		if pattern[idx] != '%' {
+			continue
+		}
+
+		// skip the flags, width and precision
+		idx++
+		for idx < len(pattern) && strings.ContainsRune("+-# 0123456789.", rune(pattern[idx])) {
+			idx++
+		}
+
+		if idx == len(pattern) {
+			return fmt.Errorf("frame pattern %q ends within a verb", pattern)
+		}
+
+		switch pattern[idx] {
+		case '%':
+		case 'd', 'b', 'o', 'x', 'X':
+			verbs++
+		default:
+			return fmt.Errorf("frame pattern %q has a verb %%%c, the frame number is an integer", pattern, pattern[idx])
+		}
+	}
+
+	if verbs != 1 {
+		return fmt.Errorf("frame pattern %q needs exactly one verb for the frame number, such as %%06d", pattern)
+	}
+
+	return nil
+}
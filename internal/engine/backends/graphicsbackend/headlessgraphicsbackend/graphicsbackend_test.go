@@ -0,0 +1,126 @@
+package headlessgraphicsbackend
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// argb returns the pixel data of a surface filled with one color
+func argb(width, height int, c color.NRGBA) []byte {
+	result := make([]byte, width*height*4)
+
+	for idx := 0; idx < width*height; idx++ {
+		result[idx*4+0] = c.B
+		result[idx*4+1] = c.G
+		result[idx*4+2] = c.R
+		result[idx*4+3] = c.A
+	}
+
+	return result
+}
+
+func TestHeadlessGraphicsBackend_Render(t *testing.T) {
+	assert := testify.New(t)
+
+	gfx, err := Create("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	red := argb(4, 2, color.NRGBA{R: 0xff, A: 0xff})
+	background, err := gfx.NewSurface(4, 2, &red)
+	assert.NoError(err)
+
+	blue := argb(2, 2, color.NRGBA{B: 0xff, A: 0x80})
+	overlay, err := gfx.NewSurface(2, 2, &blue)
+	assert.NoError(err)
+
+	assert.NoError(background.RenderTo(gfx))
+	assert.NoError(overlay.RenderTo(gfx))
+	assert.Error(gfx.RenderTo(background))
+
+	assert.Equal(color.RGBA{A: 0xff}, gfx.Screenshot().At(0, 0), "nothing is shown before Render")
+
+	assert.NoError(gfx.Render())
+	assert.Equal(1, gfx.FrameCount())
+
+	frame := gfx.Screenshot()
+	assert.Equal(image.Rect(0, 0, screenWidth, screenHeight), frame.Bounds())
+	assert.Equal(color.RGBA{R: 0x7f, B: 0x80, A: 0xff}, frame.At(1, 1), "blended over")
+	assert.Equal(color.RGBA{R: 0xff, A: 0xff}, frame.At(3, 1))
+	assert.Equal(color.RGBA{A: 0xff}, frame.At(4, 0))
+
+	assert.NoError(gfx.Render())
+	assert.Equal(color.RGBA{A: 0xff}, gfx.Screenshot().At(3, 1), "the screen is cleared after each frame")
+
+	assert.NoError(background.RenderToWithOpacity(gfx, 0.5))
+	assert.NoError(overlay.RenderToWithOpacity(gfx, 0))
+	assert.NoError(gfx.Render())
+	assert.Equal(color.RGBA{R: 0x80, A: 0xff}, gfx.Screenshot().At(1, 1), "drawn at half opacity")
+
+	short := []byte{0, 0, 0}
+	_, err = gfx.NewSurface(1, 1, &short)
+	assert.Error(err)
+}
+
+func TestHeadlessGraphicsBackend_SaveFrames(t *testing.T) {
+	assert := testify.New(t)
+
+	dir, err := ioutil.TempDir("", "headless")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	gfx, err := Create(filepath.Join(dir, "frames", "%03d.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	green := argb(1, 1, color.NRGBA{G: 0xff, A: 0xff})
+	surface, err := gfx.NewSurface(1, 1, &green)
+	assert.NoError(err)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(surface.RenderTo(gfx))
+		assert.NoError(gfx.Render())
+	}
+
+	for i := 0; i < 2; i++ {
+		file, err := os.Open(filepath.Join(dir, "frames", fmt.Sprintf("%03d.png", i)))
+		if !assert.NoError(err) {
+			continue
+		}
+
+		decoded, err := png.Decode(file)
+		_ = file.Close()
+
+		assert.NoError(err)
+		assert.Equal(color.RGBA{G: 0xff, A: 0xff}, color.RGBAModel.Convert(decoded.At(0, 0)))
+	}
+
+	path := filepath.Join(dir, "shot.png")
+	assert.NoError(gfx.SavePNG(path))
+	assert.FileExists(path)
+}
+
+func TestCreate_FramePattern(t *testing.T) {
+	assert := testify.New(t)
+
+	assert.NoError(checkFramePattern("frames/%06d.png"))
+	assert.NoError(checkFramePattern("100%%/frame-%x.png"))
+
+	for _, pattern := range []string{"frames.png", "%d-%d.png", "%s.png", "frame%", "100%.png"} {
+		_, err := Create(pattern)
+		assert.Error(err, pattern)
+	}
+}
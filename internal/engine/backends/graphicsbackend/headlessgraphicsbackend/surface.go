@@ -0,0 +1,57 @@
+package headlessgraphicsbackend
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
+)
+
+var _ graphicsbackend.Surface = &HeadlessSurface{}
+
+// HeadlessSurface is an in-memory image
+type HeadlessSurface struct {
+	image *image.RGBA
+}
+
+// CreateHeadlessSurface creates a transparent surface
+func CreateHeadlessSurface(width, height int) *HeadlessSurface {
+	result := &HeadlessSurface{
+		image: image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+
+	return result
+}
+
+// Image returns the pixels of the surface
+func (s *HeadlessSurface) Image() *image.RGBA {
+	return s.image
+}
+
+// RenderTo draws the surface over the top left corner of another headless
+// surface or of the screen
+func (s *HeadlessSurface) RenderTo(targetSurface graphicsbackend.Surface) error {
+	return s.RenderToWithOpacity(targetSurface, 1)
+}
+
+// RenderToWithOpacity draws the surface like RenderTo, with its alpha scaled
+// by the opacity
+func (s *HeadlessSurface) RenderToWithOpacity(targetSurface graphicsbackend.Surface, opacity float64) error {
+	var target *image.RGBA
+
+	switch t := targetSurface.(type) {
+	case *HeadlessSurface:
+		target = t.image
+	case *HeadlessGraphicsBackend:
+		target = t.screen
+	default:
+		return fmt.Errorf("cannot render a headless surface to a %T", targetSurface)
+	}
+
+	mask := image.NewUniform(color.Alpha{A: graphicsbackend.OpacityAlpha(opacity)})
+	draw.DrawMask(target, s.image.Bounds(), s.image, image.Point{}, mask, image.Point{}, draw.Over)
+
+	return nil
+}
@@ -1,5 +1,16 @@
 package graphicsbackend
 
+import (
+	"image"
+	"math"
+)
+
+// The screen is drawn at this size, the backends scale it to the window
+const (
+	ScreenWidth  = 800
+	ScreenHeight = 600
+)
+
 type Interface interface {
 	GetRendererName() string
 	SetWindowIcon(fileName string)
@@ -15,4 +26,18 @@ type Interface interface {
 
 type Surface interface {
 	RenderTo(surface Surface) error
+	// RenderToWithOpacity draws the surface with its alpha scaled by the
+	// opacity, which goes from 0 (invisible) to 1 (as RenderTo)
+	RenderToWithOpacity(surface Surface, opacity float64) error
+}
+
+// OpacityAlpha converts an opacity to an alpha value, clamping it to [0, 1]
+func OpacityAlpha(opacity float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, opacity)) * math.MaxUint8))
+}
+
+// Screenshotter is implemented by the backends that can read back the last
+// frame they rendered
+type Screenshotter interface {
+	Screenshot() image.Image
 }
@@ -7,8 +7,8 @@ import (
 )
 
 const (
-	screenWidth  = 800
-	screenHeight = 600
+	screenWidth  = graphicsbackend.ScreenWidth
+	screenHeight = graphicsbackend.ScreenHeight
 )
 
 var _ graphicsbackend.Interface = &SDL2GraphicsBackend{}
@@ -19,14 +19,12 @@ type SDL2GraphicsBackend struct {
 	renderer *sdl.Renderer
 }
 
+// Render presents the frame drawn so far and clears the screen, the same as
+// the headless backend
 func (r *SDL2GraphicsBackend) Render() error {
-	if err := r.renderer.Clear(); err != nil {
-		return err
-	}
-
 	r.renderer.Present()
 
-	return nil
+	return r.renderer.Clear()
 }
 
 func Create() (*SDL2GraphicsBackend, error) {
@@ -56,10 +54,10 @@ func Create() (*SDL2GraphicsBackend, error) {
 		return nil, err
 	}
 
-	if err := r.SetLogicalSize(800, 600); err != nil {
+	if err := r.SetLogicalSize(screenWidth, screenHeight); err != nil {
 		return nil, err
 	}
-	window.SetMinimumSize(800, 600)
+	window.SetMinimumSize(screenWidth, screenHeight)
 
 	result := &SDL2GraphicsBackend{
 		window:   window,
@@ -69,6 +67,21 @@ func Create() (*SDL2GraphicsBackend, error) {
 	return result, nil
 }
 
+// Close destroys the renderer and the window and shuts SDL down
+func (r *SDL2GraphicsBackend) Close() error {
+	if err := r.renderer.Destroy(); err != nil {
+		return err
+	}
+
+	if err := r.window.Destroy(); err != nil {
+		return err
+	}
+
+	sdl.Quit()
+
+	return nil
+}
+
 func (r *SDL2GraphicsBackend) GetRendererName() string {
 	return "SDL2"
 }
@@ -114,6 +127,11 @@ func (r *SDL2GraphicsBackend) NewSurface(width, height int, pixelData *[]byte) (
 		return nil, err
 	}
 
+	// without it the alpha of the pixels is ignored
+	if err = texture.SetBlendMode(sdl.BLENDMODE_BLEND); err != nil {
+		return nil, err
+	}
+
 	result := CreateSDL2Surface(r.renderer, texture, int32(width), int32(height))
 
 	return result, nil
@@ -123,3 +141,7 @@ func (r *SDL2GraphicsBackend) RenderTo(graphicsbackend.Surface) error {
 	return errors.New("cannot render the output surface to another surface")
 }
 
+func (r *SDL2GraphicsBackend) RenderToWithOpacity(surface graphicsbackend.Surface, _ float64) error {
+	return r.RenderTo(surface)
+}
+
@@ -1,6 +1,8 @@
 package sdl2graphicsbackend
 
 import (
+	"fmt"
+
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -24,14 +26,36 @@ func CreateSDL2Surface(renderer *sdl.Renderer, 	texture *sdl.Texture, width, hei
 	return result
 }
 
+// RenderTo draws the surface over the top left corner of another SDL2 surface
+// or of the screen
 func (s SDL2Surface) RenderTo(targetSurface graphicsbackend.Surface) error {
-	sdlTargetSurface := targetSurface.(*SDL2Surface)
+	return s.RenderToWithOpacity(targetSurface, 1)
+}
+
+// RenderToWithOpacity draws the surface like RenderTo, with the alpha
+// modulation of its texture set from the opacity
+func (s SDL2Surface) RenderToWithOpacity(targetSurface graphicsbackend.Surface, opacity float64) error {
+	var target *sdl.Texture
+
+	switch t := targetSurface.(type) {
+	case *SDL2Surface:
+		target = t.texture
+	case *SDL2GraphicsBackend:
+		// a nil target is the screen
+	default:
+		return fmt.Errorf("cannot render an SDL2 surface to a %T", targetSurface)
+	}
 
 	destRect := &sdl.Rect{
 		W: s.width, H: s.height,
 	}
 
-	if err := s.renderer.SetRenderTarget(sdlTargetSurface.texture); err != nil {
+	if err := s.renderer.SetRenderTarget(target); err != nil {
+		return err
+	}
+
+	// the texture keeps its alpha modulation, it is set on every draw
+	if err := s.texture.SetAlphaMod(graphicsbackend.OpacityAlpha(opacity)); err != nil {
 		return err
 	}
 
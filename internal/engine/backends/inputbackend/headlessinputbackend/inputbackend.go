@@ -0,0 +1,61 @@
+package headlessinputbackend
+
+import (
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+)
+
+var _ inputbackend.Interface = &HeadlessInputBackend{}
+
+// HeadlessInputBackend goes with the headless graphics backend, there is no
+// window to read input from so no key or button is ever pressed
+type HeadlessInputBackend struct{}
+
+func Create() (*HeadlessInputBackend, error) {
+	result := &HeadlessInputBackend{}
+
+	return result, nil
+}
+
+func (i *HeadlessInputBackend) Process() error {
+	return nil
+}
+
+func (i *HeadlessInputBackend) CursorPosition() (x int, y int) {
+	return 0, 0
+}
+
+func (i *HeadlessInputBackend) InputChars() []rune {
+	return []rune{}
+}
+
+func (i *HeadlessInputBackend) IsKeyPressed(key inputbackend.Key) bool {
+	return false
+}
+
+func (i *HeadlessInputBackend) IsKeyJustPressed(key inputbackend.Key) bool {
+	return false
+}
+
+func (i *HeadlessInputBackend) IsKeyJustReleased(key inputbackend.Key) bool {
+	return false
+}
+
+func (i *HeadlessInputBackend) KeyMods() inputbackend.KeyMod {
+	return 0
+}
+
+func (i *HeadlessInputBackend) IsMouseButtonPressed(button inputbackend.MouseButton) bool {
+	return false
+}
+
+func (i *HeadlessInputBackend) IsMouseButtonJustPressed(button inputbackend.MouseButton) bool {
+	return false
+}
+
+func (i *HeadlessInputBackend) IsMouseButtonJustReleased(button inputbackend.MouseButton) bool {
+	return false
+}
+
+func (i *HeadlessInputBackend) KeyPressDuration(key inputbackend.Key) int {
+	return 0
+}
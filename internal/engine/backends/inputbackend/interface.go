@@ -2,7 +2,7 @@ package inputbackend
 
 // Interface represents an interface offering Keyboard and Mouse interactions.
 type Interface interface {
-	// Process processes any events.
+	// Process processes any events. It returns ErrQuitRequested when the user asked to quit.
 	Process() error
 	// CursorPosition returns a position of a mouse cursor relative to the game screen (window).
 	CursorPosition() (x int, y int)
@@ -14,6 +14,8 @@ type Interface interface {
 	IsKeyJustPressed(key Key) bool
 	// IsKeyJustReleased checks if the provided key is just transitioned from down to up.
 	IsKeyJustReleased(key Key) bool
+	// KeyMods returns the modifier keys that are down.
+	KeyMods() KeyMod
 	// IsMouseButtonPressed checks if the provided mouse button is down.
 	IsMouseButtonPressed(button MouseButton) bool
 	// IsMouseButtonJustPressed checks if the provided mouse button is just transitioned from up to down.
@@ -0,0 +1,135 @@
+package inputbackend
+
+import (
+	"fmt"
+	"strings"
+)
+
+var keyNames = [...]string{
+	Key0:              "0",
+	Key1:              "1",
+	Key2:              "2",
+	Key3:              "3",
+	Key4:              "4",
+	Key5:              "5",
+	Key6:              "6",
+	Key7:              "7",
+	Key8:              "8",
+	Key9:              "9",
+	KeyA:              "A",
+	KeyB:              "B",
+	KeyC:              "C",
+	KeyD:              "D",
+	KeyE:              "E",
+	KeyF:              "F",
+	KeyG:              "G",
+	KeyH:              "H",
+	KeyI:              "I",
+	KeyJ:              "J",
+	KeyK:              "K",
+	KeyL:              "L",
+	KeyM:              "M",
+	KeyN:              "N",
+	KeyO:              "O",
+	KeyP:              "P",
+	KeyQ:              "Q",
+	KeyR:              "R",
+	KeyS:              "S",
+	KeyT:              "T",
+	KeyU:              "U",
+	KeyV:              "V",
+	KeyW:              "W",
+	KeyX:              "X",
+	KeyY:              "Y",
+	KeyZ:              "Z",
+	KeyApostrophe:     "Apostrophe",
+	KeyBackslash:      "Backslash",
+	KeyBackspace:      "Backspace",
+	KeyCapsLock:       "CapsLock",
+	KeyComma:          "Comma",
+	KeyDelete:         "Delete",
+	KeyDown:           "Down",
+	KeyEnd:            "End",
+	KeyEnter:          "Enter",
+	KeyEqual:          "Equal",
+	KeyEscape:         "Escape",
+	KeyF1:             "F1",
+	KeyF2:             "F2",
+	KeyF3:             "F3",
+	KeyF4:             "F4",
+	KeyF5:             "F5",
+	KeyF6:             "F6",
+	KeyF7:             "F7",
+	KeyF8:             "F8",
+	KeyF9:             "F9",
+	KeyF10:            "F10",
+	KeyF11:            "F11",
+	KeyF12:            "F12",
+	KeyGraveAccent:    "GraveAccent",
+	KeyHome:           "Home",
+	KeyInsert:         "Insert",
+	KeyKP0:            "KP0",
+	KeyKP1:            "KP1",
+	KeyKP2:            "KP2",
+	KeyKP3:            "KP3",
+	KeyKP4:            "KP4",
+	KeyKP5:            "KP5",
+	KeyKP6:            "KP6",
+	KeyKP7:            "KP7",
+	KeyKP8:            "KP8",
+	KeyKP9:            "KP9",
+	KeyKPAdd:          "KPAdd",
+	KeyKPDecimal:      "KPDecimal",
+	KeyKPDivide:       "KPDivide",
+	KeyKPEnter:        "KPEnter",
+	KeyKPEqual:        "KPEqual",
+	KeyKPMultiply:     "KPMultiply",
+	KeyKPSubtract:     "KPSubtract",
+	KeyLeft:           "Left",
+	KeyLeftBracket:    "LeftBracket",
+	KeyMenu:           "Menu",
+	KeyMinus:          "Minus",
+	KeyNumLock:        "NumLock",
+	KeyPageDown:       "PageDown",
+	KeyPageUp:         "PageUp",
+	KeyPause:          "Pause",
+	KeyPeriod:         "Period",
+	KeyPrintScreen:    "PrintScreen",
+	KeyRight:          "Right",
+	KeyRightBracket:   "RightBracket",
+	KeyScrollLock:     "ScrollLock",
+	KeySemicolon:      "Semicolon",
+	KeySlash:          "Slash",
+	KeySpace:          "Space",
+	KeyTab:            "Tab",
+	KeyUp:             "Up",
+	KeyAlt:            "Alt",
+	KeyControl:        "Control",
+	KeyShift:          "Shift",
+	KeyTilde:          "Tilde",
+	KeyMouse3:         "Mouse3",
+	KeyMouse4:         "Mouse4",
+	KeyMouse5:         "Mouse5",
+	KeyMouseWheelUp:   "MouseWheelUp",
+	KeyMouseWheelDown: "MouseWheelDown",
+}
+
+// String returns the name of the key, such as A, F1 or KPEnter
+func (k Key) String() string {
+	if k < KeyMin || k > KeyMax {
+		return fmt.Sprintf("Key(%d)", int(k))
+	}
+
+	return keyNames[k]
+}
+
+// ParseKey returns the key of a name returned by String, ignoring case
+func ParseKey(name string) (Key, error) {
+	for key := KeyMin; key <= KeyMax; key++ {
+		if strings.EqualFold(keyNames[key], name) {
+			return key, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown key %q", name)
+}
@@ -0,0 +1,33 @@
+package inputbackend
+
+import (
+	"fmt"
+	"strings"
+)
+
+var mouseButtonNames = [...]string{
+	MouseButtonLeft:   "Left",
+	MouseButtonMiddle: "Middle",
+	MouseButtonRight:  "Right",
+}
+
+// String returns the name of the button, Left, Middle or Right
+func (b MouseButton) String() string {
+	if b < MouseButtonMin || b > MouseButtonMax {
+		return fmt.Sprintf("MouseButton(%d)", int(b))
+	}
+
+	return mouseButtonNames[b]
+}
+
+// ParseMouseButton returns the button of a name returned by String, ignoring
+// case
+func ParseMouseButton(name string) (MouseButton, error) {
+	for button := MouseButtonMin; button <= MouseButtonMax; button++ {
+		if strings.EqualFold(mouseButtonNames[button], name) {
+			return button, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown mouse button %q", name)
+}
@@ -0,0 +1,8 @@
+package inputbackend
+
+import "errors"
+
+// ErrQuitRequested is returned by Process when the user asked to quit, for
+// example by closing the window. It is not a failure, the engine stops
+// normally.
+var ErrQuitRequested = errors.New("quit requested")
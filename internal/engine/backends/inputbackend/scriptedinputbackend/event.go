@@ -0,0 +1,240 @@
+package scriptedinputbackend
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+)
+
+// EventType is the kind of an input event
+type EventType int
+
+// Event types
+const (
+	EventKeyDown EventType = iota
+	EventKeyUp
+	EventMouseDown
+	EventMouseUp
+	EventMouseMove
+	EventText
+	EventQuit
+)
+
+var eventTypeNames = [...]string{
+	EventKeyDown:   "keydown",
+	EventKeyUp:     "keyup",
+	EventMouseDown: "mousedown",
+	EventMouseUp:   "mouseup",
+	EventMouseMove: "mousemove",
+	EventText:      "text",
+	EventQuit:      "quit",
+}
+
+func (t EventType) String() string {
+	if t < EventKeyDown || t > EventQuit {
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+
+	return eventTypeNames[t]
+}
+
+// Event is an input event happening at a simulation tick. Only the fields of
+// its type are used.
+type Event struct {
+	Tick   uint64
+	Type   EventType
+	Key    inputbackend.Key
+	Button inputbackend.MouseButton
+	X, Y   int
+	Text   string
+}
+
+// KeyDown returns an event pressing a key
+func KeyDown(tick uint64, key inputbackend.Key) Event {
+	return Event{Tick: tick, Type: EventKeyDown, Key: key}
+}
+
+// KeyUp returns an event releasing a key
+func KeyUp(tick uint64, key inputbackend.Key) Event {
+	return Event{Tick: tick, Type: EventKeyUp, Key: key}
+}
+
+// MouseDown returns an event pressing a mouse button
+func MouseDown(tick uint64, button inputbackend.MouseButton) Event {
+	return Event{Tick: tick, Type: EventMouseDown, Button: button}
+}
+
+// MouseUp returns an event releasing a mouse button
+func MouseUp(tick uint64, button inputbackend.MouseButton) Event {
+	return Event{Tick: tick, Type: EventMouseUp, Button: button}
+}
+
+// MouseMove returns an event moving the cursor
+func MouseMove(tick uint64, x, y int) Event {
+	return Event{Tick: tick, Type: EventMouseMove, X: x, Y: y}
+}
+
+// Text returns an event typing characters
+func Text(tick uint64, text string) Event {
+	return Event{Tick: tick, Type: EventText, Text: text}
+}
+
+// Quit returns an event asking to quit
+func Quit(tick uint64) Event {
+	return Event{Tick: tick, Type: EventQuit}
+}
+
+// String formats the event as a line of a timeline file, such as
+// "12 keydown A" or `40 text "hello"`
+func (e Event) String() string {
+	switch e.Type {
+	case EventKeyDown, EventKeyUp:
+		return fmt.Sprintf("%d %s %s", e.Tick, e.Type, e.Key)
+	case EventMouseDown, EventMouseUp:
+		return fmt.Sprintf("%d %s %s", e.Tick, e.Type, e.Button)
+	case EventMouseMove:
+		return fmt.Sprintf("%d %s %d %d", e.Tick, e.Type, e.X, e.Y)
+	case EventText:
+		return fmt.Sprintf("%d %s %s", e.Tick, e.Type, strconv.Quote(e.Text))
+	default:
+		return fmt.Sprintf("%d %s", e.Tick, e.Type)
+	}
+}
+
+// ParseEvent parses a line formatted by Event.String
+func ParseEvent(line string) (Event, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Event{}, fmt.Errorf("expected a tick and an event in %q", line)
+	}
+
+	tick, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return Event{}, fmt.Errorf("invalid tick %q", fields[0])
+	}
+
+	args := fields[2:]
+	expectArgs := func(count int) error {
+		if len(args) != count {
+			return fmt.Errorf("%s expects %d arguments in %q", fields[1], count, line)
+		}
+
+		return nil
+	}
+
+	switch strings.ToLower(fields[1]) {
+	case "keydown", "keyup":
+		if err := expectArgs(1); err != nil {
+			return Event{}, err
+		}
+
+		key, err := inputbackend.ParseKey(args[0])
+		if err != nil {
+			return Event{}, err
+		}
+
+		if strings.EqualFold(fields[1], "keyup") {
+			return KeyUp(tick, key), nil
+		}
+
+		return KeyDown(tick, key), nil
+	case "mousedown", "mouseup":
+		if err := expectArgs(1); err != nil {
+			return Event{}, err
+		}
+
+		button, err := inputbackend.ParseMouseButton(args[0])
+		if err != nil {
+			return Event{}, err
+		}
+
+		if strings.EqualFold(fields[1], "mouseup") {
+			return MouseUp(tick, button), nil
+		}
+
+		return MouseDown(tick, button), nil
+	case "mousemove":
+		if err := expectArgs(2); err != nil {
+			return Event{}, err
+		}
+
+		x, errX := strconv.Atoi(args[0])
+		y, errY := strconv.Atoi(args[1])
+
+		if errX != nil || errY != nil {
+			return Event{}, fmt.Errorf("invalid position in %q", line)
+		}
+
+		return MouseMove(tick, x, y), nil
+	case "text":
+		quoted := strings.TrimSpace(line[strings.Index(line, fields[1])+len(fields[1]):])
+
+		text, err := strconv.Unquote(quoted)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid text %s: %w", quoted, err)
+		}
+
+		return Text(tick, text), nil
+	case "quit":
+		if err := expectArgs(0); err != nil {
+			return Event{}, err
+		}
+
+		return Quit(tick), nil
+	default:
+		return Event{}, fmt.Errorf("unknown event %q", fields[1])
+	}
+}
+
+// ReadTimeline reads events, one per line. Blank lines and lines starting
+// with # are skipped. The events are sorted by tick, keeping the order of
+// the events of a tick.
+func ReadTimeline(r io.Reader) ([]Event, error) {
+	var result []Event
+
+	scanner := bufio.NewScanner(r)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		event, err := ParseEvent(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		result = append(result, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sortEvents(result)
+
+	return result, nil
+}
+
+// WriteTimeline writes events in the format of ReadTimeline
+func WriteTimeline(w io.Writer, events []Event) error {
+	for _, event := range events {
+		if _, err := fmt.Fprintln(w, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Tick < events[j].Tick
+	})
+}
@@ -0,0 +1,196 @@
+package scriptedinputbackend
+
+import (
+	"os"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+)
+
+var _ inputbackend.Interface = &ScriptedInputBackend{}
+
+// ScriptedInputBackend plays a timeline of events. The engine processes input
+// once per simulation tick, so the Nth call to Process applies the events of
+// tick N, counted from 0.
+type ScriptedInputBackend struct {
+	events []Event
+	tick   uint64
+
+	keys                map[inputbackend.Key]uint64
+	keysJustPressed     map[inputbackend.Key]bool
+	keysJustReleased    map[inputbackend.Key]bool
+	buttons             map[inputbackend.MouseButton]bool
+	buttonsJustPressed  map[inputbackend.MouseButton]bool
+	buttonsJustReleased map[inputbackend.MouseButton]bool
+	cursorX, cursorY    int
+	chars               []rune
+}
+
+// Create creates a backend playing the given events
+func Create(events []Event) (*ScriptedInputBackend, error) {
+	result := &ScriptedInputBackend{
+		keys:    make(map[inputbackend.Key]uint64),
+		buttons: make(map[inputbackend.MouseButton]bool),
+	}
+
+	result.Inject(events...)
+	result.clearTransitions()
+
+	return result, nil
+}
+
+// CreateFromFile creates a backend playing a timeline file, see ReadTimeline
+func CreateFromFile(path string) (*ScriptedInputBackend, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := ReadTimeline(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Create(events)
+}
+
+// Inject adds events to the timeline. Events of ticks already processed are
+// applied by the next Process.
+func (i *ScriptedInputBackend) Inject(events ...Event) {
+	i.events = append(i.events, events...)
+	sortEvents(i.events)
+}
+
+// Tick returns the tick the next Process applies
+func (i *ScriptedInputBackend) Tick() uint64 {
+	return i.tick
+}
+
+// Done tells whether every event was applied
+func (i *ScriptedInputBackend) Done() bool {
+	return len(i.events) == 0
+}
+
+func (i *ScriptedInputBackend) clearTransitions() {
+	i.keysJustPressed = make(map[inputbackend.Key]bool)
+	i.keysJustReleased = make(map[inputbackend.Key]bool)
+	i.buttonsJustPressed = make(map[inputbackend.MouseButton]bool)
+	i.buttonsJustReleased = make(map[inputbackend.MouseButton]bool)
+	i.chars = []rune{}
+}
+
+// Process applies the events of the current tick, it returns
+// inputbackend.ErrQuitRequested when one of them is a quit event
+func (i *ScriptedInputBackend) Process() error {
+	i.clearTransitions()
+
+	quit := false
+	applied := 0
+
+	for _, event := range i.events {
+		if event.Tick > i.tick {
+			break
+		}
+
+		applied++
+
+		switch event.Type {
+		case EventKeyDown:
+			if _, found := i.keys[event.Key]; !found {
+				i.keys[event.Key] = i.tick
+			}
+
+			i.keysJustPressed[event.Key] = true
+		case EventKeyUp:
+			delete(i.keys, event.Key)
+			i.keysJustReleased[event.Key] = true
+		case EventMouseDown:
+			i.buttons[event.Button] = true
+			i.buttonsJustPressed[event.Button] = true
+		case EventMouseUp:
+			delete(i.buttons, event.Button)
+			i.buttonsJustReleased[event.Button] = true
+		case EventMouseMove:
+			i.cursorX, i.cursorY = event.X, event.Y
+		case EventText:
+			i.chars = append(i.chars, []rune(event.Text)...)
+		case EventQuit:
+			quit = true
+		}
+	}
+
+	i.events = i.events[applied:]
+	i.tick++
+
+	if quit {
+		return inputbackend.ErrQuitRequested
+	}
+
+	return nil
+}
+
+func (i *ScriptedInputBackend) CursorPosition() (x int, y int) {
+	return i.cursorX, i.cursorY
+}
+
+func (i *ScriptedInputBackend) InputChars() []rune {
+	return i.chars
+}
+
+func (i *ScriptedInputBackend) IsKeyPressed(key inputbackend.Key) bool {
+	_, found := i.keys[key]
+	return found
+}
+
+func (i *ScriptedInputBackend) IsKeyJustPressed(key inputbackend.Key) bool {
+	return i.keysJustPressed[key]
+}
+
+func (i *ScriptedInputBackend) IsKeyJustReleased(key inputbackend.Key) bool {
+	return i.keysJustReleased[key]
+}
+
+// KeyMods returns the modifiers of the modifier keys that are down
+func (i *ScriptedInputBackend) KeyMods() inputbackend.KeyMod {
+	var result inputbackend.KeyMod
+
+	if i.IsKeyPressed(inputbackend.KeyAlt) {
+		result |= inputbackend.KeyModAlt
+	}
+
+	if i.IsKeyPressed(inputbackend.KeyControl) {
+		result |= inputbackend.KeyModControl
+	}
+
+	if i.IsKeyPressed(inputbackend.KeyShift) {
+		result |= inputbackend.KeyModShift
+	}
+
+	return result
+}
+
+func (i *ScriptedInputBackend) IsMouseButtonPressed(button inputbackend.MouseButton) bool {
+	return i.buttons[button]
+}
+
+func (i *ScriptedInputBackend) IsMouseButtonJustPressed(button inputbackend.MouseButton) bool {
+	return i.buttonsJustPressed[button]
+}
+
+func (i *ScriptedInputBackend) IsMouseButtonJustReleased(button inputbackend.MouseButton) bool {
+	return i.buttonsJustReleased[button]
+}
+
+// KeyPressDuration returns for how many ticks the key has been down, counting
+// the current one
+func (i *ScriptedInputBackend) KeyPressDuration(key inputbackend.Key) int {
+	pressed, found := i.keys[key]
+	if !found {
+		return 0
+	}
+
+	return int(i.tick - pressed)
+}
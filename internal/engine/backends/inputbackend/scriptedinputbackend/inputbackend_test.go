@@ -0,0 +1,172 @@
+package scriptedinputbackend
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+	testify "github.com/stretchr/testify/assert"
+)
+
+const testTimeline = `
+# walk and open the inventory
+0 mousemove 400 300
+1 mousedown Left
+3 mouseup left
+4 keydown I
+4 keyup I
+5 keydown Shift
+6 text "hi there"
+8 keyup Shift
+9 quit
+`
+
+func TestTimeline(t *testing.T) {
+	assert := testify.New(t)
+
+	events, err := ReadTimeline(strings.NewReader(testTimeline))
+	assert.NoError(err)
+	assert.Len(events, 9)
+	assert.Equal(MouseMove(0, 400, 300), events[0])
+	assert.Equal(MouseUp(3, inputbackend.MouseButtonLeft), events[2])
+	assert.Equal(Text(6, "hi there"), events[6])
+
+	var buffer bytes.Buffer
+	assert.NoError(WriteTimeline(&buffer, events))
+
+	again, err := ReadTimeline(&buffer)
+	assert.NoError(err)
+	assert.Equal(events, again)
+
+	_, err = ReadTimeline(strings.NewReader("0 keydown A\n1 keydown NoSuchKey\n"))
+	assert.EqualError(err, `line 2: unknown key "NoSuchKey"`)
+
+	_, err = ReadTimeline(strings.NewReader("x quit"))
+	assert.Error(err)
+
+	_, err = ReadTimeline(strings.NewReader("2 mousemove 1"))
+	assert.Error(err)
+}
+
+func TestScriptedInputBackend(t *testing.T) {
+	assert := testify.New(t)
+
+	events, err := ReadTimeline(strings.NewReader(testTimeline))
+	assert.NoError(err)
+
+	input, err := Create(events)
+	assert.NoError(err)
+
+	assert.NoError(input.Process()) // tick 0
+	x, y := input.CursorPosition()
+	assert.Equal([]int{400, 300}, []int{x, y})
+
+	assert.NoError(input.Process()) // tick 1
+	assert.True(input.IsMouseButtonPressed(inputbackend.MouseButtonLeft))
+	assert.True(input.IsMouseButtonJustPressed(inputbackend.MouseButtonLeft))
+
+	assert.NoError(input.Process()) // tick 2
+	assert.True(input.IsMouseButtonPressed(inputbackend.MouseButtonLeft))
+	assert.False(input.IsMouseButtonJustPressed(inputbackend.MouseButtonLeft))
+
+	assert.NoError(input.Process()) // tick 3
+	assert.True(input.IsMouseButtonJustReleased(inputbackend.MouseButtonLeft))
+
+	assert.NoError(input.Process()) // tick 4
+	assert.False(input.IsKeyPressed(inputbackend.KeyI))
+	assert.True(input.IsKeyJustPressed(inputbackend.KeyI))
+	assert.True(input.IsKeyJustReleased(inputbackend.KeyI))
+
+	assert.NoError(input.Process()) // tick 5
+	assert.Equal(1, input.KeyPressDuration(inputbackend.KeyShift))
+	assert.Equal(inputbackend.KeyModShift, input.KeyMods())
+
+	input.Inject(KeyDown(5, inputbackend.KeyEscape))
+
+	assert.NoError(input.Process()) // tick 6
+	assert.Equal(2, input.KeyPressDuration(inputbackend.KeyShift))
+	assert.Equal([]rune("hi there"), input.InputChars())
+	assert.True(input.IsKeyJustPressed(inputbackend.KeyEscape), "a late event applies on the next tick")
+
+	assert.NoError(input.Process()) // tick 7
+	assert.Empty(input.InputChars())
+
+	assert.NoError(input.Process()) // tick 8
+	assert.Zero(input.KeyMods())
+	assert.False(input.Done())
+	assert.Equal(inputbackend.ErrQuitRequested, input.Process())
+	assert.True(input.Done())
+	assert.Equal(uint64(10), input.Tick())
+}
+
+// snapshot describes the whole input state after a tick
+func snapshot(input inputbackend.Interface) string {
+	var builder strings.Builder
+
+	x, y := input.CursorPosition()
+	fmt.Fprintf(&builder, "cursor %d %d chars %q", x, y, string(input.InputChars()))
+
+	for key := inputbackend.KeyMin; key <= inputbackend.KeyMax; key++ {
+		if input.IsKeyPressed(key) || input.IsKeyJustPressed(key) || input.IsKeyJustReleased(key) {
+			fmt.Fprintf(&builder, " %s:%v/%v/%v/%d", key, input.IsKeyPressed(key),
+				input.IsKeyJustPressed(key), input.IsKeyJustReleased(key), input.KeyPressDuration(key))
+		}
+	}
+
+	for button := inputbackend.MouseButtonMin; button <= inputbackend.MouseButtonMax; button++ {
+		fmt.Fprintf(&builder, " %s:%v/%v/%v", button, input.IsMouseButtonPressed(button),
+			input.IsMouseButtonJustPressed(button), input.IsMouseButtonJustReleased(button))
+	}
+
+	return builder.String()
+}
+
+// play processes the input until it quits and returns the snapshots
+func play(t *testing.T, input inputbackend.Interface) []string {
+	var result []string
+
+	for tick := 0; tick < 100; tick++ {
+		err := input.Process()
+		result = append(result, snapshot(input))
+
+		if err == inputbackend.ErrQuitRequested {
+			return result
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Fatal("the input never quit")
+
+	return nil
+}
+
+func TestRecorder_ReplayReproducesSession(t *testing.T) {
+	assert := testify.New(t)
+
+	events, err := ReadTimeline(strings.NewReader(testTimeline))
+	assert.NoError(err)
+
+	// the timeline stands in for a live session
+	session, err := Create(events)
+	assert.NoError(err)
+
+	var recording bytes.Buffer
+
+	recorder := NewRecorder(session, &recording)
+	recorded := play(t, recorder)
+	assert.NoError(recorder.Flush())
+
+	replayEvents, err := ReadTimeline(&recording)
+	assert.NoError(err)
+
+	replay, err := Create(replayEvents)
+	assert.NoError(err)
+
+	assert.Equal(recorded, play(t, replay))
+	assert.Len(recorded, 10)
+}
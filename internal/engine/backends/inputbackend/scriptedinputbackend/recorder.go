@@ -0,0 +1,111 @@
+package scriptedinputbackend
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+)
+
+var _ inputbackend.Interface = &Recorder{}
+
+// Recorder wraps an input backend and writes what changes at each tick as a
+// timeline, which ScriptedInputBackend plays back. Ticks without changes
+// take no space.
+type Recorder struct {
+	inputbackend.Interface
+	writer  *bufio.Writer
+	tick    uint64
+	keys    map[inputbackend.Key]bool
+	buttons map[inputbackend.MouseButton]bool
+	x, y    int
+}
+
+// NewRecorder records the input of a backend to w, Flush must be called once
+// done
+func NewRecorder(backend inputbackend.Interface, w io.Writer) *Recorder {
+	result := &Recorder{
+		Interface: backend,
+		writer:    bufio.NewWriter(w),
+		keys:      make(map[inputbackend.Key]bool),
+		buttons:   make(map[inputbackend.MouseButton]bool),
+	}
+
+	return result
+}
+
+// Process processes the events of the wrapped backend and records the changes
+func (r *Recorder) Process() error {
+	processErr := r.Interface.Process()
+	if processErr != nil && !errors.Is(processErr, inputbackend.ErrQuitRequested) {
+		return processErr
+	}
+
+	events := r.changes()
+
+	if processErr != nil {
+		events = append(events, Quit(r.tick))
+	}
+
+	r.tick++
+
+	if err := WriteTimeline(r.writer, events); err != nil {
+		return fmt.Errorf("recording input: %w", err)
+	}
+
+	return processErr
+}
+
+// Flush writes the buffered events
+func (r *Recorder) Flush() error {
+	return r.writer.Flush()
+}
+
+func (r *Recorder) changes() []Event {
+	var events []Event
+
+	if x, y := r.CursorPosition(); x != r.x || y != r.y {
+		r.x, r.y = x, y
+		events = append(events, MouseMove(r.tick, x, y))
+	}
+
+	for key := inputbackend.KeyMin; key <= inputbackend.KeyMax; key++ {
+		down, up := KeyDown(r.tick, key), KeyUp(r.tick, key)
+		events = append(events, transitions(r.keys[key], r.IsKeyPressed(key),
+			r.IsKeyJustPressed(key) && r.IsKeyJustReleased(key), down, up)...)
+		r.keys[key] = r.IsKeyPressed(key)
+	}
+
+	for button := inputbackend.MouseButtonMin; button <= inputbackend.MouseButtonMax; button++ {
+		down, up := MouseDown(r.tick, button), MouseUp(r.tick, button)
+		events = append(events, transitions(r.buttons[button], r.IsMouseButtonPressed(button),
+			r.IsMouseButtonJustPressed(button) && r.IsMouseButtonJustReleased(button), down, up)...)
+		r.buttons[button] = r.IsMouseButtonPressed(button)
+	}
+
+	if chars := r.InputChars(); len(chars) > 0 {
+		events = append(events, Text(r.tick, string(chars)))
+	}
+
+	return events
+}
+
+// transitions returns the events turning a key or button from its previous
+// state to its current one, toggled means it was both pressed and released
+// during the tick
+func transitions(previous, current, toggled bool, down, up Event) []Event {
+	switch {
+	case !previous && current:
+		return []Event{down}
+	case previous && !current:
+		return []Event{up}
+	case toggled && current:
+		return []Event{up, down}
+	case toggled:
+		return []Event{down, up}
+	default:
+		return nil
+	}
+}
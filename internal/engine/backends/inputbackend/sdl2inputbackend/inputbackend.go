@@ -1,112 +1,212 @@
 package sdl2inputbackend
 
 import (
-	"os"
-
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
-const (
-	mousePressThreshold = 25
-)
-
-type mouseEventInfo struct {
-	state bool
-	time  uint32
-}
-
 var _ inputbackend.Interface = &SDL2InputBackend{}
 
+// SDL2InputBackend reads the SDL event queue. The engine processes input once
+// per simulation tick, so the transitions and durations are counted in ticks.
 type SDL2InputBackend struct {
-	mouseState [8]mouseEventInfo
-	cursorPosX int
-	cursorPosY int
+	tick uint64
+
+	keys                map[inputbackend.Key]uint64
+	keysJustPressed     map[inputbackend.Key]bool
+	keysJustReleased    map[inputbackend.Key]bool
+	keyMods             inputbackend.KeyMod
+	buttons             map[inputbackend.MouseButton]bool
+	buttonsJustPressed  map[inputbackend.MouseButton]bool
+	buttonsJustReleased map[inputbackend.MouseButton]bool
+	cursorPosX          int
+	cursorPosY          int
+	chars               []rune
 }
 
 func Create() (*SDL2InputBackend, error) {
-	result := &SDL2InputBackend{}
+	result := &SDL2InputBackend{
+		keys:    make(map[inputbackend.Key]uint64),
+		buttons: make(map[inputbackend.MouseButton]bool),
+	}
+
+	result.clearTransitions()
+
+	// the text input events carry the characters typed with the current
+	// layout and input method, which the key events cannot tell
+	sdl.StartTextInput()
 
 	return result, nil
 }
 
-func (i SDL2InputBackend) Process() error {
-	var event sdl.Event
-	for event = sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+func (i *SDL2InputBackend) clearTransitions() {
+	i.keysJustPressed = make(map[inputbackend.Key]bool)
+	i.keysJustReleased = make(map[inputbackend.Key]bool)
+	i.buttonsJustPressed = make(map[inputbackend.MouseButton]bool)
+	i.buttonsJustReleased = make(map[inputbackend.MouseButton]bool)
+	i.chars = []rune{}
+}
+
+// Process drains the event queue, it returns inputbackend.ErrQuitRequested
+// once the queue held a quit event
+func (i *SDL2InputBackend) Process() error {
+	i.clearTransitions()
+
+	quit := false
+
+	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
 		switch t := event.(type) {
 		case *sdl.QuitEvent:
-			os.Exit(0)
+			quit = true
+		case *sdl.KeyboardEvent:
+			i.processKeyboardEvent(t)
+		case *sdl.TextInputEvent:
+			i.chars = append(i.chars, []rune(t.GetText())...)
 		case *sdl.MouseMotionEvent:
 			i.cursorPosX = int(t.X)
 			i.cursorPosY = int(t.Y)
 		case *sdl.MouseButtonEvent:
-			i.mouseState[t.Button].time = sdl.GetTicks()
-			i.mouseState[t.Button].state = t.Type == sdl.MOUSEBUTTONDOWN
+			i.processMouseButtonEvent(t)
+		case *sdl.MouseWheelEvent:
+			i.processMouseWheelEvent(t)
 		}
 	}
 
+	i.tick++
+
+	if quit {
+		return inputbackend.ErrQuitRequested
+	}
+
 	return nil
 }
 
+func (i *SDL2InputBackend) processKeyboardEvent(event *sdl.KeyboardEvent) {
+	i.keyMods = toKeyMod(event.Keysym.Mod)
+
+	// the repeats of a held key are not new presses
+	if event.Repeat != 0 {
+		return
+	}
+
+	key, found := toKey(event.Keysym)
+	if !found {
+		return
+	}
+
+	i.setKey(key, event.Type == sdl.KEYDOWN)
+}
+
+func (i *SDL2InputBackend) processMouseButtonEvent(event *sdl.MouseButtonEvent) {
+	pressed := event.Type == sdl.MOUSEBUTTONDOWN
+
+	switch event.Button {
+	case sdl.BUTTON_LEFT:
+		i.setMouseButton(inputbackend.MouseButtonLeft, pressed)
+	case sdl.BUTTON_MIDDLE:
+		i.setMouseButton(inputbackend.MouseButtonMiddle, pressed)
+		i.setKey(inputbackend.KeyMouse3, pressed)
+	case sdl.BUTTON_RIGHT:
+		i.setMouseButton(inputbackend.MouseButtonRight, pressed)
+	case sdl.BUTTON_X1:
+		i.setKey(inputbackend.KeyMouse4, pressed)
+	case sdl.BUTTON_X2:
+		i.setKey(inputbackend.KeyMouse5, pressed)
+	}
+}
+
+// processMouseWheelEvent taps the wheel keys, a wheel is never held down
+func (i *SDL2InputBackend) processMouseWheelEvent(event *sdl.MouseWheelEvent) {
+	y := event.Y
+	if event.Direction == sdl.MOUSEWHEEL_FLIPPED {
+		y = -y
+	}
+
+	key := inputbackend.KeyMouseWheelUp
+
+	switch {
+	case y < 0:
+		key = inputbackend.KeyMouseWheelDown
+	case y == 0:
+		return
+	}
+
+	i.setKey(key, true)
+	i.setKey(key, false)
+}
+
+func (i *SDL2InputBackend) setKey(key inputbackend.Key, pressed bool) {
+	if !pressed {
+		delete(i.keys, key)
+		i.keysJustReleased[key] = true
+
+		return
+	}
+
+	if _, found := i.keys[key]; !found {
+		i.keys[key] = i.tick
+	}
+
+	i.keysJustPressed[key] = true
+}
+
+func (i *SDL2InputBackend) setMouseButton(button inputbackend.MouseButton, pressed bool) {
+	if !pressed {
+		delete(i.buttons, button)
+		i.buttonsJustReleased[button] = true
+
+		return
+	}
+
+	i.buttons[button] = true
+	i.buttonsJustPressed[button] = true
+}
+
 func (i *SDL2InputBackend) CursorPosition() (x int, y int) {
 	return i.cursorPosX, i.cursorPosY
 }
 
 func (i *SDL2InputBackend) InputChars() []rune {
-	return []rune{}
+	return i.chars
 }
 
 func (i *SDL2InputBackend) IsKeyPressed(key inputbackend.Key) bool {
-	return false
+	_, found := i.keys[key]
+	return found
 }
 
 func (i *SDL2InputBackend) IsKeyJustPressed(key inputbackend.Key) bool {
-	return false
+	return i.keysJustPressed[key]
 }
 
 func (i *SDL2InputBackend) IsKeyJustReleased(key inputbackend.Key) bool {
-	return false
+	return i.keysJustReleased[key]
+}
+
+// KeyMods returns the modifiers SDL reported with the latest key event
+func (i *SDL2InputBackend) KeyMods() inputbackend.KeyMod {
+	return i.keyMods
 }
 
 func (i *SDL2InputBackend) IsMouseButtonPressed(button inputbackend.MouseButton) bool {
-	switch button {
-	case inputbackend.MouseButtonLeft:
-		return i.mouseState[1].state
-	case inputbackend.MouseButtonRight:
-		return i.mouseState[2].state
-	case inputbackend.MouseButtonMiddle:
-		return i.mouseState[3].state
-	default:
-		return false
-	}
+	return i.buttons[button]
 }
 
 func (i *SDL2InputBackend) IsMouseButtonJustPressed(button inputbackend.MouseButton) bool {
-	switch button {
-	case inputbackend.MouseButtonLeft:
-		return i.mouseState[1].state == true && (sdl.GetTicks()-i.mouseState[1].time) < mousePressThreshold
-	case inputbackend.MouseButtonRight:
-		return i.mouseState[2].state == true && (sdl.GetTicks()-i.mouseState[2].time) < mousePressThreshold
-	case inputbackend.MouseButtonMiddle:
-		return i.mouseState[3].state == true && (sdl.GetTicks()-i.mouseState[3].time) < mousePressThreshold
-	default:
-		return false
-	}
+	return i.buttonsJustPressed[button]
 }
 
 func (i *SDL2InputBackend) IsMouseButtonJustReleased(button inputbackend.MouseButton) bool {
-	switch button {
-	case inputbackend.MouseButtonLeft:
-		return i.mouseState[1].state == false && (sdl.GetTicks()-i.mouseState[1].time) < mousePressThreshold
-	case inputbackend.MouseButtonRight:
-		return i.mouseState[2].state == false && (sdl.GetTicks()-i.mouseState[2].time) < mousePressThreshold
-	case inputbackend.MouseButtonMiddle:
-		return i.mouseState[3].state == false && (sdl.GetTicks()-i.mouseState[3].time) < mousePressThreshold
-	default:
-		return false
-	}
+	return i.buttonsJustReleased[button]
 }
 
+// KeyPressDuration returns for how many ticks the key has been down, counting
+// the current one
 func (i *SDL2InputBackend) KeyPressDuration(key inputbackend.Key) int {
-	return 0
+	pressed, found := i.keys[key]
+	if !found {
+		return 0
+	}
+
+	return int(i.tick - pressed)
 }
@@ -0,0 +1,156 @@
+package sdl2inputbackend
+
+import (
+	"os"
+	"testing"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+	testify "github.com/stretchr/testify/assert"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestMain(m *testing.M) {
+	// the dummy driver has an event queue but needs no display
+	if err := os.Setenv("SDL_VIDEODRIVER", "dummy"); err != nil {
+		panic(err)
+	}
+
+	if err := sdl.Init(sdl.INIT_VIDEO | sdl.INIT_EVENTS); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	sdl.Quit()
+	os.Exit(code)
+}
+
+func push(t *testing.T, events ...sdl.Event) {
+	for _, event := range events {
+		if _, err := sdl.PushEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func keyEvent(eventType uint32, scancode sdl.Scancode, keycode sdl.Keycode, mod uint16) *sdl.KeyboardEvent {
+	state := uint8(sdl.RELEASED)
+	if eventType == sdl.KEYDOWN {
+		state = sdl.PRESSED
+	}
+
+	return &sdl.KeyboardEvent{
+		Type:   eventType,
+		State:  state,
+		Keysym: sdl.Keysym{Scancode: scancode, Sym: keycode, Mod: mod},
+	}
+}
+
+func textEvent(text string) *sdl.TextInputEvent {
+	result := &sdl.TextInputEvent{Type: sdl.TEXTINPUT}
+	copy(result.Text[:], text)
+
+	return result
+}
+
+func TestSDL2InputBackend_Keys(t *testing.T) {
+	assert := testify.New(t)
+
+	input, err := Create()
+	assert.NoError(err)
+
+	push(t,
+		keyEvent(sdl.KEYDOWN, sdl.SCANCODE_LSHIFT, sdl.K_LSHIFT, sdl.KMOD_LSHIFT),
+		keyEvent(sdl.KEYDOWN, sdl.SCANCODE_A, sdl.K_a, sdl.KMOD_LSHIFT),
+		textEvent("A"),
+	)
+	assert.NoError(input.Process())
+	assert.True(input.IsKeyPressed(inputbackend.KeyA))
+	assert.True(input.IsKeyJustPressed(inputbackend.KeyA))
+	assert.True(input.IsKeyPressed(inputbackend.KeyShift))
+	assert.Equal(inputbackend.KeyModShift, input.KeyMods())
+	assert.Equal([]rune("A"), input.InputChars())
+	assert.Equal(1, input.KeyPressDuration(inputbackend.KeyA))
+
+	repeat := keyEvent(sdl.KEYDOWN, sdl.SCANCODE_A, sdl.K_a, sdl.KMOD_LSHIFT)
+	repeat.Repeat = 1
+	push(t, repeat)
+	assert.NoError(input.Process())
+	assert.True(input.IsKeyPressed(inputbackend.KeyA))
+	assert.False(input.IsKeyJustPressed(inputbackend.KeyA), "a repeat is not a new press")
+	assert.Empty(input.InputChars())
+	assert.Equal(2, input.KeyPressDuration(inputbackend.KeyA))
+
+	push(t,
+		keyEvent(sdl.KEYUP, sdl.SCANCODE_A, sdl.K_a, sdl.KMOD_LSHIFT),
+		keyEvent(sdl.KEYUP, sdl.SCANCODE_LSHIFT, sdl.K_LSHIFT, sdl.KMOD_NONE),
+	)
+	assert.NoError(input.Process())
+	assert.False(input.IsKeyPressed(inputbackend.KeyA))
+	assert.True(input.IsKeyJustReleased(inputbackend.KeyA))
+	assert.Zero(input.KeyMods())
+	assert.Zero(input.KeyPressDuration(inputbackend.KeyA))
+
+	assert.NoError(input.Process())
+	assert.False(input.IsKeyJustReleased(inputbackend.KeyA))
+}
+
+func TestSDL2InputBackend_Layout(t *testing.T) {
+	assert := testify.New(t)
+
+	input, err := Create()
+	assert.NoError(err)
+
+	// the key where QWERTY has Q types an A on an AZERTY layout
+	push(t,
+		keyEvent(sdl.KEYDOWN, sdl.SCANCODE_Q, sdl.K_a, sdl.KMOD_NONE),
+		keyEvent(sdl.KEYDOWN, sdl.SCANCODE_F5, sdl.K_F5, sdl.KMOD_NONE),
+		keyEvent(sdl.KEYDOWN, sdl.SCANCODE_0, sdl.K_0, sdl.KMOD_NONE),
+	)
+	assert.NoError(input.Process())
+	assert.True(input.IsKeyPressed(inputbackend.KeyA))
+	assert.False(input.IsKeyPressed(inputbackend.KeyQ))
+	assert.True(input.IsKeyPressed(inputbackend.KeyF5))
+	assert.True(input.IsKeyPressed(inputbackend.Key0))
+}
+
+func TestSDL2InputBackend_Mouse(t *testing.T) {
+	assert := testify.New(t)
+
+	input, err := Create()
+	assert.NoError(err)
+
+	push(t,
+		&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: 120, Y: 80},
+		&sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONDOWN, Button: sdl.BUTTON_RIGHT, State: sdl.PRESSED},
+		&sdl.MouseWheelEvent{Type: sdl.MOUSEWHEEL, Y: -1},
+	)
+	assert.NoError(input.Process())
+
+	x, y := input.CursorPosition()
+	assert.Equal([]int{120, 80}, []int{x, y})
+	assert.True(input.IsMouseButtonPressed(inputbackend.MouseButtonRight))
+	assert.True(input.IsMouseButtonJustPressed(inputbackend.MouseButtonRight))
+	assert.False(input.IsMouseButtonPressed(inputbackend.MouseButtonMiddle))
+	assert.True(input.IsKeyJustPressed(inputbackend.KeyMouseWheelDown))
+	assert.False(input.IsKeyPressed(inputbackend.KeyMouseWheelDown))
+
+	push(t, &sdl.MouseButtonEvent{Type: sdl.MOUSEBUTTONUP, Button: sdl.BUTTON_RIGHT, State: sdl.RELEASED})
+	assert.NoError(input.Process())
+	assert.False(input.IsMouseButtonPressed(inputbackend.MouseButtonRight))
+	assert.True(input.IsMouseButtonJustReleased(inputbackend.MouseButtonRight))
+}
+
+func TestSDL2InputBackend_Quit(t *testing.T) {
+	assert := testify.New(t)
+
+	input, err := Create()
+	assert.NoError(err)
+
+	push(t,
+		&sdl.QuitEvent{Type: sdl.QUIT},
+		keyEvent(sdl.KEYDOWN, sdl.SCANCODE_ESCAPE, sdl.K_ESCAPE, sdl.KMOD_NONE),
+	)
+	assert.Equal(inputbackend.ErrQuitRequested, input.Process())
+	assert.True(input.IsKeyJustPressed(inputbackend.KeyEscape), "the events after a quit are still read")
+}
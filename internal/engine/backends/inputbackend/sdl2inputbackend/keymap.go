@@ -0,0 +1,150 @@
+package sdl2inputbackend
+
+import (
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	numLetters = 26
+	numDigits  = 10
+)
+
+// keycodes maps the keys that print a character by the character they print
+// in the current layout, so that KeyA is the key labelled A on any keyboard
+var keycodes = map[sdl.Keycode]inputbackend.Key{
+	sdl.K_QUOTE:        inputbackend.KeyApostrophe,
+	sdl.K_BACKSLASH:    inputbackend.KeyBackslash,
+	sdl.K_COMMA:        inputbackend.KeyComma,
+	sdl.K_EQUALS:       inputbackend.KeyEqual,
+	sdl.K_BACKQUOTE:    inputbackend.KeyGraveAccent,
+	sdl.K_LEFTBRACKET:  inputbackend.KeyLeftBracket,
+	sdl.K_MINUS:        inputbackend.KeyMinus,
+	sdl.K_PERIOD:       inputbackend.KeyPeriod,
+	sdl.K_RIGHTBRACKET: inputbackend.KeyRightBracket,
+	sdl.K_SEMICOLON:    inputbackend.KeySemicolon,
+	sdl.K_SLASH:        inputbackend.KeySlash,
+	sdl.K_SPACE:        inputbackend.KeySpace,
+}
+
+// scancodes maps the keys by their position, for the keys that print
+// nothing and as a fallback for layouts whose characters have no Key
+var scancodes = map[sdl.Scancode]inputbackend.Key{
+	sdl.SCANCODE_APOSTROPHE:   inputbackend.KeyApostrophe,
+	sdl.SCANCODE_BACKSLASH:    inputbackend.KeyBackslash,
+	sdl.SCANCODE_BACKSPACE:    inputbackend.KeyBackspace,
+	sdl.SCANCODE_CAPSLOCK:     inputbackend.KeyCapsLock,
+	sdl.SCANCODE_COMMA:        inputbackend.KeyComma,
+	sdl.SCANCODE_DELETE:       inputbackend.KeyDelete,
+	sdl.SCANCODE_DOWN:         inputbackend.KeyDown,
+	sdl.SCANCODE_END:          inputbackend.KeyEnd,
+	sdl.SCANCODE_RETURN:       inputbackend.KeyEnter,
+	sdl.SCANCODE_EQUALS:       inputbackend.KeyEqual,
+	sdl.SCANCODE_ESCAPE:       inputbackend.KeyEscape,
+	sdl.SCANCODE_F1:           inputbackend.KeyF1,
+	sdl.SCANCODE_F2:           inputbackend.KeyF2,
+	sdl.SCANCODE_F3:           inputbackend.KeyF3,
+	sdl.SCANCODE_F4:           inputbackend.KeyF4,
+	sdl.SCANCODE_F5:           inputbackend.KeyF5,
+	sdl.SCANCODE_F6:           inputbackend.KeyF6,
+	sdl.SCANCODE_F7:           inputbackend.KeyF7,
+	sdl.SCANCODE_F8:           inputbackend.KeyF8,
+	sdl.SCANCODE_F9:           inputbackend.KeyF9,
+	sdl.SCANCODE_F10:          inputbackend.KeyF10,
+	sdl.SCANCODE_F11:          inputbackend.KeyF11,
+	sdl.SCANCODE_F12:          inputbackend.KeyF12,
+	sdl.SCANCODE_GRAVE:        inputbackend.KeyGraveAccent,
+	sdl.SCANCODE_HOME:         inputbackend.KeyHome,
+	sdl.SCANCODE_INSERT:       inputbackend.KeyInsert,
+	sdl.SCANCODE_KP_0:         inputbackend.KeyKP0,
+	sdl.SCANCODE_KP_1:         inputbackend.KeyKP1,
+	sdl.SCANCODE_KP_2:         inputbackend.KeyKP2,
+	sdl.SCANCODE_KP_3:         inputbackend.KeyKP3,
+	sdl.SCANCODE_KP_4:         inputbackend.KeyKP4,
+	sdl.SCANCODE_KP_5:         inputbackend.KeyKP5,
+	sdl.SCANCODE_KP_6:         inputbackend.KeyKP6,
+	sdl.SCANCODE_KP_7:         inputbackend.KeyKP7,
+	sdl.SCANCODE_KP_8:         inputbackend.KeyKP8,
+	sdl.SCANCODE_KP_9:         inputbackend.KeyKP9,
+	sdl.SCANCODE_KP_PLUS:      inputbackend.KeyKPAdd,
+	sdl.SCANCODE_KP_PERIOD:    inputbackend.KeyKPDecimal,
+	sdl.SCANCODE_KP_DIVIDE:    inputbackend.KeyKPDivide,
+	sdl.SCANCODE_KP_ENTER:     inputbackend.KeyKPEnter,
+	sdl.SCANCODE_KP_EQUALS:    inputbackend.KeyKPEqual,
+	sdl.SCANCODE_KP_MULTIPLY:  inputbackend.KeyKPMultiply,
+	sdl.SCANCODE_KP_MINUS:     inputbackend.KeyKPSubtract,
+	sdl.SCANCODE_LEFT:         inputbackend.KeyLeft,
+	sdl.SCANCODE_LEFTBRACKET:  inputbackend.KeyLeftBracket,
+	sdl.SCANCODE_APPLICATION:  inputbackend.KeyMenu,
+	sdl.SCANCODE_MINUS:        inputbackend.KeyMinus,
+	sdl.SCANCODE_NUMLOCKCLEAR: inputbackend.KeyNumLock,
+	sdl.SCANCODE_PAGEDOWN:     inputbackend.KeyPageDown,
+	sdl.SCANCODE_PAGEUP:       inputbackend.KeyPageUp,
+	sdl.SCANCODE_PAUSE:        inputbackend.KeyPause,
+	sdl.SCANCODE_PERIOD:       inputbackend.KeyPeriod,
+	sdl.SCANCODE_PRINTSCREEN:  inputbackend.KeyPrintScreen,
+	sdl.SCANCODE_RIGHT:        inputbackend.KeyRight,
+	sdl.SCANCODE_RIGHTBRACKET: inputbackend.KeyRightBracket,
+	sdl.SCANCODE_SCROLLLOCK:   inputbackend.KeyScrollLock,
+	sdl.SCANCODE_SEMICOLON:    inputbackend.KeySemicolon,
+	sdl.SCANCODE_SLASH:        inputbackend.KeySlash,
+	sdl.SCANCODE_SPACE:        inputbackend.KeySpace,
+	sdl.SCANCODE_TAB:          inputbackend.KeyTab,
+	sdl.SCANCODE_UP:           inputbackend.KeyUp,
+	sdl.SCANCODE_LALT:         inputbackend.KeyAlt,
+	sdl.SCANCODE_RALT:         inputbackend.KeyAlt,
+	sdl.SCANCODE_LCTRL:        inputbackend.KeyControl,
+	sdl.SCANCODE_RCTRL:        inputbackend.KeyControl,
+	sdl.SCANCODE_LSHIFT:       inputbackend.KeyShift,
+	sdl.SCANCODE_RSHIFT:       inputbackend.KeyShift,
+}
+
+// letters and digits are contiguous in SDL and in Key, except that SDL puts
+// the 0 scancode after 9
+func init() {
+	for idx := 0; idx < numLetters; idx++ {
+		key := inputbackend.KeyA + inputbackend.Key(idx)
+		keycodes[sdl.Keycode(sdl.K_a+idx)] = key
+		scancodes[sdl.Scancode(sdl.SCANCODE_A+idx)] = key
+	}
+
+	for idx := 0; idx < numDigits; idx++ {
+		keycodes[sdl.Keycode(sdl.K_0+idx)] = inputbackend.Key0 + inputbackend.Key(idx)
+	}
+
+	for idx := 1; idx < numDigits; idx++ {
+		scancodes[sdl.Scancode(sdl.SCANCODE_1+idx-1)] = inputbackend.Key0 + inputbackend.Key(idx)
+	}
+
+	scancodes[sdl.SCANCODE_0] = inputbackend.Key0
+}
+
+// toKey returns the key of an SDL key, by keycode first then by scancode
+func toKey(keysym sdl.Keysym) (inputbackend.Key, bool) {
+	if key, found := keycodes[keysym.Sym]; found {
+		return key, true
+	}
+
+	key, found := scancodes[keysym.Scancode]
+
+	return key, found
+}
+
+// toKeyMod returns the modifiers of an SDL modifier mask
+func toKeyMod(mod uint16) inputbackend.KeyMod {
+	var result inputbackend.KeyMod
+
+	if mod&uint16(sdl.KMOD_ALT) != 0 {
+		result |= inputbackend.KeyModAlt
+	}
+
+	if mod&uint16(sdl.KMOD_CTRL) != 0 {
+		result |= inputbackend.KeyModControl
+	}
+
+	if mod&uint16(sdl.KMOD_SHIFT) != 0 {
+		result |= inputbackend.KeyModShift
+	}
+
+	return result
+}
@@ -19,6 +19,11 @@ type Configuration struct {
 	RunInBackground bool
 	VsyncEnabled    bool
 	Backend         string
+	RemoteConsole   string
+	HeadlessFrames  string
+	InputScript     string
+	InputRecording  string
+	KeyBindings     map[string][]string
 	filePath        string
 }
 
@@ -0,0 +1,101 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldNames returns the names of the exported fields, in declaration order
+func (c *Configuration) FieldNames() []string {
+	t := reflect.TypeOf(c).Elem()
+	result := make([]string, 0, t.NumField())
+
+	for idx := 0; idx < t.NumField(); idx++ {
+		if t.Field(idx).PkgPath == "" {
+			result = append(result, t.Field(idx).Name)
+		}
+	}
+
+	return result
+}
+
+// Field returns the value of a field as text, the name is case insensitive.
+// Lists are comma separated.
+func (c *Configuration) Field(name string) (string, error) {
+	field, err := c.field(name)
+	if err != nil {
+		return "", err
+	}
+
+	if field.Kind() == reflect.Slice {
+		items := make([]string, field.Len())
+
+		for idx := range items {
+			items[idx] = fmt.Sprint(field.Index(idx).Interface())
+		}
+
+		return strings.Join(items, ","), nil
+	}
+
+	return fmt.Sprint(field.Interface()), nil
+}
+
+// SetField parses a value for a field, see Field
+func (c *Configuration) SetField(name, value string) error {
+	field, err := c.field(name)
+	if err != nil {
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(v))
+	case reflect.Float64:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(v)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(v)
+	case reflect.Slice:
+		items := make([]string, 0)
+
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("field %s of type %s can't be set", name, field.Type())
+	}
+
+	return nil
+}
+
+func (c *Configuration) field(name string) (reflect.Value, error) {
+	for _, fieldName := range c.FieldNames() {
+		if strings.EqualFold(fieldName, name) {
+			return reflect.ValueOf(c).Elem().FieldByName(fieldName), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("unknown configuration field %q", name)
+}
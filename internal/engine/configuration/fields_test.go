@@ -0,0 +1,41 @@
+package configuration
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestConfiguration_Fields(t *testing.T) {
+	assert := testify.New(t)
+	config := DefaultConfig()
+
+	names := config.FieldNames()
+	assert.Equal("MpqLoadOrder", names[0])
+	assert.NotContains(names, "filePath")
+
+	assert.NoError(config.SetField("fpscap", "60"))
+	assert.NoError(config.SetField("SfxVolume", "0.5"))
+	assert.NoError(config.SetField("FullScreen", "true"))
+	assert.NoError(config.SetField("MpqLoadOrder", "d2data.mpq, d2exp.mpq"))
+	assert.NoError(config.SetField("Backend", "headless"))
+
+	assert.Equal(60, config.FpsCap)
+	assert.Equal(0.5, config.SfxVolume)
+	assert.True(config.FullScreen)
+	assert.Equal([]string{"d2data.mpq", "d2exp.mpq"}, config.MpqLoadOrder)
+
+	value, err := config.Field("mpqloadorder")
+	assert.NoError(err)
+	assert.Equal("d2data.mpq,d2exp.mpq", value)
+
+	value, err = config.Field("Backend")
+	assert.NoError(err)
+	assert.Equal("headless", value)
+
+	assert.Error(config.SetField("FpsCap", "fast"))
+	assert.Error(config.SetField("filePath", "/tmp"))
+
+	_, err = config.Field("Unknown")
+	assert.Error(err)
+}
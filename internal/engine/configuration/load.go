@@ -41,6 +41,8 @@ func Load() (*Configuration, error) {
 			return nil, err
 		}
 
+		result.SetPath(configFileFullPath)
+
 		return result, nil
 	}
 
@@ -0,0 +1,57 @@
+package console
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	configDirName  = "OpenDiablo2"
+	socketFileName = "console.sock"
+)
+
+// DefaultRemoteAddress is where abyssctl connects by default, a unix socket
+// next to the configuration file. Only the user running the engine can use it.
+func DefaultRemoteAddress() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return "unix:" + filepath.Join(dir, configDirName, socketFileName)
+}
+
+// ParseAddress splits an address of the remote console, either
+// "unix:<socket path>" or "tcp:<loopback host>:<port>". TCP addresses must be
+// on the loopback interface, the console isn't meant to be reachable from
+// other machines. TCP has no authentication though, any local user can
+// connect, whereas unix sockets are only open to their owner.
+func ParseAddress(address string) (network, path string, err error) {
+	parts := strings.SplitN(address, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid console address %q, expected unix:<path> or tcp:<host>:<port>", address)
+	}
+
+	network, path = parts[0], parts[1]
+
+	switch network {
+	case "unix":
+		return network, path, nil
+	case "tcp":
+		host, _, err := net.SplitHostPort(path)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid console address %q: %w", address, err)
+		}
+
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return "", "", fmt.Errorf("console address %q is not a loopback address", address)
+		}
+
+		return network, path, nil
+	default:
+		return "", "", fmt.Errorf("unknown network %q in console address %q", network, address)
+	}
+}
@@ -0,0 +1,74 @@
+package console
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// Client runs command lines on a remote console
+type Client struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+}
+
+// Dial connects to a remote console, see ParseAddress. A positive timeout
+// bounds the connection and each command.
+func Dial(address string, timeout time.Duration) (*Client, error) {
+	network, path, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout(network, path, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Client{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: timeout,
+	}
+
+	return result, nil
+}
+
+// Run sends a command line and waits for its response. The error is about
+// the connection, a failed command is reported in the response.
+func (c *Client) Run(line string) (Response, error) {
+	if strings.ContainsAny(line, "\r\n") {
+		return Response{}, errors.New("a command line can't contain line breaks")
+	}
+
+	if c.timeout > 0 {
+		if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return Response{}, err
+		}
+	}
+
+	if _, err := c.conn.Write([]byte(line + "\n")); err != nil {
+		return Response{}, err
+	}
+
+	data, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		return Response{}, err
+	}
+
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return Response{}, err
+	}
+
+	return response, nil
+}
+
+// Close disconnects from the remote console
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
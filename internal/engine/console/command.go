@@ -0,0 +1,99 @@
+package console
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CommandFunc runs a command and returns its output
+type CommandFunc func(args Args) (string, error)
+
+// CompleteFunc returns the candidates for the last of the arguments typed so
+// far, the console keeps the ones starting with what was typed
+type CompleteFunc func(args Args) []string
+
+// Command is a named console command
+type Command struct {
+	// Name is what is typed to run the command, it can't contain spaces
+	Name string
+	// Usage describes the arguments, such as "<field> <value>"
+	Usage string
+	// Help is a one line description
+	Help string
+	// MinArgs and MaxArgs bound the number of arguments, a negative MaxArgs
+	// doesn't limit it
+	MinArgs int
+	MaxArgs int
+	// Complete is optional
+	Complete CompleteFunc
+	Run      CommandFunc
+}
+
+// String returns the name and usage of the command
+func (c *Command) String() string {
+	if c.Usage == "" {
+		return c.Name
+	}
+
+	return c.Name + " " + c.Usage
+}
+
+func (c *Command) checkArgs(args Args) error {
+	if len(args) < c.MinArgs || (c.MaxArgs >= 0 && len(args) > c.MaxArgs) {
+		return fmt.Errorf("usage: %s", c)
+	}
+
+	return nil
+}
+
+// Args are the arguments of a command, without its name
+type Args []string
+
+// String returns an argument, or an empty string past the last one
+func (a Args) String(idx int) string {
+	if idx >= len(a) {
+		return ""
+	}
+
+	return a[idx]
+}
+
+// Int parses an argument as an integer
+func (a Args) Int(idx int) (int, error) {
+	value, err := strconv.Atoi(a.String(idx))
+	if err != nil {
+		return 0, fmt.Errorf("argument %d: %q is not an integer", idx+1, a.String(idx))
+	}
+
+	return value, nil
+}
+
+// Float parses an argument as a number
+func (a Args) Float(idx int) (float64, error) {
+	value, err := strconv.ParseFloat(a.String(idx), 64)
+	if err != nil {
+		return 0, fmt.Errorf("argument %d: %q is not a number", idx+1, a.String(idx))
+	}
+
+	return value, nil
+}
+
+// Bool parses an argument as a boolean, such as true, false, 1 or 0
+func (a Args) Bool(idx int) (bool, error) {
+	value, err := strconv.ParseBool(a.String(idx))
+	if err != nil {
+		return false, fmt.Errorf("argument %d: %q is not a boolean", idx+1, a.String(idx))
+	}
+
+	return value, nil
+}
+
+// Rest joins the arguments from idx on with spaces
+func (a Args) Rest(idx int) string {
+	if idx >= len(a) {
+		return ""
+	}
+
+	return strings.Join(a[idx:], " ")
+}
@@ -0,0 +1,285 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownCommand is returned for a line naming no registered command
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrClosed is returned for lines submitted after Close
+	ErrClosed = errors.New("console closed")
+)
+
+// Result is the outcome of a command line
+type Result struct {
+	Output string
+	Err    error
+}
+
+type call struct {
+	line   string
+	result chan Result
+}
+
+// Console holds the registered commands and the lines waiting to run. Lines
+// are submitted from any goroutine and run by RunQueued on the main loop, so
+// commands may use the engine's state without locking.
+type Console struct {
+	mutex    sync.Mutex
+	commands map[string]*Command
+	queue    []call
+	closed   bool
+	history  *History
+}
+
+// New creates a console with the help and history commands
+func New() *Console {
+	result := &Console{
+		commands: make(map[string]*Command),
+		history:  NewHistory(DefaultHistorySize),
+	}
+
+	result.registerBuiltins()
+
+	return result
+}
+
+// Register adds a command, its name must be unique
+func (c *Console) Register(command Command) error {
+	if command.Name == "" || strings.ContainsAny(command.Name, " \t\"\\") {
+		return fmt.Errorf("invalid command name %q", command.Name)
+	}
+
+	if command.Run == nil {
+		return fmt.Errorf("command %s has no Run function", command.Name)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := strings.ToLower(command.Name)
+	if _, found := c.commands[key]; found {
+		return fmt.Errorf("command %s is already registered", command.Name)
+	}
+
+	c.commands[key] = &command
+
+	return nil
+}
+
+// Commands returns the registered commands sorted by name
+func (c *Console) Commands() []Command {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := make([]Command, 0, len(c.commands))
+	for _, command := range c.commands {
+		result = append(result, *command)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// History returns the history of the submitted lines
+func (c *Console) History() *History {
+	return c.history
+}
+
+// Submit queues a line to run on the next RunQueued, the result is sent on
+// the returned channel. History expansions such as !! are applied first.
+func (c *Console) Submit(line string) <-chan Result {
+	result := make(chan Result, 1)
+
+	expanded, err := c.history.Expand(line)
+	if err != nil {
+		result <- Result{Err: err}
+		return result
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		result <- Result{Err: ErrClosed}
+		return result
+	}
+
+	c.history.Add(expanded)
+	c.queue = append(c.queue, call{line: expanded, result: result})
+
+	return result
+}
+
+// RunQueued runs the submitted lines, it belongs to the main loop
+func (c *Console) RunQueued() {
+	c.mutex.Lock()
+	queue := c.queue
+	c.queue = nil
+	c.mutex.Unlock()
+
+	for _, queued := range queue {
+		queued.result <- c.Execute(queued.line)
+	}
+}
+
+// Close answers the lines still queued and the ones submitted later with
+// ErrClosed
+func (c *Console) Close() {
+	c.mutex.Lock()
+	queue := c.queue
+	c.queue = nil
+	c.closed = true
+	c.mutex.Unlock()
+
+	for _, queued := range queue {
+		queued.result <- Result{Err: ErrClosed}
+	}
+}
+
+// Execute runs a line at once, on the calling goroutine
+func (c *Console) Execute(line string) Result {
+	words, err := Split(line)
+	if err != nil {
+		return Result{Err: err}
+	}
+
+	if len(words) == 0 {
+		return Result{}
+	}
+
+	command := c.lookup(words[0])
+	if command == nil {
+		return Result{Err: fmt.Errorf("%w: %s", ErrUnknownCommand, words[0])}
+	}
+
+	args := Args(words[1:])
+	if err := command.checkArgs(args); err != nil {
+		return Result{Err: err}
+	}
+
+	output, err := command.Run(args)
+
+	return Result{Output: output, Err: err}
+}
+
+// Complete returns the lines the given one can be completed to, sorted
+func (c *Console) Complete(line string) []string {
+	words, err := Split(line)
+	if err != nil {
+		return nil
+	}
+
+	typingNewWord := line == "" || strings.HasSuffix(line, " ") || strings.HasSuffix(line, "\t")
+	if typingNewWord {
+		words = append(words, "")
+	}
+
+	var candidates []string
+
+	if len(words) == 1 {
+		for _, command := range c.Commands() {
+			candidates = append(candidates, command.Name)
+		}
+	} else if command := c.lookup(words[0]); command != nil && command.Complete != nil {
+		candidates = command.Complete(Args(words[1:]))
+	}
+
+	prefix := strings.ToLower(words[len(words)-1])
+	head := make([]string, len(words)-1)
+
+	for idx := range head {
+		head[idx] = quote(words[idx]) + " "
+	}
+
+	result := make([]string, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			result = append(result, strings.Join(head, "")+quote(candidate))
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func (c *Console) lookup(name string) *Command {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.commands[strings.ToLower(name)]
+}
+
+func (c *Console) registerBuiltins() {
+	builtins := []Command{
+		{
+			Name:     "help",
+			Usage:    "[command]",
+			Help:     "lists the commands or describes one",
+			MaxArgs:  1,
+			Complete: c.completeCommandName,
+			Run:      c.help,
+		},
+		{
+			Name: "history",
+			Help: "lists the lines run so far, !N runs line N again and !! the last one",
+			Run:  c.listHistory,
+		},
+	}
+
+	for _, command := range builtins {
+		if err := c.Register(command); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (c *Console) completeCommandName(Args) []string {
+	commands := c.Commands()
+	result := make([]string, len(commands))
+
+	for idx := range commands {
+		result[idx] = commands[idx].Name
+	}
+
+	return result
+}
+
+func (c *Console) help(args Args) (string, error) {
+	if len(args) == 1 {
+		command := c.lookup(args[0])
+		if command == nil {
+			return "", fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+		}
+
+		return fmt.Sprintf("%s\n  %s", command, command.Help), nil
+	}
+
+	var builder strings.Builder
+
+	for _, command := range c.Commands() {
+		fmt.Fprintf(&builder, "%-24s %s\n", command.String(), command.Help)
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n"), nil
+}
+
+func (c *Console) listHistory(Args) (string, error) {
+	var builder strings.Builder
+
+	for idx, line := range c.history.Lines() {
+		fmt.Fprintf(&builder, "%4d  %s\n", idx+1, line)
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n"), nil
+}
@@ -0,0 +1,139 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	assert := testify.New(t)
+
+	words, err := Split(`set  MpqPath "C:/Program Files/Diablo II" \"x\"`)
+	assert.NoError(err)
+	assert.Equal([]string{"set", "MpqPath", "C:/Program Files/Diablo II", `"x"`}, words)
+
+	words, err = Split(`echo ""`)
+	assert.NoError(err)
+	assert.Equal([]string{"echo", ""}, words)
+
+	_, err = Split(`echo "open`)
+	assert.Equal(ErrUnterminatedQuote, err)
+}
+
+func newTestConsole(t *testing.T) (*Console, *[]string) {
+	c := New()
+	ran := &[]string{}
+
+	err := c.Register(Command{
+		Name:    "spawn",
+		Usage:   "<monster> [count]",
+		MinArgs: 1,
+		MaxArgs: 2,
+		Complete: func(args Args) []string {
+			if len(args) == 1 {
+				return []string{"zombie", "zealot", "fallen"}
+			}
+
+			return nil
+		},
+		Run: func(args Args) (string, error) {
+			count := 1
+
+			if len(args) == 2 {
+				var err error
+				if count, err = args.Int(1); err != nil {
+					return "", err
+				}
+			}
+
+			*ran = append(*ran, args.String(0))
+
+			return fmt.Sprintf("%s x%d", args.String(0), count), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, ran
+}
+
+func TestConsole_Execute(t *testing.T) {
+	assert := testify.New(t)
+	c, ran := newTestConsole(t)
+
+	result := c.Execute("SPAWN zombie 3")
+	assert.NoError(result.Err)
+	assert.Equal("zombie x3", result.Output)
+	assert.Equal([]string{"zombie"}, *ran)
+
+	assert.EqualError(c.Execute("spawn").Err, "usage: spawn <monster> [count]")
+	assert.EqualError(c.Execute("spawn a b c").Err, "usage: spawn <monster> [count]")
+	assert.EqualError(c.Execute("spawn zombie many").Err, `argument 2: "many" is not an integer`)
+	assert.True(errors.Is(c.Execute("fly").Err, ErrUnknownCommand))
+
+	assert.Error(c.Register(Command{Name: "spawn", Run: func(Args) (string, error) { return "", nil }}))
+	assert.Error(c.Register(Command{Name: "two words", Run: func(Args) (string, error) { return "", nil }}))
+
+	result = c.Execute("help spawn")
+	assert.NoError(result.Err)
+	assert.Contains(result.Output, "spawn <monster> [count]")
+}
+
+func TestConsole_Queue(t *testing.T) {
+	assert := testify.New(t)
+	c, ran := newTestConsole(t)
+
+	first := c.Submit("spawn zombie")
+	second := c.Submit("spawn fallen 2")
+	assert.Empty(*ran, "lines wait for RunQueued")
+
+	c.RunQueued()
+	assert.Equal([]string{"zombie", "fallen"}, *ran)
+	assert.Equal("zombie x1", (<-first).Output)
+	assert.Equal("fallen x2", (<-second).Output)
+
+	pending := c.Submit("spawn zealot")
+	c.Close()
+	assert.Equal(ErrClosed, (<-pending).Err)
+	assert.Equal(ErrClosed, (<-c.Submit("spawn zealot")).Err)
+	assert.Len(*ran, 2)
+}
+
+func TestConsole_History(t *testing.T) {
+	assert := testify.New(t)
+	c, ran := newTestConsole(t)
+
+	c.Submit("spawn zombie")
+	c.Submit("spawn zombie")
+	c.Submit("spawn fallen")
+	c.Submit("!1")
+	c.Submit("!!")
+	c.RunQueued()
+
+	assert.Equal([]string{"zombie", "zombie", "fallen", "zombie", "zombie"}, *ran)
+	assert.Equal([]string{"spawn zombie", "spawn fallen", "spawn zombie"}, c.History().Lines())
+	assert.Error((<-c.Submit("!9")).Err)
+
+	history := NewHistory(2)
+	history.Add("a")
+	history.Add("b")
+	history.Add("c")
+	assert.Equal([]string{"b", "c"}, history.Lines())
+}
+
+func TestConsole_Complete(t *testing.T) {
+	assert := testify.New(t)
+	c, _ := newTestConsole(t)
+
+	assert.Equal([]string{"help", "history"}, c.Complete("h"))
+	assert.Equal([]string{"help", "history", "spawn"}, c.Complete(""))
+	assert.Equal([]string{"spawn zealot", "spawn zombie"}, c.Complete("spawn z"))
+	assert.Equal([]string{"spawn fallen", "spawn zealot", "spawn zombie"}, c.Complete("spawn "))
+	assert.Empty(c.Complete("spawn zombie "))
+	assert.Equal([]string{"help spawn"}, c.Complete("help sp"))
+	assert.Empty(c.Complete("fly "))
+}
@@ -0,0 +1,3 @@
+// Package console is the engine's debug console, a registry of named
+// commands that are queued from any goroutine and run on the main loop
+package console
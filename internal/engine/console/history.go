@@ -0,0 +1,76 @@
+package console
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultHistorySize is how many lines the console remembers
+const DefaultHistorySize = 100
+
+// History remembers the last lines run, it is safe for concurrent use
+type History struct {
+	mutex sync.Mutex
+	lines []string
+	size  int
+}
+
+// NewHistory creates a history that keeps the given number of lines
+func NewHistory(size int) *History {
+	return &History{size: size}
+}
+
+// Add remembers a line, a blank line or a repeat of the last one is skipped
+func (h *History) Add(line string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if strings.TrimSpace(line) == "" || (len(h.lines) > 0 && h.lines[len(h.lines)-1] == line) {
+		return
+	}
+
+	h.lines = append(h.lines, line)
+
+	if len(h.lines) > h.size {
+		h.lines = h.lines[len(h.lines)-h.size:]
+	}
+}
+
+// Lines returns the remembered lines from the oldest
+func (h *History) Lines() []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	result := make([]string, len(h.lines))
+	copy(result, h.lines)
+
+	return result
+}
+
+// Expand replaces a line of the form !! with the last line and !N with the
+// Nth line of Lines, counted from 1. Other lines are returned as they are.
+func (h *History) Expand(line string) (string, error) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "!") {
+		return line, nil
+	}
+
+	lines := h.Lines()
+
+	if trimmed == "!!" {
+		if len(lines) == 0 {
+			return "", fmt.Errorf("%s: history is empty", trimmed)
+		}
+
+		return lines[len(lines)-1], nil
+	}
+
+	number, err := strconv.Atoi(trimmed[1:])
+	if err != nil || number < 1 || number > len(lines) {
+		return "", fmt.Errorf("%s: no such history line", trimmed)
+	}
+
+	return lines[number-1], nil
+}
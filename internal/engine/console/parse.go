@@ -0,0 +1,79 @@
+package console
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrUnterminatedQuote is returned for a line with an odd number of quotes
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// Split splits a line into words at spaces. Double quotes group words and a
+// backslash escapes the next character.
+func Split(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quoted  bool
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+
+			escaped = false
+		case r == '\\':
+			escaped, inWord = true, true
+		case r == '"':
+			quoted, inWord = !quoted, true
+		case unicode.IsSpace(r) && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+
+			inWord = true
+		}
+	}
+
+	if quoted || escaped {
+		return nil, ErrUnterminatedQuote
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// quote quotes a word for a command line when it needs it
+func quote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\"\\") {
+		return word
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	return `"` + replacer.Replace(word) + `"`
+}
+
+// Join builds a line from words, quoting the ones that need it, so that
+// Split returns the same words
+func Join(words []string) string {
+	quoted := make([]string, len(words))
+
+	for idx, word := range words {
+		quoted[idx] = quote(word)
+	}
+
+	return strings.Join(quoted, " ")
+}
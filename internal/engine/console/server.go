@@ -0,0 +1,246 @@
+package console
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// socketMode only lets the user running the engine connect to a unix socket
+const socketMode = 0o600
+
+// Response is what the remote console answers to each line, encoded as one
+// line of JSON
+type Response struct {
+	OK     bool   `json:"ok"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newResponse(result Result) Response {
+	if result.Err != nil {
+		return Response{Output: result.Output, Error: result.Err.Error()}
+	}
+
+	return Response{OK: true, Output: result.Output}
+}
+
+// Server exposes a console over a local socket. Clients send one command line
+// per line and get a Response per line, in order.
+type Server struct {
+	console  *Console
+	listener net.Listener
+	// socketPath is the unix socket to remove on Close
+	socketPath string
+
+	mutex  sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Listen opens the socket of a remote console, see ParseAddress. A stale unix
+// socket file is removed first, and the new one is only open to its owner.
+func Listen(console *Console, address string) (*Server, error) {
+	network, path, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Server{
+		console: console,
+		conns:   make(map[net.Conn]struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if network == "unix" {
+		result.listener, err = listenUnix(path)
+		result.socketPath = path
+	} else {
+		result.listener, err = net.Listen(network, path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// listenUnix creates a unix socket with socketMode. It is created in a private
+// directory and then moved to its path, so nobody else can connect before its
+// mode is set.
+func listenUnix(path string) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir(filepath.Dir(path), ".console")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(dir)
+
+	created := filepath.Join(dir, socketFileName)
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: created, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	// the listener would remove the file it created, Close removes the moved one
+	listener.SetUnlinkOnClose(false)
+
+	if err = os.Chmod(created, socketMode); err == nil {
+		err = os.Rename(created, path)
+	}
+
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() net.Addr {
+	if s.socketPath != "" {
+		return &net.UnixAddr{Name: s.socketPath, Net: "unix"}
+	}
+
+	return s.listener.Addr()
+}
+
+// Serve accepts clients until Close, it returns nil once closed
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+
+			if closed {
+				return nil
+			}
+
+			return err
+		}
+
+		if !s.track(conn) {
+			_ = conn.Close()
+			return nil
+		}
+
+		go s.handle(conn)
+	}
+}
+
+// Close stops accepting clients, disconnects the connected ones and waits for
+// their handlers to return
+func (s *Server) Close() error {
+	s.mutex.Lock()
+
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+
+	s.closed = true
+	close(s.done)
+
+	err := s.listener.Close()
+
+	if s.socketPath != "" {
+		if removeErr := os.Remove(s.socketPath); removeErr != nil && err == nil {
+			err = removeErr
+		}
+	}
+
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+
+	return err
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+
+	return true
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+
+		_ = conn.Close()
+
+		s.wg.Done()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var response Response
+
+		// the line may never run when the main loop has stopped
+		select {
+		case result := <-s.console.Submit(scanner.Text()):
+			response = newResponse(result)
+		case <-s.done:
+			return
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+	}
+}
+
+// removeStaleSocket removes a socket file nobody listens on anymore
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return errors.New(path + " exists and isn't a socket")
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return errors.New(path + " is in use by another process")
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,173 @@
+package console
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// serve starts a server and a fake main loop running the queued lines
+func serve(t *testing.T, c *Console, address string) (server *Server, stop func()) {
+	server, err := Listen(c, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_ = server.Serve()
+	}()
+
+	ticker := time.NewTicker(time.Millisecond)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				c.RunQueued()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return server, func() {
+		ticker.Stop()
+		close(done)
+		testify.NoError(t, server.Close())
+	}
+}
+
+func TestServer_TCP(t *testing.T) {
+	assert := testify.New(t)
+	c, ran := newTestConsole(t)
+
+	server, stop := serve(t, c, "tcp:127.0.0.1:0")
+	defer stop()
+
+	client, err := Dial("tcp:"+server.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	response, err := client.Run(Join([]string{"spawn", "zombie", "2"}))
+	assert.NoError(err)
+	assert.Equal(Response{OK: true, Output: "zombie x2"}, response)
+
+	response, err = client.Run("fly")
+	assert.NoError(err)
+	assert.False(response.OK)
+	assert.Equal("unknown command: fly", response.Error)
+
+	_, err = client.Run("spawn\nzombie")
+	assert.Error(err)
+
+	assert.Equal([]string{"zombie"}, *ran)
+}
+
+func TestServer_Unix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "console")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	address := "unix:" + filepath.Join(dir, "abyss.sock")
+	c, _ := newTestConsole(t)
+
+	server, stop := serve(t, c, address)
+
+	assert := testify.New(t)
+	assert.Equal(filepath.Join(dir, "abyss.sock"), server.Addr().String())
+
+	if info, err := os.Stat(filepath.Join(dir, "abyss.sock")); assert.NoError(err) {
+		assert.Equal(os.FileMode(0o600), info.Mode().Perm(), "only the owner can connect")
+	}
+
+	if files, err := ioutil.ReadDir(dir); assert.NoError(err) {
+		assert.Len(files, 1, "the directory the socket is created in is removed")
+	}
+
+	client, err := Dial(address, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.Run("help spawn")
+	testify.NoError(t, err)
+	testify.True(t, response.OK)
+
+	_ = client.Close()
+
+	stop()
+
+	_, err = os.Stat(filepath.Join(dir, "abyss.sock"))
+	assert.True(os.IsNotExist(err), "the socket is removed on Close")
+
+	// listening again on the same path works
+	_, stop = serve(t, c, address)
+	stop()
+}
+
+func TestServer_CloseWithPendingLine(t *testing.T) {
+	c, _ := newTestConsole(t)
+
+	server, err := Listen(c, "tcp:127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_ = server.Serve()
+	}()
+
+	client, err := Dial("tcp:"+server.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	failed := make(chan error)
+
+	go func() {
+		// nothing runs the queue, so the line waits until the server closes
+		_, err := client.Run("spawn zombie")
+		failed <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	testify.NoError(t, server.Close())
+	testify.Error(t, <-failed)
+}
+
+func TestParseAddress(t *testing.T) {
+	assert := testify.New(t)
+
+	network, path, err := ParseAddress(DefaultRemoteAddress())
+	assert.NoError(err)
+	assert.Equal("unix", network)
+	assert.Equal("console.sock", filepath.Base(path))
+
+	_, _, err = ParseAddress("tcp:localhost:6470")
+	assert.NoError(err)
+
+	_, _, err = ParseAddress("tcp:[::1]:6470")
+	assert.NoError(err)
+
+	_, _, err = ParseAddress("tcp:0.0.0.0:6470")
+	assert.Error(err)
+
+	_, _, err = ParseAddress("udp:127.0.0.1:6470")
+	assert.Error(err)
+
+	_, _, err = ParseAddress("unix:")
+	assert.Error(err)
+}
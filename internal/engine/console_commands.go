@@ -0,0 +1,334 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"image/png"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/console"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/input"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/scenemanager"
+	"github.com/gravestench/akara"
+	log "github.com/sirupsen/logrus"
+)
+
+// Console returns the debug console, subsystems register their commands on it
+func (engine *Engine) Console() *console.Console {
+	return engine.console
+}
+
+func (engine *Engine) registerConsoleCommands() error {
+	commands := []console.Command{
+		{
+			Name:     "get",
+			Usage:    "[field]",
+			Help:     "shows a configuration field, or all of them",
+			MaxArgs:  1,
+			Complete: engine.completeConfigField,
+			Run:      engine.getConfigField,
+		},
+		{
+			Name:     "set",
+			Usage:    "<field> <value>",
+			Help:     "sets a configuration field, lists are comma separated",
+			MinArgs:  2,
+			MaxArgs:  -1,
+			Complete: engine.completeConfigField,
+			Run:      engine.setConfigField,
+		},
+		{
+			Name: "scenes",
+			Help: "lists the scene stack from the bottom, * marks the scene shown",
+			Run:  engine.listScenes,
+		},
+		{
+			Name:     "scene",
+			Usage:    "<push|replace|pop> [name]",
+			Help:     "pushes or replaces a registered scene, or pops the top one",
+			MinArgs:  1,
+			MaxArgs:  2,
+			Complete: engine.completeScene,
+			Run:      engine.changeScene,
+		},
+		{
+			Name: "ecs",
+			Help: "counts the entities and the entities of each component",
+			Run:  engine.countEntities,
+		},
+		{
+			Name:     "loglevel",
+			Usage:    "[level]",
+			Help:     "shows or sets the log level",
+			MaxArgs:  1,
+			Complete: completeLogLevel,
+			Run:      setLogLevel,
+		},
+		{
+			Name:     "bind",
+			Usage:    "[action] [binding...]",
+			Help:     "lists the key bindings of all actions or one, or rebinds an action",
+			MaxArgs:  -1,
+			Complete: engine.completeAction,
+			Run:      engine.bindAction,
+		},
+		{
+			Name:     "unbind",
+			Usage:    "<action>",
+			Help:     "removes the key bindings of an action",
+			MinArgs:  1,
+			MaxArgs:  1,
+			Complete: engine.completeAction,
+			Run: func(args console.Args) (string, error) {
+				engine.actions.Unbind(args[0])
+				engine.config.KeyBindings = engine.actions.Export()
+
+				return "", nil
+			},
+		},
+		{
+			Name: "quit",
+			Help: "stops the engine",
+			Run: func(console.Args) (string, error) {
+				engine.Quit()
+				return "", nil
+			},
+		},
+	}
+
+	if screenshotter, ok := engine.gfx.(graphicsbackend.Screenshotter); ok {
+		commands = append(commands, console.Command{
+			Name:    "screenshot",
+			Usage:   "<path>",
+			Help:    "saves the last frame as a PNG file",
+			MinArgs: 1,
+			MaxArgs: 1,
+			Run: func(args console.Args) (string, error) {
+				return args[0], saveScreenshot(screenshotter, args[0])
+			},
+		})
+	}
+
+	for _, command := range commands {
+		if err := engine.console.Register(command); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (engine *Engine) completeConfigField(args console.Args) []string {
+	if len(args) > 1 {
+		return nil
+	}
+
+	return engine.config.FieldNames()
+}
+
+func (engine *Engine) getConfigField(args console.Args) (string, error) {
+	if len(args) == 1 {
+		return engine.config.Field(args[0])
+	}
+
+	var builder strings.Builder
+
+	for _, name := range engine.config.FieldNames() {
+		value, err := engine.config.Field(name)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&builder, "%s = %s\n", name, value)
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n"), nil
+}
+
+func (engine *Engine) setConfigField(args console.Args) (string, error) {
+	if err := engine.config.SetField(args[0], args.Rest(1)); err != nil {
+		return "", err
+	}
+
+	return engine.config.Field(args[0])
+}
+
+func (engine *Engine) listScenes(console.Args) (string, error) {
+	scenes := engine.sceneManager.Scenes()
+	shown := engine.sceneManager.Shown()
+	lines := make([]string, 0, len(scenes)+1)
+
+	for idx, scene := range scenes {
+		marker := " "
+		if scene == shown {
+			marker = "*"
+		}
+
+		lines = append(lines, fmt.Sprintf("%s %d %T", marker, idx, scene))
+	}
+
+	if engine.sceneManager.IsLoading() {
+		lines = append(lines, fmt.Sprintf("  loading, showing %T", shown))
+	}
+
+	if len(lines) == 0 {
+		return "no scenes", nil
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (engine *Engine) completeScene(args console.Args) []string {
+	switch len(args) {
+	case 1:
+		return []string{"pop", "push", "replace"}
+	case 2:
+		if strings.EqualFold(args[0], "pop") {
+			return nil
+		}
+
+		return engine.sceneManager.Names()
+	default:
+		return nil
+	}
+}
+
+// changeScene queues a scene change, it is carried out by the next update of
+// the scene manager
+func (engine *Engine) changeScene(args console.Args) (string, error) {
+	operation := strings.ToLower(args[0])
+
+	if operation == "pop" {
+		if len(args) > 1 {
+			return "", errors.New("usage: scene pop")
+		}
+
+		engine.sceneManager.Pop(scenemanager.Cut())
+
+		return "", nil
+	}
+
+	if operation != "push" && operation != "replace" {
+		return "", fmt.Errorf("unknown scene operation %q", args[0])
+	}
+
+	if len(args) < 2 {
+		return "", fmt.Errorf("usage: scene %s <name>", operation)
+	}
+
+	scene, err := engine.sceneManager.Create(args[1])
+	if err != nil {
+		return "", err
+	}
+
+	if operation == "push" {
+		engine.sceneManager.Push(scene, scenemanager.Cut())
+	} else {
+		engine.sceneManager.Replace(scene, scenemanager.Cut())
+	}
+
+	return "", nil
+}
+
+func (engine *Engine) countEntities(console.Args) (string, error) {
+	counts := make(map[akara.ComponentID]int)
+
+	for _, flags := range engine.ecs.ComponentFlags {
+		for _, id := range flags.ToIntArray() {
+			counts[akara.ComponentID(id)]++
+		}
+	}
+
+	ids := make([]akara.ComponentID, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	lines := []string{fmt.Sprintf("%d entities", len(engine.ecs.ComponentFlags))}
+	for _, id := range ids {
+		lines = append(lines, fmt.Sprintf("component %d: %d", id, counts[id]))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (engine *Engine) completeAction(args console.Args) []string {
+	if len(args) > 1 {
+		return nil
+	}
+
+	return engine.actions.Actions()
+}
+
+// bindAction rebinds an action when given bindings, the configuration is
+// updated so that they are saved
+func (engine *Engine) bindAction(args console.Args) (string, error) {
+	if len(args) == 0 {
+		return engine.actions.String(), nil
+	}
+
+	if len(args) > 1 {
+		bindings := make([]input.Binding, 0, len(args)-1)
+
+		for _, text := range args[1:] {
+			binding, err := input.ParseBinding(text)
+			if err != nil {
+				return "", err
+			}
+
+			bindings = append(bindings, binding)
+		}
+
+		if err := engine.actions.Bind(args[0], bindings...); err != nil {
+			return "", err
+		}
+
+		engine.config.KeyBindings = engine.actions.Export()
+	}
+
+	return strings.Join(engine.actions.Export()[args[0]], " "), nil
+}
+
+func completeLogLevel(console.Args) []string {
+	result := make([]string, len(log.AllLevels))
+
+	for idx, level := range log.AllLevels {
+		result[idx] = level.String()
+	}
+
+	return result
+}
+
+func setLogLevel(args console.Args) (string, error) {
+	if len(args) == 1 {
+		level, err := log.ParseLevel(args[0])
+		if err != nil {
+			return "", err
+		}
+
+		log.SetLevel(level)
+	}
+
+	return log.GetLevel().String(), nil
+}
+
+func saveScreenshot(screenshotter graphicsbackend.Screenshotter, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, screenshotter.Screenshot()); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/scenemanager"
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestEngine_ConsoleCommands(t *testing.T) {
+	assert := testify.New(t)
+	e := newTestEngine(t, &testInput{})
+
+	set := e.Console().Submit("set fpscap 30")
+	get := e.Console().Submit("get FpsCap")
+	scenes := e.Console().Submit("scenes")
+
+	assert.NoError(e.update(0, time.Millisecond))
+
+	assert.Equal("30", (<-set).Output)
+	assert.Equal("30", (<-get).Output)
+	assert.Equal("no scenes", (<-scenes).Output)
+	assert.Equal(30, e.config.FpsCap)
+
+	quit := e.Console().Submit("quit")
+
+	assert.NoError(e.Run(context.Background()), "quit stops the engine on the next tick")
+	assert.NoError((<-quit).Err)
+}
+
+func TestEngine_BindCommand(t *testing.T) {
+	assert := testify.New(t)
+	e := newTestEngine(t, &testInput{})
+
+	assert.Equal([]string{"I", "B"}, e.config.KeyBindings["toggle_inventory"], "the whole layout is saved")
+
+	rebind := e.Console().Submit(`bind toggle_inventory "Control+I"`)
+	conflict := e.Console().Submit("bind toggle_party C")
+	unbind := e.Console().Submit("unbind game_menu")
+	show := e.Console().Submit("bind toggle_inventory")
+
+	assert.NoError(e.update(0, time.Millisecond))
+
+	assert.Equal("Control+I", (<-rebind).Output)
+	assert.NoError((<-unbind).Err)
+	assert.Equal("Control+I", (<-show).Output)
+	assert.EqualError((<-conflict).Err, "C of toggle_party is already bound to toggle_character")
+	assert.Equal([]string{"Control+I"}, e.config.KeyBindings["toggle_inventory"])
+	assert.Equal([]string{}, e.config.KeyBindings["game_menu"])
+}
+
+func TestEngine_SceneCommand(t *testing.T) {
+	assert := testify.New(t)
+	e := newTestEngine(t, &testInput{})
+
+	// the test loader has no files for the loading screen
+	e.sceneManager.SetLoadingScreen(nil)
+
+	created := make([]*testScene, 0)
+	assert.NoError(e.sceneManager.Register("menu", func() scenemanager.Scene {
+		scene := &testScene{}
+		created = append(created, scene)
+
+		return scene
+	}))
+
+	assert.Equal([]string{"scene pop", "scene push", "scene replace"}, e.Console().Complete("scene "))
+	assert.Equal([]string{"scene push menu"}, e.Console().Complete("scene push m"))
+
+	push := e.Console().Submit("scene push menu")
+	replace := e.Console().Submit("scene replace MENU")
+	unknown := e.Console().Submit("scene push credits")
+	missing := e.Console().Submit("scene push")
+
+	// the scenes load in the background
+	deadline := time.Now().Add(time.Second)
+
+	for len(created) < 2 || e.sceneManager.IsLoading() {
+		assert.NoError(e.update(0, time.Millisecond))
+
+		if time.Now().After(deadline) {
+			t.Fatal("the scenes didn't load")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.NoError((<-push).Err)
+	assert.NoError((<-replace).Err)
+	assert.EqualError((<-unknown).Err, "unknown scene: credits")
+	assert.EqualError((<-missing).Err, "usage: scene push <name>")
+	assert.Len(created, 2)
+	assert.Equal([]scenemanager.Scene{created[1]}, e.sceneManager.Scenes())
+
+	pop := e.Console().Submit("scene pop")
+	assert.NoError(e.update(0, time.Millisecond))
+	assert.NoError((<-pop).Err)
+	assert.Empty(e.sceneManager.Scenes())
+}
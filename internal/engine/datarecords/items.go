@@ -0,0 +1,574 @@
+package datarecords
+
+import (
+	"fmt"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+)
+
+const (
+	numItemTypeEquivs    = 2
+	numUniqueProperties  = 12
+	numSetItemProperties = 9
+	numAffixMods         = 3
+	numAffixIncludeTypes = 7
+	numAffixExcludeTypes = 5
+	numOpStats           = 3
+)
+
+// ItemKind is the table an item record was read from
+type ItemKind int
+
+// Item kinds
+const (
+	ItemKindWeapon ItemKind = iota
+	ItemKindArmor
+	ItemKindMisc
+)
+
+// ItemTypeRecord is a row of ItemTypes.txt
+type ItemTypeRecord struct {
+	Name          string                    `txt:"ItemType"`
+	Code          string                    `txt:"Code"`
+	EquivCodes    [numItemTypeEquivs]string `txt:"Equiv%d,base=1"`
+	Repair        bool                      `txt:"Repair"`
+	Body          bool                      `txt:"Body"`
+	BodyLoc1      string                    `txt:"BodyLoc1"`
+	BodyLoc2      string                    `txt:"BodyLoc2"`
+	ShootsCode    string                    `txt:"Shoots"`
+	QuiverCode    string                    `txt:"Quiver"`
+	Throwable     bool                      `txt:"Throwable"`
+	Reload        bool                      `txt:"Reload"`
+	ReEquip       bool                      `txt:"ReEquip"`
+	AutoStack     bool                      `txt:"AutoStack"`
+	Magic         bool                      `txt:"Magic"`
+	Rare          bool                      `txt:"Rare"`
+	Normal        bool                      `txt:"Normal"`
+	Charm         bool                      `txt:"Charm"`
+	Gem           bool                      `txt:"Gem"`
+	Beltable      bool                      `txt:"Beltable"`
+	MaxSockets1   int                       `txt:"MaxSock1"`
+	MaxSockets25  int                       `txt:"MaxSock25"`
+	MaxSockets40  int                       `txt:"MaxSock40"`
+	TreasureClass bool                      `txt:"TreasureClass"`
+	Rarity        int                       `txt:"Rarity"`
+	StaffMods     string                    `txt:"StaffMods"`
+	CostFormula   int                       `txt:"CostFormula"`
+	Class         string                    `txt:"Class"`
+	VarInvGfx     int                       `txt:"VarInvGfx"`
+	StorePage     string                    `txt:"StorePage"`
+
+	Equivs []*ItemTypeRecord
+	Shoots *ItemTypeRecord
+	Quiver *ItemTypeRecord
+}
+
+// ItemRecord is a row of Weapons.txt, Armor.txt or Misc.txt. The columns
+// that only some of the tables have are zero for the others.
+type ItemRecord struct {
+	Name            string `txt:"name"`
+	Code            string `txt:"code"`
+	NameStr         string `txt:"namestr"`
+	Version         int    `txt:"version"`
+	CompactSave     bool   `txt:"compactsave"`
+	Rarity          int    `txt:"rarity"`
+	Spawnable       bool   `txt:"spawnable"`
+	Level           int    `txt:"level"`
+	RequiredLevel   int    `txt:"levelreq"`
+	Cost            int    `txt:"cost"`
+	GambleCost      int    `txt:"gamble cost"`
+	TypeCode        string `txt:"type"`
+	Type2Code       string `txt:"type2"`
+	InvWidth        int    `txt:"invwidth"`
+	InvHeight       int    `txt:"invheight"`
+	InvFile         string `txt:"invfile"`
+	NormalCode      string `txt:"normcode,optional"`
+	ExceptionalCode string `txt:"ubercode,optional"`
+	EliteCode       string `txt:"ultracode,optional"`
+	RequiredStr     int    `txt:"reqstr,optional"`
+	RequiredDex     int    `txt:"reqdex,optional"`
+	Durability      int    `txt:"durability,optional"`
+	MinDamage       int    `txt:"mindam,optional"`
+	MaxDamage       int    `txt:"maxdam,optional"`
+	MinAC           int    `txt:"minac,optional"`
+	MaxAC           int    `txt:"maxac,optional"`
+	WeaponClass     string `txt:"wclass,optional"`
+	GemSockets      int    `txt:"gemsockets,optional"`
+	Stackable       bool   `txt:"stackable,optional"`
+
+	Kind        ItemKind
+	Type        *ItemTypeRecord
+	Type2       *ItemTypeRecord
+	Normal      *ItemRecord
+	Exceptional *ItemRecord
+	Elite       *ItemRecord
+}
+
+// Property is a modifier of an item, the parameter and the range depend on the
+// property
+type Property struct {
+	Code      string
+	Parameter string
+	Min       int
+	Max       int
+}
+
+// properties returns the used properties of the numbered property columns
+func properties(codes, parameters []string, mins, maxs []int) []Property {
+	result := make([]Property, 0)
+
+	for idx, code := range codes {
+		if code == "" {
+			continue
+		}
+
+		result = append(result, Property{Code: code, Parameter: parameters[idx], Min: mins[idx], Max: maxs[idx]})
+	}
+
+	return result
+}
+
+// UniqueItemRecord is a row of UniqueItems.txt
+type UniqueItemRecord struct {
+	Name          string                      `txt:"index"`
+	Version       int                         `txt:"version"`
+	Enabled       bool                        `txt:"enabled"`
+	Ladder        bool                        `txt:"ladder"`
+	Rarity        int                         `txt:"rarity"`
+	NoLimit       bool                        `txt:"nolimit"`
+	Level         int                         `txt:"lvl"`
+	RequiredLevel int                         `txt:"lvl req"`
+	Code          string                      `txt:"code"`
+	Carry1        bool                        `txt:"carry1"`
+	CostMultiply  int                         `txt:"cost mult"`
+	CostAdd       int                         `txt:"cost add"`
+	InvFile       string                      `txt:"invfile"`
+	PropCodes     [numUniqueProperties]string `txt:"prop%d,base=1"`
+	PropParams    [numUniqueProperties]string `txt:"par%d,base=1"`
+	PropMins      [numUniqueProperties]int    `txt:"min%d,base=1"`
+	PropMaxs      [numUniqueProperties]int    `txt:"max%d,base=1"`
+
+	// Item is nil for the rows without an item code
+	Item       *ItemRecord
+	Properties []Property
+}
+
+// SetItemRecord is a row of SetItems.txt
+type SetItemRecord struct {
+	Name          string                       `txt:"index"`
+	Set           string                       `txt:"set"`
+	Code          string                       `txt:"item"`
+	Rarity        int                          `txt:"rarity"`
+	Level         int                          `txt:"lvl"`
+	RequiredLevel int                          `txt:"lvl req"`
+	InvFile       string                       `txt:"invfile"`
+	CostMultiply  int                          `txt:"cost mult"`
+	CostAdd       int                          `txt:"cost add"`
+	AddFunc       int                          `txt:"add func"`
+	PropCodes     [numSetItemProperties]string `txt:"prop%d,base=1"`
+	PropParams    [numSetItemProperties]string `txt:"par%d,base=1"`
+	PropMins      [numSetItemProperties]int    `txt:"min%d,base=1"`
+	PropMaxs      [numSetItemProperties]int    `txt:"max%d,base=1"`
+
+	Item       *ItemRecord
+	Properties []Property
+}
+
+// MagicAffixRecord is a row of MagicPrefix.txt or MagicSuffix.txt
+type MagicAffixRecord struct {
+	Name               string                       `txt:"Name"`
+	Version            int                          `txt:"version"`
+	Spawnable          bool                         `txt:"spawnable"`
+	Rare               bool                         `txt:"rare"`
+	Level              int                          `txt:"level"`
+	MaxLevel           int                          `txt:"maxlevel"`
+	RequiredLevel      int                          `txt:"levelreq"`
+	ClassSpecific      string                       `txt:"classspecific"`
+	Class              string                       `txt:"class"`
+	ClassRequiredLevel int                          `txt:"classlevelreq"`
+	Frequency          int                          `txt:"frequency"`
+	Group              int                          `txt:"group"`
+	ModCodes           [numAffixMods]string         `txt:"mod%dcode,base=1"`
+	ModParams          [numAffixMods]string         `txt:"mod%dparam,base=1"`
+	ModMins            [numAffixMods]int            `txt:"mod%dmin,base=1"`
+	ModMaxs            [numAffixMods]int            `txt:"mod%dmax,base=1"`
+	Transform          bool                         `txt:"transform"`
+	TransformColor     string                       `txt:"transformcolor"`
+	IncludeTypeCodes   [numAffixIncludeTypes]string `txt:"itype%d,base=1"`
+	ExcludeTypeCodes   [numAffixExcludeTypes]string `txt:"etype%d,base=1,optional"`
+	Divide             int                          `txt:"divide"`
+	Multiply           int                          `txt:"multiply"`
+	Add                int                          `txt:"add"`
+
+	Properties   []Property
+	IncludeTypes []*ItemTypeRecord
+	ExcludeTypes []*ItemTypeRecord
+}
+
+// ItemStatCostRecord is a row of ItemStatCost.txt
+type ItemStatCostRecord struct {
+	Name           string             `txt:"Stat"`
+	ID             int                `txt:"ID"`
+	SendOther      bool               `txt:"Send Other"`
+	Signed         bool               `txt:"Signed"`
+	SendBits       int                `txt:"Send Bits"`
+	SendParamBits  int                `txt:"Send Param Bits"`
+	UpdateAnimRate bool               `txt:"UpdateAnimRate"`
+	Saved          bool               `txt:"Saved"`
+	CSvSigned      bool               `txt:"CSvSigned"`
+	CSvBits        int                `txt:"CSvBits"`
+	CSvParam       int                `txt:"CSvParam"`
+	FCallback      bool               `txt:"fCallback"`
+	FMin           bool               `txt:"fMin"`
+	MinAccr        int                `txt:"MinAccr"`
+	Encode         int                `txt:"Encode"`
+	Add            int                `txt:"Add"`
+	Multiply       int                `txt:"Multiply"`
+	Divide         int                `txt:"Divide"`
+	ValShift       int                `txt:"ValShift"`
+	SaveBits       int                `txt:"Save Bits"`
+	SaveAdd        int                `txt:"Save Add"`
+	SaveParamBits  int                `txt:"Save Param Bits"`
+	KeepZero       bool               `txt:"keepzero"`
+	Op             int                `txt:"op"`
+	OpParam        int                `txt:"op param"`
+	OpBaseName     string             `txt:"op base"`
+	OpStatNames    [numOpStats]string `txt:"op stat%d,base=1"`
+	Direct         bool               `txt:"direct"`
+	MaxStatName    string             `txt:"maxstat"`
+	ItemSpecific   bool               `txt:"itemspecific"`
+	DamageRelated  bool               `txt:"damagerelated"`
+	DescPriority   int                `txt:"descpriority"`
+	DescFunc       int                `txt:"descfunc"`
+	DescVal        int                `txt:"descval"`
+	DescStrPos     string             `txt:"descstrpos"`
+	DescStrNeg     string             `txt:"descstrneg"`
+	DescStr2       string             `txt:"descstr2"`
+	DescGroup      int                `txt:"dgrp"`
+	DescGroupFunc  int                `txt:"dgrpfunc"`
+	DescGroupVal   int                `txt:"dgrpval"`
+	DescGroupPos   string             `txt:"dgrpstrpos"`
+	DescGroupNeg   string             `txt:"dgrpstrneg"`
+	DescGroupStr2  string             `txt:"dgrpstr2"`
+
+	OpBase  *ItemStatCostRecord
+	OpStats []*ItemStatCostRecord
+	MaxStat *ItemStatCostRecord
+}
+
+func (r *Records) loadItemTypes(loader resource.FileLoader) error {
+	var records []ItemTypeRecord
+	if err := loadTable(loader, resource.ItemTypes, &records); err != nil {
+		return err
+	}
+
+	r.ItemTypes = make(map[string]*ItemTypeRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.ItemTypes[record.Code]; found {
+			return duplicateError(resource.ItemTypes, "Code", record.Code)
+		}
+
+		r.ItemTypes[record.Code] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadItems(loader resource.FileLoader) error {
+	r.Items = make(map[string]*ItemRecord)
+
+	tables := []struct {
+		path  string
+		kind  ItemKind
+		items *map[string]*ItemRecord
+	}{
+		{resource.Weapons, ItemKindWeapon, &r.Weapons},
+		{resource.Armor, ItemKindArmor, &r.Armor},
+		{resource.Misc, ItemKindMisc, &r.Misc},
+	}
+
+	for _, table := range tables {
+		var records []ItemRecord
+		if err := loadTable(loader, table.path, &records); err != nil {
+			return err
+		}
+
+		*table.items = make(map[string]*ItemRecord, len(records))
+
+		for idx := range records {
+			record := &records[idx]
+			if _, found := r.Items[record.Code]; found {
+				return duplicateError(table.path, "code", record.Code)
+			}
+
+			record.Kind = table.kind
+			(*table.items)[record.Code] = record
+			r.Items[record.Code] = record
+		}
+	}
+
+	return nil
+}
+
+func (r *Records) loadUniqueItems(loader resource.FileLoader) error {
+	var records []UniqueItemRecord
+	if err := loadTable(loader, resource.UniqueItems, &records); err != nil {
+		return err
+	}
+
+	r.UniqueItems = make([]*UniqueItemRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		record.Properties = properties(record.PropCodes[:], record.PropParams[:], record.PropMins[:], record.PropMaxs[:])
+		r.UniqueItems[idx] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadSetItems(loader resource.FileLoader) error {
+	var records []SetItemRecord
+	if err := loadTable(loader, resource.SetItems, &records); err != nil {
+		return err
+	}
+
+	r.SetItems = make([]*SetItemRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		record.Properties = properties(record.PropCodes[:], record.PropParams[:], record.PropMins[:], record.PropMaxs[:])
+		r.SetItems[idx] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadMagicAffixes(loader resource.FileLoader) error {
+	tables := []struct {
+		path    string
+		affixes *[]*MagicAffixRecord
+	}{
+		{resource.MagicPrefix, &r.MagicPrefixes},
+		{resource.MagicSuffix, &r.MagicSuffixes},
+	}
+
+	for _, table := range tables {
+		var records []MagicAffixRecord
+		if err := loadTable(loader, table.path, &records); err != nil {
+			return err
+		}
+
+		*table.affixes = make([]*MagicAffixRecord, len(records))
+
+		for idx := range records {
+			record := &records[idx]
+			record.Properties = properties(record.ModCodes[:], record.ModParams[:], record.ModMins[:], record.ModMaxs[:])
+			(*table.affixes)[idx] = record
+		}
+	}
+
+	return nil
+}
+
+func (r *Records) loadItemStatCosts(loader resource.FileLoader) error {
+	var records []ItemStatCostRecord
+	if err := loadTable(loader, resource.ItemStatCost, &records); err != nil {
+		return err
+	}
+
+	r.ItemStatCosts = make(map[string]*ItemStatCostRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.ItemStatCosts[record.Name]; found {
+			return duplicateError(resource.ItemStatCost, "Stat", record.Name)
+		}
+
+		r.ItemStatCosts[record.Name] = record
+	}
+
+	return nil
+}
+
+// itemTypeOrNone finds an item type, an empty code refers to no type
+func (r *Records) itemTypeOrNone(code string) (*ItemTypeRecord, bool) {
+	if code == "" {
+		return nil, true
+	}
+
+	itemType, found := r.ItemTypes[code]
+
+	return itemType, found
+}
+
+// itemOrNone finds an item, an empty code refers to no item
+func (r *Records) itemOrNone(code string) (*ItemRecord, bool) {
+	if code == "" {
+		return nil, true
+	}
+
+	item, found := r.Items[code]
+
+	return item, found
+}
+
+// statOrNone finds a stat, an empty name refers to no stat
+func (r *Records) statOrNone(name string) (*ItemStatCostRecord, bool) {
+	if name == "" {
+		return nil, true
+	}
+
+	stat, found := r.ItemStatCosts[name]
+
+	return stat, found
+}
+
+func (r *Records) resolveItemTypes() error {
+	var found bool
+
+	for _, itemType := range r.ItemTypes {
+		itemType.Equivs = make([]*ItemTypeRecord, 0)
+
+		for idx, code := range itemType.EquivCodes {
+			equiv, found := r.itemTypeOrNone(code)
+			if !found {
+				return referenceError(resource.ItemTypes, itemType.Code, fmt.Sprintf("Equiv%d", idx+1), code)
+			}
+
+			if equiv != nil {
+				itemType.Equivs = append(itemType.Equivs, equiv)
+			}
+		}
+
+		if itemType.Shoots, found = r.itemTypeOrNone(itemType.ShootsCode); !found {
+			return referenceError(resource.ItemTypes, itemType.Code, "Shoots", itemType.ShootsCode)
+		}
+
+		if itemType.Quiver, found = r.itemTypeOrNone(itemType.QuiverCode); !found {
+			return referenceError(resource.ItemTypes, itemType.Code, "Quiver", itemType.QuiverCode)
+		}
+	}
+
+	return nil
+}
+
+//nolint:gocyclo // every reference is checked
+func (r *Records) resolveItems() error {
+	var found bool
+
+	for _, item := range r.Items {
+		table := []string{resource.Weapons, resource.Armor, resource.Misc}[item.Kind]
+
+		if item.Type, found = r.ItemTypes[item.TypeCode]; !found {
+			return referenceError(table, item.Code, "type", item.TypeCode)
+		}
+
+		if item.Type2, found = r.itemTypeOrNone(item.Type2Code); !found {
+			return referenceError(table, item.Code, "type2", item.Type2Code)
+		}
+
+		if item.Normal, found = r.itemOrNone(item.NormalCode); !found {
+			return referenceError(table, item.Code, "normcode", item.NormalCode)
+		}
+
+		if item.Exceptional, found = r.itemOrNone(item.ExceptionalCode); !found {
+			return referenceError(table, item.Code, "ubercode", item.ExceptionalCode)
+		}
+
+		if item.Elite, found = r.itemOrNone(item.EliteCode); !found {
+			return referenceError(table, item.Code, "ultracode", item.EliteCode)
+		}
+	}
+
+	for _, unique := range r.UniqueItems {
+		if unique.Item, found = r.itemOrNone(unique.Code); !found {
+			return referenceError(resource.UniqueItems, unique.Name, "code", unique.Code)
+		}
+	}
+
+	for _, setItem := range r.SetItems {
+		if setItem.Item, found = r.Items[setItem.Code]; !found {
+			return referenceError(resource.SetItems, setItem.Name, "item", setItem.Code)
+		}
+	}
+
+	return nil
+}
+
+func (r *Records) resolveMagicAffixes() error {
+	tables := []struct {
+		path    string
+		affixes []*MagicAffixRecord
+	}{
+		{resource.MagicPrefix, r.MagicPrefixes},
+		{resource.MagicSuffix, r.MagicSuffixes},
+	}
+
+	for _, table := range tables {
+		for _, affix := range table.affixes {
+			var err error
+
+			affix.IncludeTypes, err = r.itemTypeList(table.path, affix.Name, "itype%d", affix.IncludeTypeCodes[:])
+			if err != nil {
+				return err
+			}
+
+			affix.ExcludeTypes, err = r.itemTypeList(table.path, affix.Name, "etype%d", affix.ExcludeTypeCodes[:])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// itemTypeList resolves the used item type codes of numbered columns
+func (r *Records) itemTypeList(table, record, columnFormat string, codes []string) ([]*ItemTypeRecord, error) {
+	result := make([]*ItemTypeRecord, 0)
+
+	for idx, code := range codes {
+		itemType, found := r.itemTypeOrNone(code)
+		if !found {
+			return nil, referenceError(table, record, fmt.Sprintf(columnFormat, idx+1), code)
+		}
+
+		if itemType != nil {
+			result = append(result, itemType)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Records) resolveItemStatCosts() error {
+	var found bool
+
+	for _, stat := range r.ItemStatCosts {
+		if stat.OpBase, found = r.statOrNone(stat.OpBaseName); !found {
+			return referenceError(resource.ItemStatCost, stat.Name, "op base", stat.OpBaseName)
+		}
+
+		if stat.MaxStat, found = r.statOrNone(stat.MaxStatName); !found {
+			return referenceError(resource.ItemStatCost, stat.Name, "maxstat", stat.MaxStatName)
+		}
+
+		stat.OpStats = make([]*ItemStatCostRecord, 0)
+
+		for idx, name := range stat.OpStatNames {
+			opStat, found := r.statOrNone(name)
+			if !found {
+				return referenceError(resource.ItemStatCost, stat.Name, fmt.Sprintf("op stat%d", idx+1), name)
+			}
+
+			if opStat != nil {
+				stat.OpStats = append(stat.OpStats, opStat)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+package datarecords
+
+import (
+	"fmt"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+)
+
+const (
+	numLevelVis       = 8
+	numLevelWarps     = 8
+	numLevelMonsters  = 25
+	numLevelTypeFiles = 32
+	numPresetFiles    = 6
+
+	noLevel = 0
+	noWarp  = -1
+)
+
+// LevelRecord is a row of Levels.txt
+type LevelRecord struct {
+	Name        string                   `txt:"Name"`
+	ID          int                      `txt:"Id"`
+	Act         int                      `txt:"Act"`
+	Layer       int                      `txt:"Layer"`
+	SizeX       int                      `txt:"SizeX"`
+	SizeY       int                      `txt:"SizeY"`
+	OffsetX     int                      `txt:"OffsetX"`
+	OffsetY     int                      `txt:"OffsetY"`
+	DependID    int                      `txt:"Depend"`
+	Teleport    int                      `txt:"Teleport"`
+	Rain        bool                     `txt:"Rain"`
+	Mud         bool                     `txt:"Mud"`
+	IsInside    bool                     `txt:"IsInside"`
+	DrlgType    int                      `txt:"DrlgType"`
+	LevelTypeID int                      `txt:"LevelType"`
+	VisIDs      [numLevelVis]int         `txt:"Vis%d"`
+	WarpIDs     [numLevelWarps]int       `txt:"Warp%d"`
+	Quest       int                      `txt:"Quest"`
+	MonsterIDs  [numLevelMonsters]string `txt:"mon%d,base=1,optional"`
+	Waypoint    int                      `txt:"Waypoint"`
+	LevelName   string                   `txt:"LevelName"`
+	LevelWarp   string                   `txt:"LevelWarp"`
+	EntryFile   string                   `txt:"EntryFile"`
+
+	LevelType *LevelTypeRecord
+	Depend    *LevelRecord
+	Vis       [numLevelVis]*LevelRecord
+	Warps     [numLevelWarps]*LevelWarpRecord
+	Monsters  []*MonStatsRecord
+}
+
+// LevelTypeRecord is a row of LvlTypes.txt
+type LevelTypeRecord struct {
+	Name  string                    `txt:"Name"`
+	ID    int                       `txt:"Id"`
+	Files [numLevelTypeFiles]string `txt:"File %d,base=1"`
+	Act   int                       `txt:"Act"`
+
+	// DT1Files are the resource paths of the used Files
+	DT1Files []string
+}
+
+// LevelPresetRecord is a row of LvlPrest.txt
+type LevelPresetRecord struct {
+	Name       string                 `txt:"Name"`
+	Def        int                    `txt:"Def"`
+	LevelID    int                    `txt:"LevelId"`
+	Populate   bool                   `txt:"Populate"`
+	Logicals   bool                   `txt:"Logicals"`
+	Outdoors   bool                   `txt:"Outdoors"`
+	Animate    bool                   `txt:"Animate"`
+	KillEdge   bool                   `txt:"KillEdge"`
+	FillBlanks bool                   `txt:"FillBlanks"`
+	SizeX      int                    `txt:"SizeX"`
+	SizeY      int                    `txt:"SizeY"`
+	AutoMap    bool                   `txt:"AutoMap"`
+	Scan       bool                   `txt:"Scan"`
+	Pops       int                    `txt:"Pops"`
+	PopPad     int                    `txt:"PopPad"`
+	NumFiles   int                    `txt:"Files"`
+	Files      [numPresetFiles]string `txt:"File%d,base=1"`
+	Dt1Mask    uint32                 `txt:"Dt1Mask"`
+
+	// Level is nil for presets that are not bound to a level
+	Level *LevelRecord
+	// DS1Files are the resource paths of the used Files
+	DS1Files []string
+}
+
+// LevelWarpRecord is a row of LvlWarp.txt
+type LevelWarpRecord struct {
+	Name       string `txt:"Name"`
+	ID         int    `txt:"Id"`
+	SelectX    int    `txt:"SelectX"`
+	SelectY    int    `txt:"SelectY"`
+	SelectDX   int    `txt:"SelectDX"`
+	SelectDY   int    `txt:"SelectDY"`
+	ExitWalkX  int    `txt:"ExitWalkX"`
+	ExitWalkY  int    `txt:"ExitWalkY"`
+	OffsetX    int    `txt:"OffsetX"`
+	OffsetY    int    `txt:"OffsetY"`
+	LitVersion bool   `txt:"LitVersion"`
+	Tiles      int    `txt:"Tiles"`
+	Direction  string `txt:"Direction"`
+}
+
+// LevelMazeRecord is a row of LvlMaze.txt
+type LevelMazeRecord struct {
+	Name           string `txt:"Name"`
+	LevelID        int    `txt:"Level"`
+	Rooms          int    `txt:"Rooms"`
+	RoomsNightmare int    `txt:"Rooms(N)"`
+	RoomsHell      int    `txt:"Rooms(H)"`
+	SizeX          int    `txt:"SizeX"`
+	SizeY          int    `txt:"SizeY"`
+	Merge          int    `txt:"Merge"`
+
+	Level *LevelRecord
+}
+
+func (r *Records) loadLevels(loader resource.FileLoader) error {
+	var records []LevelRecord
+	if err := loadTable(loader, resource.LevelDetails, &records); err != nil {
+		return err
+	}
+
+	r.Levels = make(map[int]*LevelRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.Levels[record.ID]; found {
+			return duplicateError(resource.LevelDetails, "Id", record.ID)
+		}
+
+		r.Levels[record.ID] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadLevelTypes(loader resource.FileLoader) error {
+	var records []LevelTypeRecord
+	if err := loadTable(loader, resource.LevelType, &records); err != nil {
+		return err
+	}
+
+	r.LevelTypes = make(map[int]*LevelTypeRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.LevelTypes[record.ID]; found {
+			return duplicateError(resource.LevelType, "Id", record.ID)
+		}
+
+		record.DT1Files = tilePaths(record.Files[:])
+		r.LevelTypes[record.ID] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadLevelPresets(loader resource.FileLoader) error {
+	var records []LevelPresetRecord
+	if err := loadTable(loader, resource.LevelPreset, &records); err != nil {
+		return err
+	}
+
+	r.LevelPresets = make(map[int]*LevelPresetRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.LevelPresets[record.Def]; found {
+			return duplicateError(resource.LevelPreset, "Def", record.Def)
+		}
+
+		record.DS1Files = tilePaths(record.Files[:])
+		r.LevelPresets[record.Def] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadLevelWarps(loader resource.FileLoader) error {
+	var records []LevelWarpRecord
+	if err := loadTable(loader, resource.LevelWarp, &records); err != nil {
+		return err
+	}
+
+	r.LevelWarps = make(map[int]*LevelWarpRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.LevelWarps[record.ID]; found {
+			return duplicateError(resource.LevelWarp, "Id", record.ID)
+		}
+
+		r.LevelWarps[record.ID] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadLevelMazes(loader resource.FileLoader) error {
+	var records []LevelMazeRecord
+	if err := loadTable(loader, resource.LevelMaze, &records); err != nil {
+		return err
+	}
+
+	r.LevelMazes = make(map[int]*LevelMazeRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.LevelMazes[record.LevelID]; found {
+			return duplicateError(resource.LevelMaze, "Level", record.LevelID)
+		}
+
+		r.LevelMazes[record.LevelID] = record
+	}
+
+	return nil
+}
+
+// levelOrNone finds a level, the id 0 refers to no level
+func (r *Records) levelOrNone(id int) (*LevelRecord, bool) {
+	if id == noLevel {
+		return nil, true
+	}
+
+	level, found := r.Levels[id]
+
+	return level, found
+}
+
+//nolint:gocyclo // every reference is checked
+func (r *Records) resolveLevels() error {
+	var found bool
+
+	for _, level := range r.Levels {
+		if level.LevelType, found = r.LevelTypes[level.LevelTypeID]; !found {
+			return referenceError(resource.LevelDetails, level.Name, "LevelType", level.LevelTypeID)
+		}
+
+		if level.Depend, found = r.levelOrNone(level.DependID); !found {
+			return referenceError(resource.LevelDetails, level.Name, "Depend", level.DependID)
+		}
+
+		for idx, id := range level.VisIDs {
+			if level.Vis[idx], found = r.levelOrNone(id); !found {
+				return referenceError(resource.LevelDetails, level.Name, fmt.Sprintf("Vis%d", idx), id)
+			}
+		}
+
+		for idx, id := range level.WarpIDs {
+			if id == noWarp {
+				continue
+			}
+
+			if level.Warps[idx], found = r.LevelWarps[id]; !found {
+				return referenceError(resource.LevelDetails, level.Name, fmt.Sprintf("Warp%d", idx), id)
+			}
+		}
+
+		level.Monsters = make([]*MonStatsRecord, 0)
+
+		for idx, id := range level.MonsterIDs {
+			if id == "" {
+				continue
+			}
+
+			monster, found := r.MonStats[id]
+			if !found {
+				return referenceError(resource.LevelDetails, level.Name, fmt.Sprintf("mon%d", idx+1), id)
+			}
+
+			level.Monsters = append(level.Monsters, monster)
+		}
+	}
+
+	for _, preset := range r.LevelPresets {
+		if preset.Level, found = r.levelOrNone(preset.LevelID); !found {
+			return referenceError(resource.LevelPreset, preset.Name, "LevelId", preset.LevelID)
+		}
+	}
+
+	for _, maze := range r.LevelMazes {
+		if maze.Level, found = r.Levels[maze.LevelID]; !found {
+			return referenceError(resource.LevelMaze, maze.Name, "Level", maze.LevelID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package datarecords
+
+import (
+	"fmt"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+)
+
+const numMinions = 2
+
+// MonStatsRecord is a row of MonStats.txt
+type MonStatsRecord struct {
+	ID             string             `txt:"Id"`
+	HcIdx          int                `txt:"hcIdx"`
+	BaseID         string             `txt:"BaseId"`
+	NextInClassID  string             `txt:"NextInClass"`
+	NameStr        string             `txt:"NameStr"`
+	MonStatsEx     string             `txt:"MonStatsEx"`
+	MonType        string             `txt:"MonType"`
+	AI             string             `txt:"AI"`
+	Code           string             `txt:"Code"`
+	Enabled        bool               `txt:"enabled"`
+	MinionIDs      [numMinions]string `txt:"minion%d,base=1"`
+	IsNPC          bool               `txt:"npc"`
+	InTown         bool               `txt:"inTown"`
+	LowUndead      bool               `txt:"lUndead"`
+	HighUndead     bool               `txt:"hUndead"`
+	Demon          bool               `txt:"demon"`
+	Flying         bool               `txt:"flying"`
+	Killable       bool               `txt:"killable"`
+	Velocity       int                `txt:"Velocity"`
+	Run            int                `txt:"Run"`
+	Rarity         int                `txt:"Rarity"`
+	Level          int                `txt:"Level"`
+	LevelNightmare int                `txt:"Level(N)"`
+	LevelHell      int                `txt:"Level(H)"`
+	MinHP          int                `txt:"MinHP"`
+	MaxHP          int                `txt:"MaxHP"`
+	AC             int                `txt:"AC"`
+	Experience     int                `txt:"Exp"`
+
+	Base        *MonStatsRecord
+	NextInClass *MonStatsRecord
+	Stats2      *MonStats2Record
+	Minions     []*MonStatsRecord
+}
+
+// MonStats2Record is a row of MonStats2.txt, the graphics and animation
+// settings of a monster
+type MonStats2Record struct {
+	ID            string `txt:"Id"`
+	Height        int    `txt:"Height"`
+	OverlayHeight int    `txt:"OverlayHeight"`
+	PixHeight     int    `txt:"pixHeight"`
+	SizeX         int    `txt:"SizeX"`
+	SizeY         int    `txt:"SizeY"`
+	SpawnCol      int    `txt:"spawnCol"`
+	MeleeRange    int    `txt:"MeleeRng"`
+	BaseWeapon    string `txt:"BaseW"`
+	HitClass      int    `txt:"HitClass"`
+}
+
+func (r *Records) loadMonStats(loader resource.FileLoader) error {
+	var records []MonStatsRecord
+	if err := loadTable(loader, resource.MonStats, &records); err != nil {
+		return err
+	}
+
+	r.MonStats = make(map[string]*MonStatsRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.MonStats[record.ID]; found {
+			return duplicateError(resource.MonStats, "Id", record.ID)
+		}
+
+		r.MonStats[record.ID] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadMonStats2(loader resource.FileLoader) error {
+	var records []MonStats2Record
+	if err := loadTable(loader, resource.MonStats2, &records); err != nil {
+		return err
+	}
+
+	r.MonStats2 = make(map[string]*MonStats2Record, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.MonStats2[record.ID]; found {
+			return duplicateError(resource.MonStats2, "Id", record.ID)
+		}
+
+		r.MonStats2[record.ID] = record
+	}
+
+	return nil
+}
+
+// monsterOrNone finds a monster, an empty id refers to no monster
+func (r *Records) monsterOrNone(id string) (*MonStatsRecord, bool) {
+	if id == "" {
+		return nil, true
+	}
+
+	monster, found := r.MonStats[id]
+
+	return monster, found
+}
+
+func (r *Records) resolveMonStats() error {
+	var found bool
+
+	for _, monster := range r.MonStats {
+		if monster.Base, found = r.monsterOrNone(monster.BaseID); !found {
+			return referenceError(resource.MonStats, monster.ID, "BaseId", monster.BaseID)
+		}
+
+		if monster.NextInClass, found = r.monsterOrNone(monster.NextInClassID); !found {
+			return referenceError(resource.MonStats, monster.ID, "NextInClass", monster.NextInClassID)
+		}
+
+		if monster.MonStatsEx != "" {
+			if monster.Stats2, found = r.MonStats2[monster.MonStatsEx]; !found {
+				return referenceError(resource.MonStats, monster.ID, "MonStatsEx", monster.MonStatsEx)
+			}
+		}
+
+		monster.Minions = make([]*MonStatsRecord, 0)
+
+		for idx, id := range monster.MinionIDs {
+			minion, found := r.monsterOrNone(id)
+			if !found {
+				return referenceError(resource.MonStats, monster.ID, fmt.Sprintf("minion%d", idx+1), id)
+			}
+
+			if minion != nil {
+				monster.Minions = append(monster.Minions, minion)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package datarecords
+
+import (
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+)
+
+// ObjectRecord is a row of Objects.txt
+type ObjectRecord struct {
+	Name        string `txt:"Name"`
+	ID          int    `txt:"Id"`
+	Token       string `txt:"Token"`
+	SpawnMax    int    `txt:"SpawnMax"`
+	SizeX       int    `txt:"SizeX"`
+	SizeY       int    `txt:"SizeY"`
+	IsDoor      bool   `txt:"IsDoor"`
+	BlocksLight bool   `txt:"BlocksLight"`
+	BlocksVis   bool   `txt:"BlocksVis"`
+	Lit         int    `txt:"Lit"`
+	XOffset     int    `txt:"Xoffset"`
+	YOffset     int    `txt:"Yoffset"`
+}
+
+func (r *Records) loadObjects(loader resource.FileLoader) error {
+	var records []ObjectRecord
+	if err := loadTable(loader, resource.ObjectDetails, &records); err != nil {
+		return err
+	}
+
+	r.Objects = make(map[int]*ObjectRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.Objects[record.ID]; found {
+			return duplicateError(resource.ObjectDetails, "Id", record.ID)
+		}
+
+		r.Objects[record.ID] = record
+	}
+
+	return nil
+}
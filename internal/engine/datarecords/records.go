@@ -0,0 +1,179 @@
+package datarecords
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+	d2txt "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/txtfile"
+)
+
+// tilesPath is the directory the tile and preset files of the level tables
+// are relative to
+const tilesPath = "/data/global/tiles"
+
+// noFile marks an unused file column in the level tables
+const noFile = "0"
+
+// ReferenceError is a record that refers to a record which doesn't exist
+type ReferenceError struct {
+	Table  string
+	Record string
+	Column string
+	Value  string
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("%s: record %q, column %q: %q not found", path.Base(e.Table), e.Record, e.Column, e.Value)
+}
+
+func referenceError(table, record, column string, value interface{}) error {
+	return &ReferenceError{Table: table, Record: record, Column: column, Value: fmt.Sprint(value)}
+}
+
+func duplicateError(table, column string, value interface{}) error {
+	return fmt.Errorf("%s: duplicate %s %v", path.Base(table), column, value)
+}
+
+// Records holds the data tables, indexed by the keys the other tables use to
+// refer to them
+type Records struct {
+	Levels       map[int]*LevelRecord
+	LevelTypes   map[int]*LevelTypeRecord
+	LevelPresets map[int]*LevelPresetRecord
+	LevelWarps   map[int]*LevelWarpRecord
+	LevelMazes   map[int]*LevelMazeRecord
+
+	MonStats  map[string]*MonStatsRecord
+	MonStats2 map[string]*MonStats2Record
+
+	Missiles map[string]*MissileRecord
+	Skills   map[string]*SkillRecord
+
+	ItemTypes     map[string]*ItemTypeRecord
+	Weapons       map[string]*ItemRecord
+	Armor         map[string]*ItemRecord
+	Misc          map[string]*ItemRecord
+	Items         map[string]*ItemRecord
+	UniqueItems   []*UniqueItemRecord
+	SetItems      []*SetItemRecord
+	MagicPrefixes []*MagicAffixRecord
+	MagicSuffixes []*MagicAffixRecord
+	ItemStatCosts map[string]*ItemStatCostRecord
+
+	Objects map[int]*ObjectRecord
+	Sounds  map[string]*SoundRecord
+}
+
+// Load reads the data tables and resolves their references, a reference to a
+// record that doesn't exist is a ReferenceError
+func Load(loader resource.FileLoader) (*Records, error) {
+	r := &Records{}
+
+	loaders := []func(resource.FileLoader) error{
+		r.loadLevelTypes,
+		r.loadLevelWarps,
+		r.loadLevels,
+		r.loadLevelPresets,
+		r.loadLevelMazes,
+		r.loadMonStats2,
+		r.loadMonStats,
+		r.loadMissiles,
+		r.loadSkills,
+		r.loadItemTypes,
+		r.loadItems,
+		r.loadUniqueItems,
+		r.loadSetItems,
+		r.loadMagicAffixes,
+		r.loadItemStatCosts,
+		r.loadObjects,
+		r.loadSounds,
+	}
+
+	for _, load := range loaders {
+		if err := load(loader); err != nil {
+			return nil, err
+		}
+	}
+
+	resolvers := []func() error{
+		r.resolveLevels,
+		r.resolveMonStats,
+		r.resolveMissiles,
+		r.resolveSkills,
+		r.resolveItemTypes,
+		r.resolveItems,
+		r.resolveMagicAffixes,
+		r.resolveItemStatCosts,
+	}
+
+	for _, resolve := range resolvers {
+		if err := resolve(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// loadTable reads a table into v, a pointer to a slice of tagged structs
+func loadTable(loader resource.FileLoader, table string, v interface{}) error {
+	data, err := loader.ReadFile(table)
+	if err != nil {
+		return fmt.Errorf("couldn't read %s: %v", table, err)
+	}
+
+	if err := d2txt.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %v", path.Base(table), err)
+	}
+
+	return nil
+}
+
+// tilePath returns the resource path of a file of the level tables, they are
+// stored relative to the tiles directory with backslashes
+func tilePath(file string) string {
+	return path.Join(tilesPath, strings.ReplaceAll(file, "\\", "/"))
+}
+
+// tilePaths returns the resource paths of the used file columns
+func tilePaths(files []string) []string {
+	result := make([]string, 0, len(files))
+
+	for _, file := range files {
+		if file == "" || file == noFile {
+			continue
+		}
+
+		result = append(result, tilePath(file))
+	}
+
+	return result
+}
+
+// Tables lists the paths of the tables read by Load
+func Tables() []string {
+	return []string{
+		resource.LevelDetails,
+		resource.LevelType,
+		resource.LevelPreset,
+		resource.LevelWarp,
+		resource.LevelMaze,
+		resource.MonStats,
+		resource.MonStats2,
+		resource.Missiles,
+		resource.Skills,
+		resource.ItemTypes,
+		resource.Weapons,
+		resource.Armor,
+		resource.Misc,
+		resource.UniqueItems,
+		resource.SetItems,
+		resource.MagicPrefix,
+		resource.MagicSuffix,
+		resource.ItemStatCost,
+		resource.ObjectDetails,
+		resource.SoundSettings,
+	}
+}
@@ -0,0 +1,270 @@
+package datarecords
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+	d2txt "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/txtfile"
+)
+
+type testLoader map[string][]byte
+
+func (l testLoader) ReadFile(path string) ([]byte, error) {
+	data, found := l[path]
+	if !found {
+		return nil, fmt.Errorf("%s not found", path)
+	}
+
+	return data, nil
+}
+
+// header lists the columns of a record type, in field order
+func header(recordType reflect.Type) []string {
+	columns := make([]string, 0)
+
+	for idx := 0; idx < recordType.NumField(); idx++ {
+		field := recordType.Field(idx)
+
+		tag, found := field.Tag.Lookup("txt")
+		if !found {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		name, base := parts[0], 0
+
+		for _, option := range parts[1:] {
+			if strings.HasPrefix(option, "base=") {
+				base, _ = strconv.Atoi(strings.TrimPrefix(option, "base="))
+			}
+		}
+
+		if !strings.Contains(name, "%d") {
+			columns = append(columns, name)
+			continue
+		}
+
+		for element := 0; element < field.Type.Len(); element++ {
+			columns = append(columns, strings.Replace(name, "%d", strconv.Itoa(base+element), 1))
+		}
+	}
+
+	return columns
+}
+
+type testTables map[string]interface{}
+
+func newTestTables() testTables {
+	noWarps := [numLevelWarps]int{-1, -1, -1, -1, -1, -1, -1, -1}
+
+	return testTables{
+		resource.LevelType: []LevelTypeRecord{
+			{Name: "Act 1 - Town", ID: 1, Files: [numLevelTypeFiles]string{`Act1\Town\Floor.dt1`, "0"}, Act: 1},
+		},
+		resource.LevelWarp: []LevelWarpRecord{{Name: "Act 1 - Wilderness to Cave", ID: 0}},
+		resource.LevelDetails: []LevelRecord{
+			{Name: "Act 1 - Town", ID: 1, LevelTypeID: 1, WarpIDs: noWarps},
+			{Name: "Act 1 - Wilderness 1", ID: 2, LevelTypeID: 1, WarpIDs: [numLevelWarps]int{0, -1, -1, -1, -1, -1, -1, -1},
+				VisIDs: [numLevelVis]int{1}, MonsterIDs: [numLevelMonsters]string{"fallen1"}},
+		},
+		resource.LevelPreset: []LevelPresetRecord{
+			{Name: "Act 1 - Town 1 Transition S", Def: 1, LevelID: 1, Files: [numPresetFiles]string{`Act1\Town\townE1.ds1`}},
+			{Name: "Act 1 - Wild Border 1", Def: 2},
+		},
+		resource.LevelMaze: []LevelMazeRecord{{Name: "Act 1 - Cave 1", LevelID: 2}},
+		resource.MonStats: []MonStatsRecord{
+			{ID: "fallen1", BaseID: "fallen1", NextInClassID: "fallen2", MonStatsEx: "fallen1"},
+			{ID: "fallen2", BaseID: "fallen1", MonStatsEx: "fallen1", MinionIDs: [numMinions]string{"", "fallen1"}},
+		},
+		resource.MonStats2: []MonStats2Record{{ID: "fallen1"}},
+		resource.Missiles: []MissileRecord{
+			{Name: "firebolt", ExplosionMissileName: "explodingfireball"},
+			{Name: "explodingfireball"},
+		},
+		resource.Skills: []SkillRecord{
+			{Name: "Fire Bolt", ServerMissileName: "firebolt", ClientMissileName: "firebolt"},
+			{Name: "Fire Ball", RequiredSkillNames: [numRequiredSkills]string{"Fire Bolt"}},
+		},
+		resource.ItemTypes: []ItemTypeRecord{
+			{Name: "Weapon", Code: "weap"},
+			{Name: "Sword", Code: "swor", EquivCodes: [numItemTypeEquivs]string{"weap"}},
+			{Name: "Helm", Code: "helm"},
+			{Name: "Healing Potion", Code: "hpot"},
+		},
+		resource.Weapons: []ItemRecord{
+			{Name: "Short Sword", Code: "ssd", TypeCode: "swor", NormalCode: "ssd", ExceptionalCode: "9ss"},
+			{Name: "Gladius", Code: "9ss", TypeCode: "swor", NormalCode: "ssd", ExceptionalCode: "9ss"},
+		},
+		resource.Armor: []ItemRecord{{Name: "Cap", Code: "cap", TypeCode: "helm"}},
+		resource.Misc:  []ItemRecord{{Name: "Minor Healing Potion", Code: "hp1", TypeCode: "hpot"}},
+		resource.UniqueItems: []UniqueItemRecord{
+			{Name: "Gull", Code: ""},
+			{Name: "The Gnasher", Code: "ssd", PropCodes: [numUniqueProperties]string{"dmg%", "", "swing2"},
+				PropMins: [numUniqueProperties]int{60, 0, 20}, PropMaxs: [numUniqueProperties]int{70, 0, 20}},
+		},
+		resource.SetItems:    []SetItemRecord{{Name: "Sigon's Visor", Code: "cap"}},
+		resource.MagicPrefix: []MagicAffixRecord{{Name: "Jagged", IncludeTypeCodes: [numAffixIncludeTypes]string{"weap"}}},
+		resource.MagicSuffix: []MagicAffixRecord{{Name: "of Life", IncludeTypeCodes: [numAffixIncludeTypes]string{"helm"},
+			ExcludeTypeCodes: [numAffixExcludeTypes]string{"swor"}}},
+		resource.ItemStatCost: []ItemStatCostRecord{
+			{Name: "strength", ID: 0},
+			{Name: "hitpoints", ID: 6, MaxStatName: "maxhp"},
+			{Name: "maxhp", ID: 7, OpStatNames: [numOpStats]string{"hitpoints"}},
+		},
+		resource.ObjectDetails: []ObjectRecord{{Name: "Door", ID: 0, IsDoor: true}},
+		resource.SoundSettings: []SoundRecord{{Name: "cursor_pickup", FileName: `cursor\pickup.wav`}},
+	}
+}
+
+func (tables testTables) loader(t *testing.T) testLoader {
+	loader := make(testLoader)
+
+	for path, records := range tables {
+		data, err := d2txt.Marshal(header(reflect.TypeOf(records).Elem()), records)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loader[path] = data
+	}
+
+	return loader
+}
+
+func TestTables(t *testing.T) {
+	tables := newTestTables()
+
+	for _, path := range Tables() {
+		if _, found := tables[path]; !found {
+			t.Errorf("no test table for %s", path)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	assert := testify.New(t)
+
+	r, err := Load(newTestTables().loader(t))
+	if !assert.NoError(err) {
+		return
+	}
+
+	town, wilderness := r.Levels[1], r.Levels[2]
+	assert.Same(r.LevelTypes[1], town.LevelType)
+	assert.Equal([]string{"/data/global/tiles/Act1/Town/Floor.dt1"}, town.LevelType.DT1Files)
+	assert.Nil(town.Warps[0])
+	assert.Same(r.LevelWarps[0], wilderness.Warps[0])
+	assert.Same(town, wilderness.Vis[0])
+	assert.Nil(wilderness.Vis[1])
+	assert.Equal([]*MonStatsRecord{r.MonStats["fallen1"]}, wilderness.Monsters)
+
+	assert.Same(town, r.LevelPresets[1].Level)
+	assert.Equal([]string{"/data/global/tiles/Act1/Town/townE1.ds1"}, r.LevelPresets[1].DS1Files)
+	assert.Nil(r.LevelPresets[2].Level)
+	assert.Same(wilderness, r.LevelMazes[2].Level)
+
+	fallen, shaman := r.MonStats["fallen1"], r.MonStats["fallen2"]
+	assert.Same(r.MonStats2["fallen1"], fallen.Stats2)
+	assert.Same(shaman, fallen.NextInClass)
+	assert.Same(fallen, shaman.Base)
+	assert.Equal([]*MonStatsRecord{fallen}, shaman.Minions)
+
+	assert.Same(r.Missiles["explodingfireball"], r.Missiles["firebolt"].ExplosionMissile)
+	assert.Same(r.Missiles["firebolt"], r.Skills["Fire Bolt"].ServerMissile)
+	assert.Equal([]*SkillRecord{r.Skills["Fire Bolt"]}, r.Skills["Fire Ball"].RequiredSkills)
+
+	sword := r.Weapons["ssd"]
+	assert.Equal(ItemKindWeapon, sword.Kind)
+	assert.Same(r.ItemTypes["swor"], sword.Type)
+	assert.Equal([]*ItemTypeRecord{r.ItemTypes["weap"]}, sword.Type.Equivs)
+	assert.Same(r.Weapons["9ss"], sword.Exceptional)
+	assert.Same(r.Armor["cap"], r.Items["cap"])
+	assert.Equal(ItemKindMisc, r.Misc["hp1"].Kind)
+
+	assert.Nil(r.UniqueItems[0].Item)
+	assert.Same(sword, r.UniqueItems[1].Item)
+	assert.Equal([]Property{{Code: "dmg%", Min: 60, Max: 70}, {Code: "swing2", Min: 20, Max: 20}},
+		r.UniqueItems[1].Properties)
+	assert.Same(r.Armor["cap"], r.SetItems[0].Item)
+	assert.Equal([]*ItemTypeRecord{r.ItemTypes["helm"]}, r.MagicSuffixes[0].IncludeTypes)
+	assert.Equal([]*ItemTypeRecord{r.ItemTypes["swor"]}, r.MagicSuffixes[0].ExcludeTypes)
+
+	assert.Same(r.ItemStatCosts["maxhp"], r.ItemStatCosts["hitpoints"].MaxStat)
+	assert.Equal([]*ItemStatCostRecord{r.ItemStatCosts["hitpoints"]}, r.ItemStatCosts["maxhp"].OpStats)
+
+	assert.True(r.Objects[0].IsDoor)
+	assert.Equal(`cursor\pickup.wav`, r.Sounds["cursor_pickup"].FileName)
+}
+
+func TestLoad_DanglingReferences(t *testing.T) {
+	tests := []struct {
+		table  string
+		modify func(tables testTables)
+		column string
+		value  string
+	}{
+		{resource.LevelDetails, func(tables testTables) {
+			tables[resource.LevelDetails].([]LevelRecord)[0].LevelTypeID = 9
+		}, "LevelType", "9"},
+		{resource.LevelDetails, func(tables testTables) {
+			tables[resource.LevelDetails].([]LevelRecord)[1].MonsterIDs[1] = "zombie1"
+		}, "mon2", "zombie1"},
+		{resource.LevelMaze, func(tables testTables) {
+			tables[resource.LevelMaze].([]LevelMazeRecord)[0].LevelID = 3
+		}, "Level", "3"},
+		{resource.MonStats, func(tables testTables) {
+			tables[resource.MonStats].([]MonStatsRecord)[1].MonStatsEx = "shaman"
+		}, "MonStatsEx", "shaman"},
+		{resource.Skills, func(tables testTables) {
+			tables[resource.Skills].([]SkillRecord)[1].ServerMissileName = "fireball"
+		}, "srvmissile", "fireball"},
+		{resource.Weapons, func(tables testTables) {
+			tables[resource.Weapons].([]ItemRecord)[1].EliteCode = "7ss"
+		}, "ultracode", "7ss"},
+		{resource.MagicPrefix, func(tables testTables) {
+			tables[resource.MagicPrefix].([]MagicAffixRecord)[0].IncludeTypeCodes[2] = "axe"
+		}, "itype3", "axe"},
+		{resource.ItemStatCost, func(tables testTables) {
+			tables[resource.ItemStatCost].([]ItemStatCostRecord)[0].OpBaseName = "level"
+		}, "op base", "level"},
+	}
+
+	for _, test := range tests {
+		tables := newTestTables()
+		test.modify(tables)
+
+		_, err := Load(tables.loader(t))
+
+		var refErr *ReferenceError
+		if !errors.As(err, &refErr) {
+			t.Errorf("%s %s: expected a reference error, got %v", test.table, test.column, err)
+			continue
+		}
+
+		testify.Equal(t, ReferenceError{Table: test.table, Record: refErr.Record, Column: test.column, Value: test.value},
+			*refErr)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	assert := testify.New(t)
+
+	tables := newTestTables()
+	tables[resource.Armor] = []ItemRecord{{Name: "Short Sword", Code: "ssd", TypeCode: "swor"}}
+
+	_, err := Load(tables.loader(t))
+	assert.EqualError(err, "armor.txt: duplicate code ssd")
+
+	loader := newTestTables().loader(t)
+	delete(loader, resource.SoundSettings)
+
+	_, err = Load(loader)
+	assert.Error(err)
+}
@@ -0,0 +1,190 @@
+package datarecords
+
+import (
+	"fmt"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+)
+
+const (
+	numSubMissiles    = 3
+	numHitSubMissiles = 4
+	numRequiredSkills = 3
+)
+
+// MissileRecord is a row of Missiles.txt
+type MissileRecord struct {
+	Name                 string                    `txt:"Missile"`
+	ID                   int                       `txt:"Id"`
+	Velocity             int                       `txt:"Vel"`
+	MaxVelocity          int                       `txt:"MaxVel"`
+	Range                int                       `txt:"Range"`
+	LevelRange           int                       `txt:"LevRange"`
+	Light                int                       `txt:"Light"`
+	CelFile              string                    `txt:"CelFile"`
+	AnimationLength      int                       `txt:"AnimLen"`
+	AnimationRate        int                       `txt:"animrate"`
+	SubMissileNames      [numSubMissiles]string    `txt:"SubMissile%d,base=1"`
+	HitSubMissileNames   [numHitSubMissiles]string `txt:"HitSubMissile%d,base=1"`
+	ExplosionMissileName string                    `txt:"ExplosionMissile"`
+
+	SubMissiles      [numSubMissiles]*MissileRecord
+	HitSubMissiles   [numHitSubMissiles]*MissileRecord
+	ExplosionMissile *MissileRecord
+}
+
+// SkillRecord is a row of Skills.txt
+type SkillRecord struct {
+	Name               string                    `txt:"skill"`
+	ID                 int                       `txt:"Id"`
+	CharClass          string                    `txt:"charclass"`
+	SkillDesc          string                    `txt:"skilldesc"`
+	ServerMissileName  string                    `txt:"srvmissile"`
+	ServerMissileAName string                    `txt:"srvmissilea"`
+	ServerMissileBName string                    `txt:"srvmissileb"`
+	ServerMissileCName string                    `txt:"srvmissilec"`
+	ClientMissileName  string                    `txt:"cltmissile"`
+	ClientMissileAName string                    `txt:"cltmissilea"`
+	ClientMissileBName string                    `txt:"cltmissileb"`
+	ClientMissileCName string                    `txt:"cltmissilec"`
+	ClientMissileDName string                    `txt:"cltmissiled"`
+	RequiredLevel      int                       `txt:"reqlevel"`
+	MaxLevel           int                       `txt:"maxlvl"`
+	RequiredSkillNames [numRequiredSkills]string `txt:"reqskill%d,base=1"`
+	Mana               int                       `txt:"mana"`
+	LevelMana          int                       `txt:"lvlmana"`
+	Passive            bool                      `txt:"passive"`
+	Aura               bool                      `txt:"aura"`
+
+	ServerMissile  *MissileRecord
+	ServerMissileA *MissileRecord
+	ServerMissileB *MissileRecord
+	ServerMissileC *MissileRecord
+	ClientMissile  *MissileRecord
+	ClientMissileA *MissileRecord
+	ClientMissileB *MissileRecord
+	ClientMissileC *MissileRecord
+	ClientMissileD *MissileRecord
+	RequiredSkills []*SkillRecord
+}
+
+func (r *Records) loadMissiles(loader resource.FileLoader) error {
+	var records []MissileRecord
+	if err := loadTable(loader, resource.Missiles, &records); err != nil {
+		return err
+	}
+
+	r.Missiles = make(map[string]*MissileRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.Missiles[record.Name]; found {
+			return duplicateError(resource.Missiles, "Missile", record.Name)
+		}
+
+		r.Missiles[record.Name] = record
+	}
+
+	return nil
+}
+
+func (r *Records) loadSkills(loader resource.FileLoader) error {
+	var records []SkillRecord
+	if err := loadTable(loader, resource.Skills, &records); err != nil {
+		return err
+	}
+
+	r.Skills = make(map[string]*SkillRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.Skills[record.Name]; found {
+			return duplicateError(resource.Skills, "skill", record.Name)
+		}
+
+		r.Skills[record.Name] = record
+	}
+
+	return nil
+}
+
+// missileOrNone finds a missile, an empty name refers to no missile
+func (r *Records) missileOrNone(name string) (*MissileRecord, bool) {
+	if name == "" {
+		return nil, true
+	}
+
+	missile, found := r.Missiles[name]
+
+	return missile, found
+}
+
+func (r *Records) resolveMissiles() error {
+	var found bool
+
+	for _, missile := range r.Missiles {
+		for idx, name := range missile.SubMissileNames {
+			if missile.SubMissiles[idx], found = r.missileOrNone(name); !found {
+				return referenceError(resource.Missiles, missile.Name, fmt.Sprintf("SubMissile%d", idx+1), name)
+			}
+		}
+
+		for idx, name := range missile.HitSubMissileNames {
+			if missile.HitSubMissiles[idx], found = r.missileOrNone(name); !found {
+				return referenceError(resource.Missiles, missile.Name, fmt.Sprintf("HitSubMissile%d", idx+1), name)
+			}
+		}
+
+		if missile.ExplosionMissile, found = r.missileOrNone(missile.ExplosionMissileName); !found {
+			return referenceError(resource.Missiles, missile.Name, "ExplosionMissile", missile.ExplosionMissileName)
+		}
+	}
+
+	return nil
+}
+
+func (r *Records) resolveSkills() error {
+	for _, skill := range r.Skills {
+		missiles := []struct {
+			column string
+			name   string
+			target **MissileRecord
+		}{
+			{"srvmissile", skill.ServerMissileName, &skill.ServerMissile},
+			{"srvmissilea", skill.ServerMissileAName, &skill.ServerMissileA},
+			{"srvmissileb", skill.ServerMissileBName, &skill.ServerMissileB},
+			{"srvmissilec", skill.ServerMissileCName, &skill.ServerMissileC},
+			{"cltmissile", skill.ClientMissileName, &skill.ClientMissile},
+			{"cltmissilea", skill.ClientMissileAName, &skill.ClientMissileA},
+			{"cltmissileb", skill.ClientMissileBName, &skill.ClientMissileB},
+			{"cltmissilec", skill.ClientMissileCName, &skill.ClientMissileC},
+			{"cltmissiled", skill.ClientMissileDName, &skill.ClientMissileD},
+		}
+
+		for _, m := range missiles {
+			missile, found := r.missileOrNone(m.name)
+			if !found {
+				return referenceError(resource.Skills, skill.Name, m.column, m.name)
+			}
+
+			*m.target = missile
+		}
+
+		skill.RequiredSkills = make([]*SkillRecord, 0)
+
+		for idx, name := range skill.RequiredSkillNames {
+			if name == "" {
+				continue
+			}
+
+			required, found := r.Skills[name]
+			if !found {
+				return referenceError(resource.Skills, skill.Name, fmt.Sprintf("reqskill%d", idx+1), name)
+			}
+
+			skill.RequiredSkills = append(skill.RequiredSkills, required)
+		}
+	}
+
+	return nil
+}
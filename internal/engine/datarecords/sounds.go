@@ -0,0 +1,54 @@
+package datarecords
+
+import (
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+)
+
+// SoundRecord is a row of Sounds.txt
+type SoundRecord struct {
+	Name      string `txt:"Sound"`
+	Index     int    `txt:"Index"`
+	FileName  string `txt:"FileName"`
+	Volume    int    `txt:"Volume"`
+	GroupSize int    `txt:"Group Size"`
+	Loop      bool   `txt:"Loop"`
+	FadeIn    int    `txt:"Fade In"`
+	FadeOut   int    `txt:"Fade Out"`
+	DeferInst bool   `txt:"Defer Inst"`
+	StopInst  bool   `txt:"Stop Inst"`
+	Duration  int    `txt:"Duration"`
+	Compound  int    `txt:"Compound"`
+	Reverb    bool   `txt:"Reverb"`
+	Falloff   int    `txt:"Falloff"`
+	Cache     bool   `txt:"Cache"`
+	AsyncOnly bool   `txt:"Async Only"`
+	Priority  int    `txt:"Priority"`
+	Stream    bool   `txt:"Stream"`
+	Stereo    bool   `txt:"Stereo"`
+	Tracking  bool   `txt:"Tracking"`
+	Solo      bool   `txt:"Solo"`
+	MusicVol  bool   `txt:"Music Vol"`
+	Block1    int    `txt:"Block 1"`
+	Block2    int    `txt:"Block 2"`
+	Block3    int    `txt:"Block 3"`
+}
+
+func (r *Records) loadSounds(loader resource.FileLoader) error {
+	var records []SoundRecord
+	if err := loadTable(loader, resource.SoundSettings, &records); err != nil {
+		return err
+	}
+
+	r.Sounds = make(map[string]*SoundRecord, len(records))
+
+	for idx := range records {
+		record := &records[idx]
+		if _, found := r.Sounds[record.Name]; found {
+			return duplicateError(resource.SoundSettings, "Sound", record.Name)
+		}
+
+		r.Sounds[record.Name] = record
+	}
+
+	return nil
+}
@@ -2,10 +2,18 @@ package engine
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/console"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/input"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/scenemanager"
 
 	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
@@ -19,54 +27,138 @@ import (
 // Engine represents an instance of the Abyss Engine.
 type Engine struct {
 	ecs          *akara.World
+	config       *configuration.Configuration
+	console      *console.Console
 	gfx          graphicsbackend.Interface
 	input        inputbackend.Interface
+	actions      *input.ActionMap
 	sceneManager *scenemanager.SceneManager
+	loop         *Loop
+	alpha        float64
+	quit         chan struct{}
+	quitOnce     sync.Once
+	shutdown     shutdownHooks
 }
 
-// New creates a new instance of the abyss engine
+// New creates a new instance of the abyss engine, the loader reads the game
+// files such as the loading screen
 func New(config *configuration.Configuration,
+	loader resource.FileLoader,
 	graphicsBackend graphicsbackend.Interface,
 	inputBackend inputbackend.Interface,
 	sceneManager *scenemanager.SceneManager,
 ) (*Engine, error) {
 	result := &Engine{
+		config:       config,
+		console:      console.New(),
 		gfx:          graphicsBackend,
 		input:        inputBackend,
+		actions:      input.New(),
 		sceneManager: sceneManager,
+		loop:         NewLoop(config.TicksPerSecond, config.FpsCap),
+		quit:         make(chan struct{}),
 	}
 
+	if err := result.actions.Load(config.KeyBindings); err != nil {
+		return nil, fmt.Errorf("loading key bindings: %w", err)
+	}
+
+	// the whole layout is saved, for players to edit
+	config.KeyBindings = result.actions.Export()
+
+	sceneManager.SetLoadingScreen(scenemanager.NewLoadingScreen(loader, graphicsBackend))
+
 	result.configureECS()
 
+	if err := result.registerConsoleCommands(); err != nil {
+		return nil, err
+	}
+
+	result.OnShutdown("console", ShutdownOrderGame, func(context.Context) error {
+		result.console.Close()
+		return nil
+	})
+	result.OnShutdown("scenes", ShutdownOrderGame, sceneManager.UnloadAll)
+
 	return result, nil
 }
 
-// Run runs the engine
-func (engine *Engine) Run() error {
-	lastUpdateTime := time.Now()
+// Run runs the engine, the ECS world is updated at the configured ticks per
+// second and the frames are rendered as often as the FPS cap allows. It
+// returns nil once the context is done or a quit was requested, the shutdown
+// hooks are left to Shutdown.
+func (engine *Engine) Run(ctx context.Context) error {
 	go func() {
 		engine.handleDebugger()
 	}()
 
 	for {
-		currentTime := time.Now()
-		timeDelta := currentTime.Sub(lastUpdateTime)
-		lastUpdateTime = currentTime
-
-		if err := engine.ecs.Update(timeDelta); err != nil {
-			return err
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-engine.quit:
+			return nil
+		default:
 		}
 
-		if err := engine.gfx.Render(); err != nil {
-			return err
-		}
+		if err := engine.loop.Frame(engine.update, engine.render); err != nil {
+			if errors.Is(err, inputbackend.ErrQuitRequested) {
+				return nil
+			}
 
-		if err := engine.input.Process(); err != nil {
 			return err
 		}
 	}
 }
 
+// Quit asks Run to stop after the current frame, it is safe to call from any
+// goroutine and more than once
+func (engine *Engine) Quit() {
+	engine.quitOnce.Do(func() {
+		close(engine.quit)
+	})
+}
+
+// Actions returns the actions bound to the input, they are updated at every
+// tick right after the input is processed
+func (engine *Engine) Actions() *input.ActionMap {
+	return engine.actions
+}
+
+// InterpolationAlpha returns how far the frame being rendered lies between the
+// last simulation tick and the next one, in [0, 1)
+func (engine *Engine) InterpolationAlpha() float64 {
+	return engine.alpha
+}
+
+// update runs a simulation tick, input is processed once per tick so that
+// replaying recorded input reproduces the same ticks
+func (engine *Engine) update(_ uint64, delta time.Duration) error {
+	if err := engine.input.Process(); err != nil {
+		return err
+	}
+
+	engine.actions.Update(engine.input)
+
+	engine.console.RunQueued()
+
+	if err := engine.sceneManager.Update(delta); err != nil {
+		return err
+	}
+
+	return engine.ecs.Update(delta)
+}
+
+func (engine *Engine) render(alpha float64) error {
+	engine.alpha = alpha
+
+	if err := engine.sceneManager.Render(); err != nil {
+		return err
+	}
+
+	return engine.gfx.Render()
+}
+
 func (engine *Engine) configureECS() {
 	cfg := akara.NewWorldConfig().
 		With(&configuration.Configuration{})
@@ -74,14 +166,36 @@ func (engine *Engine) configureECS() {
 	engine.ecs = akara.NewWorld(cfg)
 }
 
+// handleDebugger runs the lines read from stdin on the console. A line ending
+// with a tab lists its completions instead.
 func (engine *Engine) handleDebugger() {
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		res, err := reader.ReadString('\n')
+		line, err := reader.ReadString('\n')
 		if err != nil {
 			log.Printf("Couldn't read stdin")
+			return
 		}
 
-		log.Printf("Recieved: %q", res)
+		line = strings.TrimRight(line, "\r\n")
+
+		if strings.HasSuffix(line, "\t") {
+			for _, completion := range engine.console.Complete(strings.TrimSuffix(line, "\t")) {
+				fmt.Println(completion)
+			}
+
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		result := <-engine.console.Submit(line)
+		if result.Err != nil {
+			fmt.Println("error:", result.Err)
+		} else if result.Output != "" {
+			fmt.Println(result.Output)
+		}
 	}
 }
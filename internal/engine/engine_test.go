@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/scenemanager"
+	testify "github.com/stretchr/testify/assert"
+)
+
+type testScene struct {
+	loaded  chan struct{}
+	loadErr error
+}
+
+func (s *testScene) Load() error {
+	if s.loaded != nil {
+		<-s.loaded
+	}
+
+	return s.loadErr
+}
+
+func (s *testScene) Unload() error                    { return nil }
+func (s *testScene) Enter() error                     { return nil }
+func (s *testScene) Exit() error                      { return nil }
+func (s *testScene) Update(delta time.Duration) error { return nil }
+func (s *testScene) Render(opacity float64) error     { return nil }
+
+func TestEngine_LoadingScreen(t *testing.T) {
+	assert := testify.New(t)
+	e := newTestEngine(t, &testInput{})
+	scene := &testScene{loaded: make(chan struct{}), loadErr: errors.New("not loaded")}
+
+	defer close(scene.loaded)
+
+	e.sceneManager.Push(scene, scenemanager.Cut())
+
+	err := e.update(0, time.Millisecond)
+	assert.EqualError(err, "loading the loading screen: "+resource.PaletteLoading+" not found",
+		"the loading screen reads its files with the engine's loader")
+}
@@ -0,0 +1,333 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+	log "github.com/sirupsen/logrus"
+)
+
+// ConflictError is returned when binding an action to a binding that
+// another action already uses
+type ConflictError struct {
+	Binding Binding
+	Action  string
+	Other   string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s of %s is already bound to %s", e.Binding, e.Action, e.Other)
+}
+
+type heldBinding struct {
+	action  string
+	binding Binding
+}
+
+type actionState struct {
+	active        bool
+	justTriggered bool
+	justReleased  bool
+	since         uint64
+}
+
+// ActionMap binds named actions to inputs and tracks which actions are held.
+// Update must be called once per tick, after the input backend processed its
+// events.
+//
+// An action is held while all the inputs of one of its bindings are down,
+// unless the binding of another action holds the same inputs and more: when
+// Control+S is held, Control+S wins over S. Bindings that do not contain one
+// another are held together, so Shift+MouseLeft holds both stand_still and
+// skill_left, and so are the bindings of modifiers alone.
+type ActionMap struct {
+	bindings map[string][]Binding
+	states   map[string]*actionState
+	tick     uint64
+}
+
+// New creates an action map bound to the Diablo II layout, see
+// DefaultBindings
+func New() *ActionMap {
+	result := &ActionMap{
+		bindings: make(map[string][]Binding),
+		states:   make(map[string]*actionState),
+	}
+
+	if err := result.Load(DefaultBindings()); err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// Actions returns the names of the bound actions, sorted
+func (m *ActionMap) Actions() []string {
+	result := make([]string, 0, len(m.bindings))
+
+	for action := range m.bindings {
+		result = append(result, action)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Bindings returns the bindings of an action
+func (m *ActionMap) Bindings(action string) []Binding {
+	return append([]Binding(nil), m.bindings[action]...)
+}
+
+// Bind replaces the bindings of an action. Nothing changes when one of them
+// is already bound to another action, the error is then a *ConflictError.
+func (m *ActionMap) Bind(action string, bindings ...Binding) error {
+	normalized := make([]Binding, 0, len(bindings))
+
+	for _, binding := range bindings {
+		binding = binding.normalized()
+
+		if others := m.Conflicts(binding, action); len(others) > 0 {
+			return &ConflictError{Binding: binding, Action: action, Other: others[0]}
+		}
+
+		if !containsBinding(normalized, binding) {
+			normalized = append(normalized, binding)
+		}
+	}
+
+	m.bindings[action] = normalized
+
+	return nil
+}
+
+// Unbind removes the bindings of an action. The action stays in Export
+// without bindings, so that Load does not bring its defaults back.
+func (m *ActionMap) Unbind(action string) {
+	m.bindings[action] = []Binding{}
+}
+
+// Conflicts returns the actions other than except that are bound to the
+// binding, sorted
+func (m *ActionMap) Conflicts(binding Binding, except string) []string {
+	var result []string
+
+	for action, bindings := range m.bindings {
+		if action != except && containsBinding(bindings, binding) {
+			result = append(result, action)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Load binds the actions of a map of action names to bindings written as by
+// ParseBinding, the actions it does not name keep their bindings. A kept
+// binding that the map gives to another action is dropped with a warning, so
+// that a new default does not lock players out of their layout. Nothing
+// changes when a binding does not parse or two actions of the map share one.
+func (m *ActionMap) Load(bindings map[string][]string) error {
+	loaded := &ActionMap{
+		bindings: make(map[string][]Binding, len(m.bindings)),
+	}
+
+	for _, action := range sortedKeys(bindings) {
+		parsed := make([]Binding, len(bindings[action]))
+
+		for idx, text := range bindings[action] {
+			binding, err := ParseBinding(text)
+			if err != nil {
+				return fmt.Errorf("action %s: %w", action, err)
+			}
+
+			parsed[idx] = binding
+		}
+
+		if err := loaded.Bind(action, parsed...); err != nil {
+			return err
+		}
+	}
+
+	for _, action := range m.Actions() {
+		if _, found := bindings[action]; found {
+			continue
+		}
+
+		kept := make([]Binding, 0, len(m.bindings[action]))
+
+		for _, binding := range m.bindings[action] {
+			if others := loaded.Conflicts(binding, action); len(others) > 0 {
+				log.Warnf("%s is bound to %s, removing it from %s", binding, others[0], action)
+				continue
+			}
+
+			kept = append(kept, binding)
+		}
+
+		loaded.bindings[action] = kept
+	}
+
+	m.bindings = loaded.bindings
+
+	return nil
+}
+
+// Export returns the bindings of every action in the form Load reads
+func (m *ActionMap) Export() map[string][]string {
+	result := make(map[string][]string, len(m.bindings))
+
+	for action, bindings := range m.bindings {
+		texts := make([]string, len(bindings))
+
+		for idx, binding := range bindings {
+			texts[idx] = binding.String()
+		}
+
+		result[action] = texts
+	}
+
+	return result
+}
+
+// Update reads the state of the actions from the input
+func (m *ActionMap) Update(input inputbackend.Interface) {
+	m.tick++
+
+	var held []heldBinding
+
+	for action, bindings := range m.bindings {
+		for _, binding := range bindings {
+			if binding.held(input) {
+				held = append(held, heldBinding{action: action, binding: binding})
+			}
+		}
+	}
+
+	active := make(map[string]bool)
+	triggered := make(map[string]bool)
+
+	for _, candidate := range held {
+		if shadowed(candidate.binding, candidate.action, held) {
+			continue
+		}
+
+		active[candidate.action] = true
+
+		if candidate.binding.justPressed(input) {
+			triggered[candidate.action] = true
+		}
+	}
+
+	for action := range m.bindings {
+		m.updateState(action, active[action], triggered[action])
+	}
+}
+
+func (m *ActionMap) updateState(action string, active, triggered bool) {
+	state, found := m.states[action]
+	if !found {
+		state = &actionState{}
+		m.states[action] = state
+	}
+
+	state.justTriggered = active && triggered && !state.active
+	state.justReleased = !active && state.active
+
+	if active && !state.active {
+		state.since = m.tick
+	}
+
+	state.active = active
+}
+
+// shadowed tells whether a held binding of another action holds more inputs
+// than the binding. A binding of modifiers alone is held whatever else is
+// down, so that Control keeps running while Control+S is pressed.
+func shadowed(binding Binding, action string, held []heldBinding) bool {
+	if len(binding.Keys) == 0 && len(binding.Buttons) == 0 {
+		return false
+	}
+
+	for _, other := range held {
+		if other.action != action && other.binding.contains(binding) && !binding.contains(other.binding) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ActionPressed tells whether the action is held
+func (m *ActionMap) ActionPressed(action string) bool {
+	state, found := m.states[action]
+	return found && state.active
+}
+
+// ActionJustTriggered tells whether the action started being held during the
+// tick because one of its inputs went down. An action that is held because
+// the binding shadowing it was released is pressed but not triggered.
+func (m *ActionMap) ActionJustTriggered(action string) bool {
+	state, found := m.states[action]
+	return found && state.justTriggered
+}
+
+// ActionJustReleased tells whether the action stopped being held during the
+// tick
+func (m *ActionMap) ActionJustReleased(action string) bool {
+	state, found := m.states[action]
+	return found && state.justReleased
+}
+
+// ActionDuration returns for how many ticks the action has been held,
+// counting the current one
+func (m *ActionMap) ActionDuration(action string) int {
+	state, found := m.states[action]
+	if !found || !state.active {
+		return 0
+	}
+
+	return int(m.tick - state.since + 1)
+}
+
+// String lists the actions and their bindings, one action per line
+func (m *ActionMap) String() string {
+	lines := make([]string, 0, len(m.bindings))
+
+	for _, action := range m.Actions() {
+		texts := make([]string, len(m.bindings[action]))
+
+		for idx, binding := range m.bindings[action] {
+			texts[idx] = binding.String()
+		}
+
+		lines = append(lines, strings.TrimSpace(action+" "+strings.Join(texts, " ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func containsBinding(bindings []Binding, binding Binding) bool {
+	for _, b := range bindings {
+		if b.Equal(binding) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(bindings map[string][]string) []string {
+	result := make([]string, 0, len(bindings))
+
+	for action := range bindings {
+		result = append(result, action)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,146 @@
+package input
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend/scriptedinputbackend"
+	testify "github.com/stretchr/testify/assert"
+)
+
+const testTimeline = `
+0 keydown I
+2 keyup I
+3 keydown Shift
+3 mousedown Left
+4 keyup Shift
+4 mouseup Left
+5 keydown Control
+6 keydown S
+7 keyup Control
+8 keyup S
+9 keydown MouseWheelDown
+9 keyup MouseWheelDown
+`
+
+// step processes the next tick of the input and updates the actions
+func step(t *testing.T, input *scriptedinputbackend.ScriptedInputBackend, actions *ActionMap) {
+	if err := input.Process(); err != nil {
+		t.Fatal(err)
+	}
+
+	actions.Update(input)
+}
+
+func TestActionMap(t *testing.T) {
+	assert := testify.New(t)
+
+	events, err := scriptedinputbackend.ReadTimeline(strings.NewReader(testTimeline))
+	assert.NoError(err)
+
+	input, err := scriptedinputbackend.Create(events)
+	assert.NoError(err)
+
+	actions := New()
+	assert.NoError(actions.Bind("save", MustParseBinding("Control+S")))
+
+	step(t, input, actions) // tick 0
+	assert.True(actions.ActionJustTriggered(ActionToggleInventory))
+	assert.True(actions.ActionPressed(ActionToggleInventory))
+	assert.Equal(1, actions.ActionDuration(ActionToggleInventory))
+
+	step(t, input, actions) // tick 1
+	assert.False(actions.ActionJustTriggered(ActionToggleInventory))
+	assert.Equal(2, actions.ActionDuration(ActionToggleInventory))
+
+	step(t, input, actions) // tick 2
+	assert.True(actions.ActionJustReleased(ActionToggleInventory))
+	assert.False(actions.ActionPressed(ActionToggleInventory))
+	assert.Zero(actions.ActionDuration(ActionToggleInventory))
+
+	step(t, input, actions) // tick 3
+	assert.True(actions.ActionJustTriggered(ActionStandStill))
+	assert.True(actions.ActionJustTriggered(ActionSkillLeft), "bindings that don't contain one another are held together")
+
+	step(t, input, actions) // tick 4
+	assert.True(actions.ActionJustReleased(ActionStandStill))
+	assert.True(actions.ActionJustReleased(ActionSkillLeft))
+
+	step(t, input, actions) // tick 5
+	assert.True(actions.ActionJustTriggered(ActionRun))
+
+	step(t, input, actions) // tick 6
+	assert.True(actions.ActionJustTriggered("save"))
+	assert.False(actions.ActionPressed(ActionToggleSkillSpeed), "Control+S wins over S")
+	assert.True(actions.ActionPressed(ActionRun), "a binding of modifiers alone is never shadowed")
+
+	step(t, input, actions) // tick 7
+	assert.True(actions.ActionJustReleased("save"))
+	assert.True(actions.ActionPressed(ActionToggleSkillSpeed))
+	assert.False(actions.ActionJustTriggered(ActionToggleSkillSpeed), "releasing Control does not trigger S")
+
+	step(t, input, actions) // tick 8
+	assert.True(actions.ActionJustReleased(ActionToggleSkillSpeed))
+
+	step(t, input, actions) // tick 9
+	assert.True(actions.ActionJustTriggered(ActionNextSkill), "a wheel tap triggers")
+	assert.False(actions.ActionJustTriggered(ActionPreviousSkill))
+
+	step(t, input, actions) // tick 10
+	assert.True(actions.ActionJustReleased(ActionNextSkill))
+}
+
+func TestActionMap_Bind(t *testing.T) {
+	assert := testify.New(t)
+
+	actions := New()
+
+	err := actions.Bind(ActionToggleParty, MustParseBinding("O"), MustParseBinding("i"))
+
+	var conflict *ConflictError
+
+	assert.True(errors.As(err, &conflict))
+	assert.Equal(ActionToggleInventory, conflict.Other)
+	assert.EqualError(err, "I of toggle_party is already bound to toggle_inventory")
+	assert.Equal("P", actions.Bindings(ActionToggleParty)[0].String(), "nothing changes on conflict")
+
+	assert.Equal([]string{ActionToggleInventory}, actions.Conflicts(MustParseBinding("B"), ""))
+	assert.Empty(actions.Conflicts(MustParseBinding("B"), ActionToggleInventory))
+
+	assert.NoError(actions.Bind(ActionToggleInventory, MustParseBinding("B")))
+	assert.NoError(actions.Bind(ActionToggleParty, MustParseBinding("O"), MustParseBinding("I")))
+	assert.ElementsMatch([]string{"I", "O"}, actions.Export()[ActionToggleParty])
+
+	actions.Unbind(ActionGameMenu)
+	assert.Empty(actions.Bindings(ActionGameMenu))
+	assert.Contains(actions.Actions(), ActionGameMenu)
+	assert.Equal([]string{}, actions.Export()[ActionGameMenu], "an unbound action stays unbound once loaded")
+}
+
+func TestActionMap_Load(t *testing.T) {
+	assert := testify.New(t)
+
+	saved := New()
+	assert.NoError(saved.Bind(ActionToggleChat, MustParseBinding("Control+Enter")))
+	saved.Unbind(ActionGameMenu)
+
+	loaded := New()
+	assert.NoError(loaded.Load(saved.Export()))
+	assert.Equal(saved.Export(), loaded.Export())
+	assert.Equal(saved.String(), loaded.String())
+
+	partial := New()
+	assert.NoError(partial.Load(map[string][]string{ActionToggleChat: {"ctrl+enter"}}))
+	assert.Equal(saved.Export()[ActionToggleChat], partial.Export()[ActionToggleChat])
+	assert.Equal([]string{"Escape"}, partial.Export()[ActionGameMenu], "actions not named keep their bindings")
+
+	before := partial.String()
+	assert.Error(partial.Load(map[string][]string{ActionToggleChat: {"Enter"}, "whisper": {"Enter"}}))
+	assert.Error(partial.Load(map[string][]string{ActionToggleChat: {"NoSuchKey"}}))
+	assert.Equal(before, partial.String(), "nothing changes on error")
+
+	assert.NoError(partial.Load(map[string][]string{"save": {"I"}}), "a kept binding never fails the load")
+	assert.Equal([]string{"I"}, partial.Export()["save"])
+	assert.Equal([]string{"B"}, partial.Export()[ActionToggleInventory], "the kept binding the map reuses is dropped")
+}
@@ -0,0 +1,251 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+)
+
+const (
+	bindingSeparator  = "+"
+	mouseButtonPrefix = "Mouse"
+)
+
+// ErrEmptyBinding is returned when parsing a binding without any input
+var ErrEmptyBinding = errors.New("empty binding")
+
+// modifiers are the names of the modifiers, in the order String writes them.
+// The modifier keys always bind as modifiers, so that Control+S holds
+// whichever Control key is down.
+var modifiers = []struct {
+	mod   inputbackend.KeyMod
+	key   inputbackend.Key
+	names []string
+}{
+	{inputbackend.KeyModControl, inputbackend.KeyControl, []string{"Control", "Ctrl"}},
+	{inputbackend.KeyModAlt, inputbackend.KeyAlt, []string{"Alt"}},
+	{inputbackend.KeyModShift, inputbackend.KeyShift, []string{"Shift"}},
+}
+
+// Binding is a combination of modifiers, keys and mouse buttons that are all
+// held together. A binding of several keys or buttons is a chord.
+type Binding struct {
+	Mods    inputbackend.KeyMod
+	Keys    []inputbackend.Key
+	Buttons []inputbackend.MouseButton
+}
+
+// ParseBinding parses the names of the inputs of a binding joined by +, such
+// as "Control+S", "Shift+MouseLeft" or "G+H". Keys are named as by
+// inputbackend.ParseKey, mouse buttons as by inputbackend.ParseMouseButton
+// with a Mouse prefix, and the names ignore case.
+func ParseBinding(text string) (Binding, error) {
+	var result Binding
+
+	for _, name := range strings.Split(text, bindingSeparator) {
+		if name = strings.TrimSpace(name); name == "" {
+			return Binding{}, fmt.Errorf("binding %q: %w", text, ErrEmptyBinding)
+		}
+
+		if mod, found := parseModifier(name); found {
+			result.Mods |= mod
+			continue
+		}
+
+		if len(name) > len(mouseButtonPrefix) && strings.EqualFold(name[:len(mouseButtonPrefix)], mouseButtonPrefix) {
+			if button, err := inputbackend.ParseMouseButton(name[len(mouseButtonPrefix):]); err == nil {
+				result.Buttons = append(result.Buttons, button)
+				continue
+			}
+		}
+
+		key, err := inputbackend.ParseKey(name)
+		if err != nil {
+			return Binding{}, fmt.Errorf("binding %q: %w", text, err)
+		}
+
+		result.Keys = append(result.Keys, key)
+	}
+
+	return result.normalized(), nil
+}
+
+// MustParseBinding is like ParseBinding but panics on error, for bindings
+// written in code
+func MustParseBinding(text string) Binding {
+	result, err := ParseBinding(text)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+func parseModifier(name string) (inputbackend.KeyMod, bool) {
+	for _, modifier := range modifiers {
+		for _, modifierName := range modifier.names {
+			if strings.EqualFold(modifierName, name) {
+				return modifier.mod, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func modifierOf(key inputbackend.Key) (inputbackend.KeyMod, bool) {
+	for _, modifier := range modifiers {
+		if modifier.key == key {
+			return modifier.mod, true
+		}
+	}
+
+	return 0, false
+}
+
+// String returns the binding in the form ParseBinding reads, modifiers first
+func (b Binding) String() string {
+	names := make([]string, 0, len(modifiers)+len(b.Keys)+len(b.Buttons))
+
+	for _, modifier := range modifiers {
+		if b.Mods&modifier.mod != 0 {
+			names = append(names, modifier.names[0])
+		}
+	}
+
+	for _, key := range b.Keys {
+		names = append(names, key.String())
+	}
+
+	for _, button := range b.Buttons {
+		names = append(names, mouseButtonPrefix+button.String())
+	}
+
+	return strings.Join(names, bindingSeparator)
+}
+
+// Equal tells whether both bindings hold the same inputs
+func (b Binding) Equal(other Binding) bool {
+	return b.String() == other.String()
+}
+
+// contains tells whether holding the inputs of b holds those of other
+func (b Binding) contains(other Binding) bool {
+	if b.Mods&other.Mods != other.Mods {
+		return false
+	}
+
+	for _, key := range other.Keys {
+		if !containsKey(b.Keys, key) {
+			return false
+		}
+	}
+
+	for _, button := range other.Buttons {
+		if !containsButton(b.Buttons, button) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// held tells whether every input of the binding is down. A key pressed and
+// released within the tick counts, the mouse wheel is never held otherwise.
+func (b Binding) held(input inputbackend.Interface) bool {
+	if input.KeyMods()&b.Mods != b.Mods {
+		return false
+	}
+
+	for _, key := range b.Keys {
+		if !input.IsKeyPressed(key) && !input.IsKeyJustPressed(key) {
+			return false
+		}
+	}
+
+	for _, button := range b.Buttons {
+		if !input.IsMouseButtonPressed(button) && !input.IsMouseButtonJustPressed(button) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// justPressed tells whether one of the inputs of the binding went down
+// during the tick
+func (b Binding) justPressed(input inputbackend.Interface) bool {
+	for _, modifier := range modifiers {
+		if b.Mods&modifier.mod != 0 && input.IsKeyJustPressed(modifier.key) {
+			return true
+		}
+	}
+
+	for _, key := range b.Keys {
+		if input.IsKeyJustPressed(key) {
+			return true
+		}
+	}
+
+	for _, button := range b.Buttons {
+		if input.IsMouseButtonJustPressed(button) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b Binding) normalized() Binding {
+	result := Binding{Mods: b.Mods}
+
+	for _, key := range b.Keys {
+		if mod, found := modifierOf(key); found {
+			result.Mods |= mod
+			continue
+		}
+
+		if !containsKey(result.Keys, key) {
+			result.Keys = append(result.Keys, key)
+		}
+	}
+
+	for _, button := range b.Buttons {
+		if !containsButton(result.Buttons, button) {
+			result.Buttons = append(result.Buttons, button)
+		}
+	}
+
+	sort.Slice(result.Keys, func(i, j int) bool {
+		return result.Keys[i] < result.Keys[j]
+	})
+
+	sort.Slice(result.Buttons, func(i, j int) bool {
+		return result.Buttons[i] < result.Buttons[j]
+	})
+
+	return result
+}
+
+func containsKey(keys []inputbackend.Key, key inputbackend.Key) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsButton(buttons []inputbackend.MouseButton, button inputbackend.MouseButton) bool {
+	for _, b := range buttons {
+		if b == button {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,55 @@
+package input
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestParseBinding(t *testing.T) {
+	assert := testify.New(t)
+
+	binding, err := ParseBinding("shift + ctrl+s")
+	assert.NoError(err)
+	assert.Equal(inputbackend.KeyModControl|inputbackend.KeyModShift, binding.Mods)
+	assert.Equal([]inputbackend.Key{inputbackend.KeyS}, binding.Keys)
+	assert.Equal("Control+Shift+S", binding.String())
+
+	binding, err = ParseBinding("MouseRight+H+G+G")
+	assert.NoError(err)
+	assert.Equal("G+H+MouseRight", binding.String())
+	assert.True(binding.Equal(MustParseBinding("g+mouseright+h")))
+
+	binding, err = ParseBinding("MouseWheelUp")
+	assert.NoError(err)
+	assert.Equal([]inputbackend.Key{inputbackend.KeyMouseWheelUp}, binding.Keys)
+	assert.Empty(binding.Buttons)
+
+	binding, err = ParseBinding("Alt")
+	assert.NoError(err)
+	assert.Equal(inputbackend.KeyModAlt, binding.Mods)
+	assert.Equal("Alt", binding.String())
+
+	assert.Equal("Shift+A", Binding{Keys: []inputbackend.Key{inputbackend.KeyShift, inputbackend.KeyA}}.normalized().String(),
+		"the modifier keys are modifiers")
+
+	_, err = ParseBinding("Control+")
+	assert.True(errors.Is(err, ErrEmptyBinding))
+
+	_, err = ParseBinding("Control+NoSuchKey")
+	assert.EqualError(err, `binding "Control+NoSuchKey": unknown key "NoSuchKey"`)
+}
+
+func TestDefaultBindings(t *testing.T) {
+	assert := testify.New(t)
+
+	for action, bindings := range DefaultBindings() {
+		for _, text := range bindings {
+			binding, err := ParseBinding(text)
+			assert.NoError(err, action)
+			assert.Equal(text, binding.String(), "%s is written the way it is exported", action)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package input
+
+// Actions of the default layout
+const (
+	ActionSkillLeft        = "skill_left"
+	ActionSkillRight       = "skill_right"
+	ActionStandStill       = "stand_still"
+	ActionRun              = "run"
+	ActionToggleRunWalk    = "toggle_run_walk"
+	ActionShowItems        = "show_items"
+	ActionShowPortraits    = "show_portraits"
+	ActionSwapWeapons      = "swap_weapons"
+	ActionClearScreen      = "clear_screen"
+	ActionClearMessages    = "clear_messages"
+	ActionGameMenu         = "game_menu"
+	ActionToggleCharacter  = "toggle_character"
+	ActionToggleInventory  = "toggle_inventory"
+	ActionToggleParty      = "toggle_party"
+	ActionToggleMessageLog = "toggle_message_log"
+	ActionToggleQuests     = "toggle_quests"
+	ActionToggleChat       = "toggle_chat"
+	ActionToggleSkillTree  = "toggle_skill_tree"
+	ActionToggleSkillSpeed = "toggle_skill_speed_bar"
+	ActionToggleHelp       = "toggle_help"
+	ActionToggleAutomap    = "toggle_automap"
+	ActionToggleBelt       = "toggle_belt"
+	ActionCenterAutomap    = "center_automap"
+	ActionFadeAutomap      = "fade_automap"
+	ActionAutomapParty     = "automap_party"
+	ActionAutomapNames     = "automap_names"
+	ActionSkill1           = "skill_1"
+	ActionSkill2           = "skill_2"
+	ActionSkill3           = "skill_3"
+	ActionSkill4           = "skill_4"
+	ActionSkill5           = "skill_5"
+	ActionSkill6           = "skill_6"
+	ActionSkill7           = "skill_7"
+	ActionSkill8           = "skill_8"
+	ActionPreviousSkill    = "previous_skill"
+	ActionNextSkill        = "next_skill"
+	ActionUseBelt1         = "use_belt_1"
+	ActionUseBelt2         = "use_belt_2"
+	ActionUseBelt3         = "use_belt_3"
+	ActionUseBelt4         = "use_belt_4"
+	ActionScreenshot       = "screenshot"
+)
+
+// DefaultBindings returns the key layout of Diablo II
+func DefaultBindings() map[string][]string {
+	return map[string][]string{
+		ActionSkillLeft:        {"MouseLeft"},
+		ActionSkillRight:       {"MouseRight"},
+		ActionStandStill:       {"Shift"},
+		ActionRun:              {"Control"},
+		ActionToggleRunWalk:    {"R"},
+		ActionShowItems:        {"Alt"},
+		ActionShowPortraits:    {"Z"},
+		ActionSwapWeapons:      {"W"},
+		ActionClearScreen:      {"Space"},
+		ActionClearMessages:    {"N"},
+		ActionGameMenu:         {"Escape"},
+		ActionToggleCharacter:  {"C", "A"},
+		ActionToggleInventory:  {"I", "B"},
+		ActionToggleParty:      {"P"},
+		ActionToggleMessageLog: {"M"},
+		ActionToggleQuests:     {"Q"},
+		ActionToggleChat:       {"Enter"},
+		ActionToggleSkillTree:  {"T"},
+		ActionToggleSkillSpeed: {"S"},
+		ActionToggleHelp:       {"H"},
+		ActionToggleAutomap:    {"Tab"},
+		ActionToggleBelt:       {"GraveAccent"},
+		ActionCenterAutomap:    {"F9"},
+		ActionFadeAutomap:      {"F10"},
+		ActionAutomapParty:     {"F11"},
+		ActionAutomapNames:     {"F12"},
+		ActionSkill1:           {"F1"},
+		ActionSkill2:           {"F2"},
+		ActionSkill3:           {"F3"},
+		ActionSkill4:           {"F4"},
+		ActionSkill5:           {"F5"},
+		ActionSkill6:           {"F6"},
+		ActionSkill7:           {"F7"},
+		ActionSkill8:           {"F8"},
+		ActionPreviousSkill:    {"MouseWheelUp"},
+		ActionNextSkill:        {"MouseWheelDown"},
+		ActionUseBelt1:         {"1"},
+		ActionUseBelt2:         {"2"},
+		ActionUseBelt3:         {"3"},
+		ActionUseBelt4:         {"4"},
+		ActionScreenshot:       {"PrintScreen"},
+	}
+}
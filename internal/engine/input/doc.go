@@ -0,0 +1,4 @@
+// Package input maps the raw keys and mouse buttons of an input backend to
+// named actions, so that game code asks whether "toggle_inventory" was
+// triggered rather than whether I was pressed, and players can rebind them
+package input
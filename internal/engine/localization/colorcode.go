@@ -0,0 +1,140 @@
+package localization
+
+import (
+	"image/color"
+	"strings"
+)
+
+// Color is a color code of the game strings, the character that follows the
+// ÿc marker
+type Color byte
+
+// Color codes
+const (
+	ColorDefault   Color = 0   // no color code, the label color
+	ColorWhite     Color = '0' // normal items
+	ColorRed       Color = '1'
+	ColorGreen     Color = '2' // set items
+	ColorBlue      Color = '3' // magic items
+	ColorGold      Color = '4' // unique items
+	ColorGrey      Color = '5' // socketed and ethereal items
+	ColorBlack     Color = '6'
+	ColorTan       Color = '7'
+	ColorOrange    Color = '8' // crafted items
+	ColorYellow    Color = '9' // rare items
+	ColorDarkGreen Color = ':'
+	ColorPurple    Color = ';'
+)
+
+// colorMarkers start a color code, the game files store ÿ as a single
+// latin-1 byte, strings written in Go hold it as UTF-8
+func colorMarkers() []string {
+	return []string{"\xffc", "ÿc"}
+}
+
+func getColors() map[Color]color.RGBA {
+	const opaque = 255
+
+	return map[Color]color.RGBA{
+		ColorWhite:     {R: 255, G: 255, B: 255, A: opaque},
+		ColorRed:       {R: 255, G: 77, B: 77, A: opaque},
+		ColorGreen:     {R: 0, G: 255, B: 0, A: opaque},
+		ColorBlue:      {R: 105, G: 105, B: 255, A: opaque},
+		ColorGold:      {R: 199, G: 179, B: 119, A: opaque},
+		ColorGrey:      {R: 105, G: 105, B: 105, A: opaque},
+		ColorBlack:     {R: 0, G: 0, B: 0, A: opaque},
+		ColorTan:       {R: 208, G: 194, B: 125, A: opaque},
+		ColorOrange:    {R: 255, G: 168, B: 0, A: opaque},
+		ColorYellow:    {R: 255, G: 255, B: 100, A: opaque},
+		ColorDarkGreen: {R: 0, G: 128, B: 0, A: opaque},
+		ColorPurple:    {R: 174, G: 0, B: 255, A: opaque},
+	}
+}
+
+// RGBA returns the color of the code, ColorDefault is white
+func (c Color) RGBA() color.RGBA {
+	if rgba, found := getColors()[c]; found {
+		return rgba
+	}
+
+	return getColors()[ColorWhite]
+}
+
+func (c Color) known() bool {
+	return c >= ColorWhite && c <= ColorPurple
+}
+
+// TextRun is a piece of text drawn in a single color
+type TextRun struct {
+	Color Color
+	Text  string
+}
+
+// ParseColorCodes splits a game string at its color codes. The text before the
+// first code has ColorDefault, unknown codes are removed and keep the color.
+// Empty runs are dropped and consecutive runs of the same color are joined.
+func ParseColorCodes(text string) []TextRun {
+	runs := make([]TextRun, 0)
+	current := ColorDefault
+
+	add := func(s string) {
+		if s == "" {
+			return
+		}
+
+		if last := len(runs) - 1; last >= 0 && runs[last].Color == current {
+			runs[last].Text += s
+			return
+		}
+
+		runs = append(runs, TextRun{Color: current, Text: s})
+	}
+
+	for {
+		start, marker := nextColorMarker(text)
+		if start < 0 {
+			add(text)
+			break
+		}
+
+		// a marker at the very end has no code, it is dropped
+		if start+len(marker) >= len(text) {
+			add(text[:start])
+			break
+		}
+
+		add(text[:start])
+
+		code := Color(text[start+len(marker)])
+		if code.known() {
+			current = code
+		}
+
+		text = text[start+len(marker)+1:]
+	}
+
+	return runs
+}
+
+// StripColorCodes removes the color codes from a game string
+func StripColorCodes(text string) string {
+	var sb strings.Builder
+
+	for _, run := range ParseColorCodes(text) {
+		sb.WriteString(run.Text)
+	}
+
+	return sb.String()
+}
+
+func nextColorMarker(text string) (start int, marker string) {
+	start = -1
+
+	for _, m := range colorMarkers() {
+		if idx := strings.Index(text, m); idx >= 0 && (start < 0 || idx < start) {
+			start, marker = idx, m
+		}
+	}
+
+	return start, marker
+}
@@ -0,0 +1,3 @@
+// Package localization chooses the game language and looks up the localized
+// strings of the layered string tables
+package localization
@@ -0,0 +1,94 @@
+package localization
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	specifierPrefix = '%'
+	signFlag        = '+'
+)
+
+// Format replaces the format specifiers of a game string with the arguments.
+// The game strings use %d, %i, %u and %s for the next argument, %0 to %9 for
+// an argument by position and %% for a percent sign. A + flag, as in %+d,
+// prints the sign of positive numbers. Specifiers without an argument are
+// kept as they are.
+func Format(format string, args ...interface{}) string {
+	var sb strings.Builder
+
+	next := 0
+
+	for idx := 0; idx < len(format); idx++ {
+		if format[idx] != specifierPrefix || idx+1 == len(format) {
+			sb.WriteByte(format[idx])
+			continue
+		}
+
+		end := idx + 1
+
+		if format[end] == specifierPrefix {
+			sb.WriteByte(specifierPrefix)
+
+			idx = end
+
+			continue
+		}
+
+		signed := format[end] == signFlag
+		if signed && end+1 < len(format) {
+			end++
+		}
+
+		arg, found := formatArg(format[end], args, &next)
+		if !found {
+			sb.WriteByte(format[idx])
+			continue
+		}
+
+		sb.WriteString(formatValue(arg, signed))
+
+		idx = end
+	}
+
+	return sb.String()
+}
+
+// formatArg returns the argument of the specifier with the given verb
+func formatArg(verb byte, args []interface{}, next *int) (interface{}, bool) {
+	switch verb {
+	case 'd', 'i', 'u', 's':
+		if *next >= len(args) {
+			return nil, false
+		}
+
+		*next++
+
+		return args[*next-1], true
+	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		position := int(verb - '0')
+		if position >= len(args) {
+			return nil, false
+		}
+
+		return args[position], true
+	}
+
+	return nil, false
+}
+
+func formatValue(arg interface{}, signed bool) string {
+	if !signed {
+		return fmt.Sprint(arg)
+	}
+
+	switch arg.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%+d", arg)
+	case float32, float64:
+		return fmt.Sprintf("%+g", arg)
+	}
+
+	return fmt.Sprint(arg)
+}
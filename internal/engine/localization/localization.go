@@ -0,0 +1,136 @@
+package localization
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+	d2tbl "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/tblfile"
+)
+
+// FallbackLanguage is used when the language of the game can not be read or
+// has no string tables
+const FallbackLanguage = "ENG"
+
+// tableLoadOrder lists the string tables from the lowest to the highest
+// priority, the game looks strings up in the patch table first
+func tableLoadOrder() []string {
+	return []string{
+		resource.StringTable,
+		resource.ExpansionStringTable,
+		resource.PatchStringTable,
+	}
+}
+
+// Localization looks up the localized strings of a language
+type Localization struct {
+	language string
+	strings  d2tbl.TextDictionary
+}
+
+// New reads the language of the game from resource.LocalLanguage and loads
+// its string tables
+func New(loader resource.FileLoader) (*Localization, error) {
+	language := FallbackLanguage
+
+	data, err := loader.ReadFile(resource.LocalLanguage)
+
+	switch {
+	case err != nil:
+		log.Warnf("couldn't read the game language, using %s: %v", language, err)
+	case len(data) == 0 || resource.GetLanguageLiteral(data[0]) == "":
+		log.Warnf("unknown game language, using %s", language)
+	default:
+		language = resource.GetLanguageLiteral(data[0])
+	}
+
+	return Load(loader, language)
+}
+
+// Load loads the string tables of the given language. The tables that are
+// missing are skipped, when a language has no tables at all the tables of the
+// FallbackLanguage are loaded instead.
+func Load(loader resource.FileLoader, language string) (*Localization, error) {
+	table, err := loadTables(loader, language)
+	if err != nil && language != FallbackLanguage {
+		log.Warnf("%v, falling back to %s", err, FallbackLanguage)
+
+		language = FallbackLanguage
+		table, err = loadTables(loader, language)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Localization{language: language, strings: table}, nil
+}
+
+func loadTables(loader resource.FileLoader, language string) (d2tbl.TextDictionary, error) {
+	tables := make([]d2tbl.TextDictionary, 0)
+
+	for _, path := range tableLoadOrder() {
+		path = strings.ReplaceAll(path, resource.LanguageTableToken, language)
+
+		data, err := loader.ReadFile(path)
+		if err != nil {
+			// the classic game has no expansion tables
+			continue
+		}
+
+		table, err := d2tbl.LoadTextDictionary(data)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load string table %s: %v", path, err)
+		}
+
+		tables = append(tables, table)
+	}
+
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no string tables found for language %s", language)
+	}
+
+	return d2tbl.Merge(tables...), nil
+}
+
+// Language returns the code of the loaded language, such as ENG
+func (l *Localization) Language() string {
+	return l.language
+}
+
+// Charset returns the font charset of the loaded language, such as LATIN
+func (l *Localization) Charset() string {
+	return resource.GetFontCharset(l.language)
+}
+
+// Lookup returns the unformatted string for the key
+func (l *Localization) Lookup(key string) (string, bool) {
+	value, found := l.strings[key]
+
+	return value, found
+}
+
+// Translate returns the string for the key with the format specifiers
+// replaced by the arguments, see Format. Unknown keys are returned as they are.
+func (l *Localization) Translate(key string, args ...interface{}) string {
+	value, found := l.strings[key]
+	if !found {
+		return key
+	}
+
+	return Format(value, args...)
+}
+
+// TranslateLabel translates an unnamed string by its label number, the
+// numbers are shifted by the label modifier of the language
+func (l *Localization) TranslateLabel(label int, args ...interface{}) string {
+	return l.Translate("#"+strconv.Itoa(label+resource.GetLabelModifier(l.language)), args...)
+}
+
+// TranslateRuns translates the key and splits the result at its color codes
+func (l *Localization) TranslateRuns(key string, args ...interface{}) []TextRun {
+	return ParseColorCodes(l.Translate(key, args...))
+}
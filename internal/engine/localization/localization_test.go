@@ -0,0 +1,120 @@
+package localization
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+	d2tbl "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/tblfile"
+)
+
+type testLoader map[string][]byte
+
+func (l testLoader) ReadFile(path string) ([]byte, error) {
+	data, found := l[path]
+	if !found {
+		return nil, fmt.Errorf("%s not found", path)
+	}
+
+	return data, nil
+}
+
+func (l testLoader) addTable(t *testing.T, path, language string, td d2tbl.TextDictionary) {
+	data, err := td.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l[strings.ReplaceAll(path, resource.LanguageTableToken, language)] = data
+}
+
+func TestNew_Layers(t *testing.T) {
+	assert := testify.New(t)
+	loader := testLoader{resource.LocalLanguage: {0x0A}}
+
+	loader.addTable(t, resource.StringTable, "POL", d2tbl.TextDictionary{"a": "string", "b": "string", "#5": "label"})
+	loader.addTable(t, resource.ExpansionStringTable, "POL", d2tbl.TextDictionary{"b": "expansion", "c": "expansion"})
+	loader.addTable(t, resource.PatchStringTable, "POL", d2tbl.TextDictionary{"c": "patch"})
+
+	l, err := New(loader)
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal("POL", l.Language())
+	assert.Equal("LATIN2", l.Charset())
+	assert.Equal("string", l.Translate("a"))
+	assert.Equal("expansion", l.Translate("b"))
+	assert.Equal("patch", l.Translate("c"))
+	assert.Equal("missing", l.Translate("missing"), "unknown keys are returned as they are")
+	assert.Equal("label", l.TranslateLabel(4), "polish labels are shifted by one")
+}
+
+func TestNew_Fallback(t *testing.T) {
+	assert := testify.New(t)
+	loader := testLoader{resource.LocalLanguage: {0x02}}
+
+	loader.addTable(t, resource.StringTable, FallbackLanguage, d2tbl.TextDictionary{"a": "english"})
+
+	l, err := New(loader)
+	if assert.NoError(err) {
+		assert.Equal(FallbackLanguage, l.Language(), "DEU has no tables")
+		assert.Equal("english", l.Translate("a"))
+	}
+
+	delete(loader, resource.LocalLanguage)
+
+	l, err = New(loader)
+	if assert.NoError(err) {
+		assert.Equal(FallbackLanguage, l.Language())
+	}
+
+	_, err = New(testLoader{})
+	assert.Error(err)
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		args     []interface{}
+		expected string
+	}{
+		{"+%d to Strength", []interface{}{5}, "+5 to Strength"},
+		{"%+d%% Enhanced Damage", []interface{}{120}, "+120% Enhanced Damage"},
+		{"%+d to Light Radius", []interface{}{-2}, "-2 to Light Radius"},
+		{"Adds %d-%d damage", []interface{}{1, 3}, "Adds 1-3 damage"},
+		{"%d%% Chance to cast level %d %s on attack", []interface{}{5, 7, "Frost Nova"},
+			"5% Chance to cast level 7 Frost Nova on attack"},
+		{"%1 %0", []interface{}{"Sword", "Blessed"}, "Blessed Sword"},
+		{"%d and %d", []interface{}{1}, "1 and %d"},
+		{"100%", nil, "100%"},
+		{"50% chance", nil, "50% chance"},
+		{"%+", []interface{}{1}, "%+"},
+	}
+
+	for _, test := range tests {
+		testify.Equal(t, test.expected, Format(test.format, test.args...), test.format)
+	}
+}
+
+func TestParseColorCodes(t *testing.T) {
+	assert := testify.New(t)
+
+	runs := ParseColorCodes("Ring\xffc4Stone of Jordan\xffc4 \xffc3+1ÿcZ to skills\xffc")
+	assert.Equal([]TextRun{
+		{ColorDefault, "Ring"},
+		{ColorGold, "Stone of Jordan "},
+		{ColorBlue, "+1 to skills"},
+	}, runs, "the trailing marker has no code and is dropped")
+
+	assert.Equal("Grey", StripColorCodes("ÿc5Greyÿc"))
+	assert.Empty(ParseColorCodes("\xffc"))
+
+	assert.Empty(ParseColorCodes(""))
+	assert.Equal("Grey", StripColorCodes("ÿc5Grey"))
+	assert.Equal(uint8(199), ColorGold.RGBA().R)
+	assert.Equal(ColorWhite.RGBA(), ColorDefault.RGBA())
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"time"
+)
+
+const (
+	// DefaultTicksPerSecond is the simulation rate when the configuration
+	// doesn't set one, the rate of the original game
+	DefaultTicksPerSecond = 25
+
+	// maxFrameTime caps the time simulated after a slow frame, so that a
+	// frame which takes longer than its ticks can't make every following
+	// frame run even more ticks
+	maxFrameTime = 250 * time.Millisecond
+)
+
+// TickFunc advances the simulation by one tick of fixed duration, ticks are
+// numbered from 0
+type TickFunc func(tick uint64, delta time.Duration) error
+
+// RenderFunc draws a frame, alpha is how far the frame lies between the
+// last tick and the next one, in [0, 1)
+type RenderFunc func(alpha float64) error
+
+// Loop runs the simulation at a fixed rate and renders in between. The
+// simulation only ever advances in whole ticks of the same duration, so
+// that it doesn't depend on the frame rate.
+type Loop struct {
+	tickDuration  time.Duration
+	frameDuration time.Duration
+	accumulator   time.Duration
+	tick          uint64
+	lastFrame     time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewLoop creates a loop with the given simulation rate, DefaultTicksPerSecond
+// if it is not positive. A positive fpsCap limits the frame rate.
+func NewLoop(ticksPerSecond, fpsCap int) *Loop {
+	if ticksPerSecond <= 0 {
+		ticksPerSecond = DefaultTicksPerSecond
+	}
+
+	result := &Loop{
+		tickDuration: time.Second / time.Duration(ticksPerSecond),
+		now:          time.Now,
+		sleep:        time.Sleep,
+	}
+
+	if fpsCap > 0 {
+		result.frameDuration = time.Second / time.Duration(fpsCap)
+	}
+
+	return result
+}
+
+// TickDuration returns the simulated time of a tick
+func (l *Loop) TickDuration() time.Duration {
+	return l.tickDuration
+}
+
+// Tick returns the number of ticks run so far
+func (l *Loop) Tick() uint64 {
+	return l.tick
+}
+
+// Frame runs the ticks that are due since the last frame, renders once and
+// waits for the frame cap. The first frame doesn't run any tick.
+func (l *Loop) Frame(update TickFunc, render RenderFunc) error {
+	frameStart := l.now()
+
+	if !l.lastFrame.IsZero() {
+		elapsed := frameStart.Sub(l.lastFrame)
+		if elapsed > maxFrameTime {
+			elapsed = maxFrameTime
+		}
+
+		l.accumulator += elapsed
+	}
+
+	l.lastFrame = frameStart
+
+	for l.accumulator >= l.tickDuration {
+		if err := update(l.tick, l.tickDuration); err != nil {
+			return err
+		}
+
+		l.tick++
+		l.accumulator -= l.tickDuration
+	}
+
+	if err := render(float64(l.accumulator) / float64(l.tickDuration)); err != nil {
+		return err
+	}
+
+	if l.frameDuration > 0 {
+		if wait := l.frameDuration - l.now().Sub(frameStart); wait > 0 {
+			l.sleep(wait)
+		}
+	}
+
+	return nil
+}
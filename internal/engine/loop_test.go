@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) install(l *Loop) {
+	c.now = time.Unix(0, 0)
+	l.now = func() time.Time { return c.now }
+	l.sleep = func(d time.Duration) {
+		c.slept = append(c.slept, d)
+		c.now = c.now.Add(d)
+	}
+}
+
+type frameLog struct {
+	ticks  []uint64
+	deltas []time.Duration
+	alphas []float64
+}
+
+func (f *frameLog) update(tick uint64, delta time.Duration) error {
+	f.ticks = append(f.ticks, tick)
+	f.deltas = append(f.deltas, delta)
+
+	return nil
+}
+
+func (f *frameLog) render(alpha float64) error {
+	f.alphas = append(f.alphas, alpha)
+	return nil
+}
+
+func TestLoop_FixedTicks(t *testing.T) {
+	assert := testify.New(t)
+	loop := NewLoop(25, 0)
+	clock := &fakeClock{}
+	clock.install(loop)
+
+	frames := &frameLog{}
+
+	// frames of 10ms, 30ms, 50ms and 10ms after the first one
+	for _, elapsed := range []time.Duration{0, 10, 30, 50, 10} {
+		clock.now = clock.now.Add(elapsed * time.Millisecond)
+		assert.NoError(loop.Frame(frames.update, frames.render))
+	}
+
+	assert.Equal([]uint64{0, 1}, frames.ticks)
+	assert.Equal([]time.Duration{40 * time.Millisecond, 40 * time.Millisecond}, frames.deltas)
+	assert.InDeltaSlice([]float64{0, 0.25, 0, 0.25, 0.5}, frames.alphas, 1e-9)
+	assert.Equal(uint64(2), loop.Tick())
+	assert.Empty(clock.slept, "no frame cap")
+}
+
+func TestLoop_SameTicksForAnyFrameRate(t *testing.T) {
+	run := func(frameTime time.Duration) []uint64 {
+		loop := NewLoop(25, 0)
+		clock := &fakeClock{}
+		clock.install(loop)
+
+		frames := &frameLog{}
+
+		for clock.now.Sub(time.Unix(0, 0)) <= time.Second {
+			_ = loop.Frame(frames.update, frames.render)
+			clock.now = clock.now.Add(frameTime)
+		}
+
+		return frames.ticks
+	}
+
+	expected := run(5 * time.Millisecond)
+
+	testify.Len(t, expected, 25)
+	testify.Equal(t, expected, run(25*time.Millisecond))
+	testify.Equal(t, expected, run(125*time.Millisecond))
+}
+
+func TestLoop_SpiralOfDeathClamp(t *testing.T) {
+	loop := NewLoop(DefaultTicksPerSecond, 0)
+	clock := &fakeClock{}
+	clock.install(loop)
+
+	frames := &frameLog{}
+	_ = loop.Frame(frames.update, frames.render)
+
+	clock.now = clock.now.Add(10 * time.Second)
+	_ = loop.Frame(frames.update, frames.render)
+
+	testify.Len(t, frames.ticks, int(maxFrameTime/loop.TickDuration()), "a stall only runs maxFrameTime of ticks")
+}
+
+func TestLoop_FrameCap(t *testing.T) {
+	assert := testify.New(t)
+	loop := NewLoop(DefaultTicksPerSecond, 50)
+	clock := &fakeClock{}
+	clock.install(loop)
+
+	frames := &frameLog{}
+	render := func(alpha float64) error {
+		clock.now = clock.now.Add(5 * time.Millisecond)
+		return frames.render(alpha)
+	}
+
+	assert.NoError(loop.Frame(frames.update, render))
+	assert.Equal([]time.Duration{15 * time.Millisecond}, clock.slept, "a 50 FPS frame lasts 20ms")
+
+	assert.NoError(loop.Frame(frames.update, render))
+	assert.Len(clock.slept, 2)
+	assert.Empty(frames.ticks, "20ms is less than a tick")
+}
+
+func TestLoop_Errors(t *testing.T) {
+	loop := NewLoop(-1, 0)
+	clock := &fakeClock{}
+	clock.install(loop)
+
+	testify.Equal(t, time.Second/DefaultTicksPerSecond, loop.TickDuration())
+
+	errTick := errors.New("tick")
+	_ = loop.Frame(nil, func(float64) error { return nil })
+
+	clock.now = clock.now.Add(time.Second)
+	err := loop.Frame(func(uint64, time.Duration) error { return errTick }, func(float64) error { return nil })
+	testify.Equal(t, errTick, err)
+}
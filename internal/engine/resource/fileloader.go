@@ -0,0 +1,7 @@
+package resource
+
+// FileLoader reads game files by their resource path, such as LevelDetails.
+// Scenes load on a background goroutine, so it must be safe for concurrent use.
+type FileLoader interface {
+	ReadFile(path string) ([]byte, error)
+}
@@ -0,0 +1,154 @@
+package scenemanager
+
+import (
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+	d2dat "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/datfile"
+	d2dc6 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/dc6file"
+)
+
+// loadingFrameDuration is how long each frame of the loading animation shows
+const loadingFrameDuration = 100 * time.Millisecond
+
+type loadingFrame struct {
+	width, height int
+	pixels        []byte
+}
+
+// LoadingScreen is the scene shown while another scene loads, it plays the
+// animation of resource.LoadingScreen
+type LoadingScreen struct {
+	loader   resource.FileLoader
+	gfx      graphicsbackend.Interface
+	frames   []loadingFrame
+	surfaces []graphicsbackend.Surface
+	frame    int
+	elapsed  time.Duration
+}
+
+var _ Scene = &LoadingScreen{}
+
+// NewLoadingScreen creates the loading screen scene
+func NewLoadingScreen(loader resource.FileLoader, gfx graphicsbackend.Interface) *LoadingScreen {
+	result := &LoadingScreen{
+		loader: loader,
+		gfx:    gfx,
+	}
+
+	return result
+}
+
+// Load decodes the animation frames with the loading palette
+func (s *LoadingScreen) Load() error {
+	paletteData, err := s.loader.ReadFile(resource.PaletteLoading)
+	if err != nil {
+		return err
+	}
+
+	palette, err := d2dat.Load(paletteData)
+	if err != nil {
+		return err
+	}
+
+	animationData, err := s.loader.ReadFile(resource.LoadingScreen)
+	if err != nil {
+		return err
+	}
+
+	animation, err := d2dc6.Load(animationData)
+	if err != nil {
+		return err
+	}
+
+	s.frames = make([]loadingFrame, len(animation.Frames))
+
+	for idx, frame := range animation.Frames {
+		indices := animation.DecodeFrame(idx)
+		pixels := make([]byte, len(indices)*4)
+
+		for pixel, colorIndex := range indices {
+			// index 0 is transparent
+			if colorIndex == 0 {
+				continue
+			}
+
+			c, err := palette.GetColor(int(colorIndex))
+			if err != nil {
+				return err
+			}
+
+			// ARGB8888, stored little endian
+			pixels[pixel*4+0] = c.B()
+			pixels[pixel*4+1] = c.G()
+			pixels[pixel*4+2] = c.R()
+			pixels[pixel*4+3] = 0xff
+		}
+
+		s.frames[idx] = loadingFrame{width: int(frame.Width), height: int(frame.Height), pixels: pixels}
+	}
+
+	return nil
+}
+
+// Unload releases the frames
+func (s *LoadingScreen) Unload() error {
+	s.frames = nil
+	s.surfaces = nil
+
+	return nil
+}
+
+// Enter creates the surfaces of the frames and restarts the animation
+func (s *LoadingScreen) Enter() error {
+	s.frame = 0
+	s.elapsed = 0
+
+	if s.surfaces != nil {
+		return nil
+	}
+
+	s.surfaces = make([]graphicsbackend.Surface, len(s.frames))
+
+	for idx := range s.frames {
+		surface, err := s.gfx.NewSurface(s.frames[idx].width, s.frames[idx].height, &s.frames[idx].pixels)
+		if err != nil {
+			return err
+		}
+
+		s.surfaces[idx] = surface
+	}
+
+	return nil
+}
+
+// Exit does nothing, the surfaces are kept for the next load
+func (s *LoadingScreen) Exit() error {
+	return nil
+}
+
+// Update advances the animation
+func (s *LoadingScreen) Update(delta time.Duration) error {
+	if len(s.surfaces) == 0 {
+		return nil
+	}
+
+	s.elapsed += delta
+	for s.elapsed >= loadingFrameDuration {
+		s.elapsed -= loadingFrameDuration
+		s.frame = (s.frame + 1) % len(s.surfaces)
+	}
+
+	return nil
+}
+
+// Render draws the current frame to the screen with the given opacity
+func (s *LoadingScreen) Render(opacity float64) error {
+	screen, ok := s.gfx.(graphicsbackend.Surface)
+	if !ok || opacity <= 0 || len(s.surfaces) == 0 {
+		return nil
+	}
+
+	return s.surfaces[s.frame].RenderToWithOpacity(screen, opacity)
+}
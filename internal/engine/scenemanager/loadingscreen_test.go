@@ -0,0 +1,127 @@
+package scenemanager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend/headlessgraphicsbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/resource"
+	testify "github.com/stretchr/testify/assert"
+)
+
+type testLoader map[string][]byte
+
+func (l testLoader) ReadFile(path string) ([]byte, error) {
+	data, found := l[path]
+	if !found {
+		return nil, fmt.Errorf("%s not found", path)
+	}
+
+	return data, nil
+}
+
+// testPalette has red at index 1
+func testPalette() []byte {
+	result := make([]byte, 256*3)
+	result[1*3+2] = 0xff
+
+	return result
+}
+
+// testAnimation is a DC6 of two 2x2 frames, the top left pixel of the first
+// one is transparent and every other pixel has color 1
+func testAnimation(t *testing.T) []byte {
+	frames := [][]byte{
+		// rows go from the bottom to the top, 0x80 ends a row and a byte
+		// with the high bit set skips transparent pixels
+		{0x02, 1, 1, 0x80, 0x81, 0x01, 1, 0x80},
+		{0x02, 1, 1, 0x80, 0x02, 1, 1, 0x80},
+	}
+
+	buf := &bytes.Buffer{}
+	write := func(values ...interface{}) {
+		for _, value := range values {
+			if err := binary.Write(buf, binary.LittleEndian, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	write(int32(6), uint32(1), uint32(0), []byte{0xee, 0xee, 0xee, 0xee}, uint32(1), uint32(len(frames)))
+	write(make([]uint32, len(frames)))
+
+	for _, data := range frames {
+		write(uint32(0), uint32(2), uint32(2), int32(0), int32(0), uint32(0), uint32(0), uint32(len(data)))
+		write(data, []byte{0xee, 0xee, 0xee})
+	}
+
+	return buf.Bytes()
+}
+
+func newTestLoadingScreen(t *testing.T) (*LoadingScreen, *headlessgraphicsbackend.HeadlessGraphicsBackend) {
+	gfx, err := headlessgraphicsbackend.Create("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader := testLoader{
+		resource.PaletteLoading: testPalette(),
+		resource.LoadingScreen:  testAnimation(t),
+	}
+
+	return NewLoadingScreen(loader, gfx), gfx
+}
+
+func TestLoadingScreen_Load(t *testing.T) {
+	assert := testify.New(t)
+	s, _ := newTestLoadingScreen(t)
+
+	assert.NoError(s.Load())
+	assert.Len(s.frames, 2)
+	assert.Equal([]int{2, 2}, []int{s.frames[0].width, s.frames[0].height})
+	assert.Equal([]byte{0, 0, 0, 0}, s.frames[0].pixels[0:4], "index 0 is transparent")
+	assert.Equal([]byte{0, 0, 0xff, 0xff}, s.frames[0].pixels[4:8], "pixels are ARGB8888 stored little endian")
+
+	assert.NoError(s.Unload())
+	assert.Nil(s.frames)
+
+	s.loader = testLoader{resource.PaletteLoading: testPalette()}
+	assert.EqualError(s.Load(), resource.LoadingScreen+" not found")
+}
+
+func TestLoadingScreen_Render(t *testing.T) {
+	assert := testify.New(t)
+	s, gfx := newTestLoadingScreen(t)
+	red := color.RGBA{R: 0xff, A: 0xff}
+	black := color.RGBA{A: 0xff}
+
+	assert.NoError(s.Render(1), "nothing is drawn before Enter")
+	assert.NoError(s.Load())
+	assert.NoError(s.Enter())
+	assert.Len(s.surfaces, 2)
+
+	assert.NoError(s.Render(1))
+	assert.NoError(gfx.Render())
+	assert.Equal(black, gfx.Screenshot().At(0, 0))
+	assert.Equal(red, gfx.Screenshot().At(1, 0))
+
+	assert.NoError(s.Update(loadingFrameDuration))
+	assert.NoError(s.Render(1))
+	assert.NoError(gfx.Render())
+	assert.Equal(red, gfx.Screenshot().At(0, 0))
+
+	assert.NoError(s.Render(0.5))
+	assert.NoError(gfx.Render())
+	assert.Equal(color.RGBA{R: 0x80, A: 0xff}, gfx.Screenshot().At(0, 0), "drawn at half opacity over black")
+
+	assert.NoError(s.Update(loadingFrameDuration + time.Millisecond))
+	assert.Equal(0, s.frame, "the animation loops")
+
+	assert.NoError(s.Exit())
+	assert.NoError(s.Enter())
+	assert.Equal(0, s.frame, "the animation restarts")
+}
@@ -0,0 +1,59 @@
+package scenemanager
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownScene is returned when creating a scene that isn't registered
+var ErrUnknownScene = errors.New("unknown scene")
+
+// SceneFactory creates a scene, it is called each time the scene is created
+// by name so that a scene popped and pushed again starts afresh
+type SceneFactory func() Scene
+
+// Register names a scene so that it can be created by name, such as from the
+// console. Names are not case sensitive and must be unique.
+func (m *SceneManager) Register(name string, factory SceneFactory) error {
+	if name == "" || strings.ContainsAny(name, " \t\"\\") {
+		return fmt.Errorf("invalid scene name %q", name)
+	}
+
+	if factory == nil {
+		return fmt.Errorf("scene %s has no factory", name)
+	}
+
+	key := strings.ToLower(name)
+	if _, found := m.factories[key]; found {
+		return fmt.Errorf("scene %s is already registered", name)
+	}
+
+	m.factories[key] = factory
+
+	return nil
+}
+
+// Names returns the names of the registered scenes, sorted
+func (m *SceneManager) Names() []string {
+	result := make([]string, 0, len(m.factories))
+
+	for name := range m.factories {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Create creates a registered scene
+func (m *SceneManager) Create(name string) (Scene, error) {
+	factory, found := m.factories[strings.ToLower(name)]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownScene, name)
+	}
+
+	return factory(), nil
+}
@@ -0,0 +1,36 @@
+package scenemanager
+
+import (
+	"errors"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestSceneManager_Register(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+	m := New(nil)
+
+	factory := func() Scene {
+		return newTestScene("menu", events)
+	}
+
+	assert.NoError(m.Register("MainMenu", factory))
+	assert.NoError(m.Register("credits", factory))
+	assert.EqualError(m.Register("mainmenu", factory), "scene mainmenu is already registered")
+	assert.EqualError(m.Register("main menu", factory), `invalid scene name "main menu"`)
+	assert.EqualError(m.Register("options", nil), "scene options has no factory")
+	assert.Equal([]string{"credits", "mainmenu"}, m.Names())
+
+	first, err := m.Create("MAINMENU")
+	assert.NoError(err)
+
+	second, err := m.Create("mainmenu")
+	assert.NoError(err)
+	assert.False(first == second, "each scene created is new")
+
+	_, err = m.Create("options")
+	assert.True(errors.Is(err, ErrUnknownScene))
+	assert.EqualError(err, "unknown scene: options")
+}
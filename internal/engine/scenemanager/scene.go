@@ -0,0 +1,29 @@
+package scenemanager
+
+import (
+	"time"
+)
+
+// Scene is a screen of the game, such as the main menu or character select.
+//
+// The scene manager calls the methods in this order: Load once before the
+// scene is first shown, then Enter and Exit around each time the scene is
+// shown, with Update and Render in between, and finally Unload once the
+// scene is removed from the stack.
+type Scene interface {
+	// Load reads and decodes the scene's assets. It runs on a background
+	// goroutine while the loading screen is shown, so it must not use the
+	// graphics backend, surfaces belong in Enter.
+	Load() error
+	// Unload releases everything Load and Enter acquired.
+	Unload() error
+	// Enter is called when the transition to the scene starts.
+	Enter() error
+	// Exit is called when the transition away from the scene has ended.
+	Exit() error
+	// Update advances the scene by one simulation tick.
+	Update(delta time.Duration) error
+	// Render draws the scene, opacity goes from 0 to 1 while the scene
+	// crossfades in and is 1 otherwise, fades are drawn over the scene.
+	Render(opacity float64) error
+}
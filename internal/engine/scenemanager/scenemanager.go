@@ -1,10 +1,441 @@
 package scenemanager
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
+)
+
+// ErrNoScene is returned when popping from an empty stack
+var ErrNoScene = errors.New("no scene to pop")
+
+// overlayLevels is how many shades of the fade overlay are drawn, each is a
+// screen sized surface created the first time it is needed
+const overlayLevels = 16
+
+type operationKind int
+
+const (
+	operationPush operationKind = iota
+	operationPop
+	operationReplace
+)
+
+type operation struct {
+	kind       operationKind
+	scene      Scene
+	transition Transition
+}
+
+// pendingLoad is a scene being loaded in the background
+type pendingLoad struct {
+	operation
+	done chan error
+}
+
+// SceneManager keeps a stack of scenes and shows the one at the top.
+//
+// Push, Pop and Replace are queued and carried out by Update one after the
+// other, each waiting for the previous transition to end. A scene that isn't
+// loaded yet is loaded in the background while the loading screen is shown.
+// Scenes are told apart by identity, so they should be pointers. Scenes
+// registered by name can be created with Create.
+//
+// The scene manager isn't safe for concurrent use, it belongs to the main loop.
 type SceneManager struct {
+	stack         []Scene
+	loaded        map[Scene]bool
+	queue         []operation
+	loading       *pendingLoad
+	loadingScreen Scene
+	shown         Scene
+	transition    *activeTransition
+	gfx           graphicsbackend.Interface
+	overlays      map[int]graphicsbackend.Surface
+	factories     map[string]SceneFactory
+}
+
+// New creates an empty scene manager, the fades are drawn with the graphics
+// backend
+func New(gfx graphicsbackend.Interface) *SceneManager {
+	result := &SceneManager{
+		loaded:    make(map[Scene]bool),
+		gfx:       gfx,
+		overlays:  make(map[int]graphicsbackend.Surface),
+		factories: make(map[string]SceneFactory),
+	}
+
+	return result
 }
 
-func New() *SceneManager {
-	result := &SceneManager{}
+// SetLoadingScreen sets the scene shown while the next scene loads, it is
+// loaded on the main loop the first time it is needed
+func (m *SceneManager) SetLoadingScreen(scene Scene) {
+	m.loadingScreen = scene
+}
+
+// Push puts a scene on top of the stack
+func (m *SceneManager) Push(scene Scene, transition Transition) {
+	m.queue = append(m.queue, operation{kind: operationPush, scene: scene, transition: transition})
+}
+
+// Pop removes the scene at the top of the stack and unloads it
+func (m *SceneManager) Pop(transition Transition) {
+	m.queue = append(m.queue, operation{kind: operationPop, transition: transition})
+}
+
+// Replace swaps the scene at the top of the stack for another one and
+// unloads the old one
+func (m *SceneManager) Replace(scene Scene, transition Transition) {
+	m.queue = append(m.queue, operation{kind: operationReplace, scene: scene, transition: transition})
+}
+
+// Current returns the scene at the top of the stack, nil if it is empty
+func (m *SceneManager) Current() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+
+	return m.stack[len(m.stack)-1]
+}
+
+// Scenes returns the stack from the bottom to the top
+func (m *SceneManager) Scenes() []Scene {
+	result := make([]Scene, len(m.stack))
+	copy(result, m.stack)
 
 	return result
 }
+
+// Shown returns the scene being shown, which is the loading screen while a
+// scene loads
+func (m *SceneManager) Shown() Scene {
+	return m.shown
+}
+
+// IsLoading tells whether a scene is being loaded
+func (m *SceneManager) IsLoading() bool {
+	return m.loading != nil
+}
+
+// InTransition tells whether a transition is running
+func (m *SceneManager) InTransition() bool {
+	return m.transition != nil
+}
+
+// Update advances the running transition, carries out the queued operations
+// and updates the scene being shown
+func (m *SceneManager) Update(delta time.Duration) error {
+	if m.transition != nil {
+		m.transition.elapsed += delta
+
+		if err := m.finishTransition(); err != nil {
+			return err
+		}
+	}
+
+	if err := m.process(); err != nil {
+		return err
+	}
+
+	if m.shown == nil {
+		return nil
+	}
+
+	return m.shown.Update(delta)
+}
+
+// Render draws the scene being shown, or both scenes of a transition. A
+// crossfade draws both scenes translucent, a fade draws the scene opaque under
+// a black overlay, which is darker as its opacity drops.
+func (m *SceneManager) Render() error {
+	if m.transition == nil {
+		if m.shown == nil {
+			return nil
+		}
+
+		return m.shown.Render(1)
+	}
+
+	fromOpacity, toOpacity := m.transition.opacities()
+	darkness := 1 - fromOpacity - toOpacity
+
+	if m.transition.Kind == TransitionFade {
+		// a scene drawn translucent would show its surfaces through each
+		// other, so the overlay does the fading
+		fromOpacity, toOpacity = math.Ceil(fromOpacity), math.Ceil(toOpacity)
+	}
+
+	if m.transition.from != nil && fromOpacity > 0 {
+		if err := m.transition.from.Render(fromOpacity); err != nil {
+			return err
+		}
+	}
+
+	if m.transition.to != nil && toOpacity > 0 {
+		if err := m.transition.to.Render(toOpacity); err != nil {
+			return err
+		}
+	}
+
+	if m.transition.Kind != TransitionFade {
+		return nil
+	}
+
+	return m.renderOverlay(darkness)
+}
+
+// renderOverlay darkens the screen, darkness goes from 0 to 1
+func (m *SceneManager) renderOverlay(darkness float64) error {
+	screen, ok := m.gfx.(graphicsbackend.Surface)
+	if !ok {
+		return nil
+	}
+
+	level := int(math.Round(darkness * overlayLevels))
+	if level <= 0 {
+		return nil
+	}
+
+	overlay, found := m.overlays[level]
+	if !found {
+		// ARGB8888 stored little endian, only the alpha is set
+		pixels := make([]byte, graphicsbackend.ScreenWidth*graphicsbackend.ScreenHeight*4)
+		alpha := byte(math.MaxUint8 * level / overlayLevels)
+
+		for idx := 3; idx < len(pixels); idx += 4 {
+			pixels[idx] = alpha
+		}
+
+		var err error
+		if overlay, err = m.gfx.NewSurface(graphicsbackend.ScreenWidth, graphicsbackend.ScreenHeight, &pixels); err != nil {
+			return err
+		}
+
+		m.overlays[level] = overlay
+	}
+
+	return overlay.RenderTo(screen)
+}
+
+// UnloadAll exits the scene being shown and unloads every scene, waiting for
+// a background load to end first. The stack is empty afterwards.
+func (m *SceneManager) UnloadAll(ctx context.Context) error {
+	var firstErr error
+
+	keep := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if m.loading != nil {
+		select {
+		case err := <-m.loading.done:
+			if err == nil {
+				m.loaded[m.loading.scene] = true
+			}
+		case <-ctx.Done():
+			keep(ctx.Err())
+		}
+
+		m.loading = nil
+	}
+
+	if m.transition != nil && m.transition.from != nil && m.transition.from != m.shown {
+		keep(m.transition.from.Exit())
+	}
+
+	if m.shown != nil {
+		keep(m.shown.Exit())
+	}
+
+	for scene := range m.loaded {
+		keep(scene.Unload())
+	}
+
+	m.stack = nil
+	m.queue = nil
+	m.loaded = make(map[Scene]bool)
+	m.shown = nil
+	m.transition = nil
+
+	return firstErr
+}
+
+// process carries out queued operations until one has to wait for a
+// transition or a load
+func (m *SceneManager) process() error {
+	for m.transition == nil {
+		if m.loading != nil {
+			select {
+			case err := <-m.loading.done:
+				if err := m.finishLoad(err); err != nil {
+					return err
+				}
+
+				continue
+			default:
+				return nil
+			}
+		}
+
+		if len(m.queue) == 0 {
+			return nil
+		}
+
+		op := m.queue[0]
+		m.queue = m.queue[1:]
+
+		if err := m.start(op); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// start carries out an operation, or starts loading its scene
+func (m *SceneManager) start(op operation) error {
+	if op.kind == operationPop {
+		if len(m.stack) == 0 {
+			return ErrNoScene
+		}
+
+		removed := m.stack[len(m.stack)-1]
+		m.stack = m.stack[:len(m.stack)-1]
+
+		return m.show(m.Current(), op.transition, removed)
+	}
+
+	if m.loaded[op.scene] {
+		return m.apply(op)
+	}
+
+	// the loading screen is loaded before the background load starts, so
+	// that both don't read the game files at the same time
+	if m.loadingScreen != nil && !m.loaded[m.loadingScreen] {
+		if err := m.loadingScreen.Load(); err != nil {
+			return fmt.Errorf("loading the loading screen: %w", err)
+		}
+
+		m.loaded[m.loadingScreen] = true
+	}
+
+	done := make(chan error, 1)
+
+	go func(scene Scene) {
+		done <- scene.Load()
+	}(op.scene)
+
+	m.loading = &pendingLoad{operation: op, done: done}
+
+	if m.loadingScreen == nil {
+		return nil
+	}
+
+	return m.show(m.loadingScreen, op.transition, nil)
+}
+
+// finishLoad carries out the operation of a scene that finished loading, or
+// goes back to the top of the stack when it failed
+func (m *SceneManager) finishLoad(loadErr error) error {
+	op := m.loading.operation
+	m.loading = nil
+
+	if loadErr != nil {
+		if err := m.show(m.Current(), op.transition, nil); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("loading scene %T: %w", op.scene, loadErr)
+	}
+
+	m.loaded[op.scene] = true
+
+	return m.apply(op)
+}
+
+// apply carries out a push or a replace of a loaded scene
+func (m *SceneManager) apply(op operation) error {
+	if op.kind == operationReplace && len(m.stack) > 0 {
+		removed := m.stack[len(m.stack)-1]
+		m.stack[len(m.stack)-1] = op.scene
+
+		return m.show(op.scene, op.transition, removed)
+	}
+
+	m.stack = append(m.stack, op.scene)
+
+	return m.show(op.scene, op.transition, nil)
+}
+
+// show starts a transition from the scene being shown to another one, removed
+// is unloaded when the transition ends unless it is still on the stack
+func (m *SceneManager) show(to Scene, transition Transition, removed Scene) error {
+	from := m.shown
+
+	if to != nil && to != from {
+		if err := to.Enter(); err != nil {
+			return fmt.Errorf("entering scene %T: %w", to, err)
+		}
+	}
+
+	m.shown = to
+	m.transition = &activeTransition{
+		Transition: transition,
+		from:       from,
+		to:         to,
+		finished: func() error {
+			if from != nil && from != to {
+				if err := from.Exit(); err != nil {
+					return fmt.Errorf("exiting scene %T: %w", from, err)
+				}
+			}
+
+			return m.unload(removed)
+		},
+	}
+
+	if from == to {
+		m.transition.Transition = Cut()
+	}
+
+	return m.finishTransition()
+}
+
+// finishTransition ends the running transition if its time is up
+func (m *SceneManager) finishTransition() error {
+	if m.transition.elapsed < m.transition.Duration {
+		return nil
+	}
+
+	finished := m.transition.finished
+	m.transition = nil
+
+	return finished()
+}
+
+func (m *SceneManager) unload(scene Scene) error {
+	if scene == nil || scene == m.shown || !m.loaded[scene] {
+		return nil
+	}
+
+	for _, stacked := range m.stack {
+		if stacked == scene {
+			return nil
+		}
+	}
+
+	delete(m.loaded, scene)
+
+	if err := scene.Unload(); err != nil {
+		return fmt.Errorf("unloading scene %T: %w", scene, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,375 @@
+package scenemanager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend/headlessgraphicsbackend"
+	testify "github.com/stretchr/testify/assert"
+)
+
+// eventLog is shared by the scenes of a test, Load runs on another goroutine
+type eventLog struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) get() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	result := l.events
+	l.events = nil
+
+	return result
+}
+
+type testScene struct {
+	name    string
+	events  *eventLog
+	loadErr error
+	release chan struct{}
+	opacity []float64
+}
+
+func newTestScene(name string, events *eventLog) *testScene {
+	return &testScene{name: name, events: events}
+}
+
+func (s *testScene) log(event string) {
+	s.events.add(fmt.Sprintf("%s.%s", s.name, event))
+}
+
+func (s *testScene) Load() error {
+	if s.release != nil {
+		<-s.release
+	}
+
+	s.log("Load")
+
+	return s.loadErr
+}
+
+func (s *testScene) Unload() error {
+	s.log("Unload")
+	return nil
+}
+
+func (s *testScene) Enter() error {
+	s.log("Enter")
+	return nil
+}
+
+func (s *testScene) Exit() error {
+	s.log("Exit")
+	return nil
+}
+
+func (s *testScene) Update(time.Duration) error {
+	return nil
+}
+
+func (s *testScene) Render(opacity float64) error {
+	s.opacity = append(s.opacity, opacity)
+	return nil
+}
+
+// settle updates the manager until the background load is over
+func settle(t *testing.T, m *SceneManager) error {
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		if err := m.Update(0); err != nil {
+			return err
+		}
+
+		if !m.IsLoading() {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("the scene didn't load")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSceneManager_PushPopReplace(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+	menu := newTestScene("menu", events)
+	options := newTestScene("options", events)
+	game := newTestScene("game", events)
+
+	m := New(nil)
+	assert.Nil(m.Current())
+
+	m.Push(menu, Cut())
+	m.Push(options, Cut())
+	assert.Nil(m.Current(), "operations wait for Update")
+
+	assert.NoError(settle(t, m))
+	assert.NoError(settle(t, m))
+	assert.Equal([]Scene{menu, options}, m.Scenes())
+	assert.Equal(options, m.Shown())
+
+	m.Pop(Cut())
+	m.Replace(game, Cut())
+	assert.NoError(settle(t, m))
+	assert.NoError(settle(t, m))
+	assert.Equal([]Scene{game}, m.Scenes())
+
+	assert.Equal([]string{
+		"menu.Load", "menu.Enter",
+		"options.Load", "options.Enter", "menu.Exit",
+		"menu.Enter", "options.Exit", "options.Unload",
+		"game.Load", "game.Enter", "menu.Exit", "menu.Unload",
+	}, events.get())
+
+	m.Pop(Cut())
+	assert.NoError(m.Update(0))
+	m.Pop(Cut())
+	assert.Equal(ErrNoScene, m.Update(0))
+}
+
+func TestSceneManager_LoadingScreen(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+	loading := newTestScene("loading", events)
+	menu := newTestScene("menu", events)
+	menu.release = make(chan struct{})
+
+	m := New(nil)
+	m.SetLoadingScreen(loading)
+	m.Push(menu, Cut())
+
+	assert.NoError(m.Update(0))
+	assert.True(m.IsLoading())
+	assert.Equal(loading, m.Shown())
+	assert.Nil(m.Current())
+
+	close(menu.release)
+	assert.NoError(settle(t, m))
+
+	assert.Equal(menu, m.Shown())
+	assert.Equal([]string{"loading.Load", "loading.Enter", "menu.Load", "menu.Enter", "loading.Exit"}, events.get())
+}
+
+func TestSceneManager_LoadingScreenFirst(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+	loading := newTestScene("loading", events)
+	loading.release = make(chan struct{})
+	menu := newTestScene("menu", events)
+
+	m := New(nil)
+	m.SetLoadingScreen(loading)
+	m.Push(menu, Cut())
+
+	// the scene would load in the meantime if its load had started
+	time.AfterFunc(10*time.Millisecond, func() { close(loading.release) })
+
+	assert.NoError(settle(t, m))
+	assert.Equal("loading.Load", events.get()[0], "both loads don't read the game files at the same time")
+}
+
+func TestSceneManager_LoadError(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+	loading := newTestScene("loading", events)
+	menu := newTestScene("menu", events)
+	broken := newTestScene("broken", events)
+	broken.loadErr = errors.New("missing file")
+
+	m := New(nil)
+	m.SetLoadingScreen(loading)
+	m.Push(menu, Cut())
+	assert.NoError(settle(t, m))
+
+	m.Push(broken, Cut())
+	err := settle(t, m)
+	assert.True(errors.Is(err, broken.loadErr))
+	assert.Equal(menu, m.Shown(), "a failed load goes back to the top of the stack")
+	assert.Equal([]Scene{menu}, m.Scenes())
+}
+
+func TestSceneManager_Crossfade(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+	menu := newTestScene("menu", events)
+	game := newTestScene("game", events)
+
+	m := New(nil)
+	m.Push(menu, Cut())
+	assert.NoError(settle(t, m))
+
+	m.Push(game, Crossfade(100*time.Millisecond))
+	assert.NoError(settle(t, m))
+	assert.True(m.InTransition())
+
+	assert.NoError(m.Render())
+	assert.NoError(m.Update(25 * time.Millisecond))
+	assert.NoError(m.Render())
+	assert.NoError(m.Update(75 * time.Millisecond))
+	assert.False(m.InTransition())
+	assert.NoError(m.Render())
+
+	assert.Equal([]float64{1, 0.75}, menu.opacity)
+	assert.Equal([]float64{0.25, 1}, game.opacity)
+	logged := events.get()
+	assert.Equal("menu.Exit", logged[len(logged)-1], "the old scene exits when the transition ends")
+}
+
+func TestSceneManager_Fade(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+	menu := newTestScene("menu", events)
+	game := newTestScene("game", events)
+
+	m := New(nil)
+	m.Push(menu, Cut())
+	assert.NoError(settle(t, m))
+
+	m.Replace(game, Fade(100*time.Millisecond))
+	assert.NoError(settle(t, m))
+
+	// the old scene fades out, nothing is drawn halfway through, then the
+	// new scene fades in. The overlay does the fading, the scenes are opaque.
+	for i := 0; i < 3; i++ {
+		assert.NoError(m.Update(25 * time.Millisecond))
+		assert.NoError(m.Render())
+	}
+
+	assert.Equal([]float64{1}, menu.opacity)
+	assert.Equal([]float64{1}, game.opacity)
+}
+
+// filledScene fills the screen with one ARGB8888 color
+type filledScene struct {
+	*testScene
+	gfx     graphicsbackend.Interface
+	argb    []byte
+	surface graphicsbackend.Surface
+}
+
+func newFilledScene(name string, events *eventLog, gfx graphicsbackend.Interface, argb ...byte) *filledScene {
+	return &filledScene{testScene: newTestScene(name, events), gfx: gfx, argb: argb}
+}
+
+func (s *filledScene) Enter() error {
+	pixels := bytes.Repeat(s.argb, graphicsbackend.ScreenWidth*graphicsbackend.ScreenHeight)
+
+	surface, err := s.gfx.NewSurface(graphicsbackend.ScreenWidth, graphicsbackend.ScreenHeight, &pixels)
+	s.surface = surface
+
+	return err
+}
+
+func (s *filledScene) Render(opacity float64) error {
+	return s.surface.RenderToWithOpacity(s.gfx.(graphicsbackend.Surface), opacity)
+}
+
+func TestSceneManager_FadeOverlay(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+
+	gfx, err := headlessgraphicsbackend.Create("")
+	assert.NoError(err)
+
+	menu := newFilledScene("menu", events, gfx, 0xff, 0xff, 0xff, 0xff)
+	game := newFilledScene("game", events, gfx, 0xff, 0xff, 0xff, 0xff)
+
+	m := New(gfx)
+	m.Push(menu, Cut())
+	assert.NoError(settle(t, m))
+
+	m.Replace(game, Fade(100*time.Millisecond))
+	assert.NoError(settle(t, m))
+
+	shades := make([]uint8, 0)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(m.Render())
+		assert.NoError(gfx.Render())
+
+		shades = append(shades, gfx.Screenshot().(*image.RGBA).RGBAAt(400, 300).R)
+
+		assert.NoError(m.Update(25 * time.Millisecond))
+	}
+
+	assert.Equal([]uint8{0xff, 0x80, 0, 0x80, 0xff}, shades, "the screen goes through black")
+	assert.Len(m.overlays, 2, "the overlay shades are kept")
+}
+
+func TestSceneManager_CrossfadeBlend(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+
+	gfx, err := headlessgraphicsbackend.Create("")
+	assert.NoError(err)
+
+	// red and blue, ARGB8888 stored little endian
+	menu := newFilledScene("menu", events, gfx, 0, 0, 0xff, 0xff)
+	game := newFilledScene("game", events, gfx, 0xff, 0, 0, 0xff)
+
+	m := New(gfx)
+	m.Push(menu, Cut())
+	assert.NoError(settle(t, m))
+
+	m.Replace(game, Crossfade(100*time.Millisecond))
+	assert.NoError(settle(t, m))
+
+	colors := make([]color.RGBA, 0)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(m.Render())
+		assert.NoError(gfx.Render())
+
+		colors = append(colors, gfx.Screenshot().(*image.RGBA).RGBAAt(400, 300))
+
+		assert.NoError(m.Update(50 * time.Millisecond))
+	}
+
+	assert.Equal([]color.RGBA{
+		{R: 0xff, A: 0xff},
+		{R: 0x3f, B: 0x80, A: 0xff},
+		{B: 0xff, A: 0xff},
+	}, colors, "both scenes are drawn translucent halfway through")
+	assert.Empty(m.overlays, "a crossfade draws no overlay")
+}
+
+func TestSceneManager_UnloadAll(t *testing.T) {
+	assert := testify.New(t)
+	events := &eventLog{}
+	menu := newTestScene("menu", events)
+	game := newTestScene("game", events)
+
+	m := New(nil)
+	m.Push(menu, Cut())
+	m.Push(game, Cut())
+	assert.NoError(settle(t, m))
+	assert.NoError(settle(t, m))
+
+	events.get()
+
+	assert.NoError(m.UnloadAll(context.Background()))
+	assert.ElementsMatch([]string{"game.Exit", "game.Unload", "menu.Unload"}, events.get())
+	assert.Empty(m.Scenes())
+	assert.Nil(m.Shown())
+}
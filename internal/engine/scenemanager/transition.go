@@ -0,0 +1,79 @@
+package scenemanager
+
+import (
+	"time"
+)
+
+// TransitionKind is how a scene change is shown
+type TransitionKind int
+
+const (
+	// TransitionCut switches scenes at once
+	TransitionCut TransitionKind = iota
+	// TransitionFade fades the old scene out, then the new one in
+	TransitionFade
+	// TransitionCrossfade fades the old scene out while the new one fades in
+	TransitionCrossfade
+)
+
+// Transition describes a scene change
+type Transition struct {
+	Kind     TransitionKind
+	Duration time.Duration
+}
+
+// Cut returns a transition that switches scenes at once
+func Cut() Transition {
+	return Transition{Kind: TransitionCut}
+}
+
+// Fade returns a transition that fades through black, each half takes half
+// of the duration
+func Fade(duration time.Duration) Transition {
+	return Transition{Kind: TransitionFade, Duration: duration}
+}
+
+// Crossfade returns a transition that blends the old scene into the new one,
+// the scenes draw their surfaces with the opacity they are given
+func Crossfade(duration time.Duration) Transition {
+	return Transition{Kind: TransitionCrossfade, Duration: duration}
+}
+
+// activeTransition is a transition being shown, from or to may be nil when
+// the stack is empty
+type activeTransition struct {
+	Transition
+	from     Scene
+	to       Scene
+	elapsed  time.Duration
+	finished func() error
+}
+
+// progress returns how far the transition is, in [0, 1]
+func (t *activeTransition) progress() float64 {
+	if t.Duration <= 0 || t.elapsed >= t.Duration {
+		return 1
+	}
+
+	return float64(t.elapsed) / float64(t.Duration)
+}
+
+// opacities returns the opacities of the old and the new scene
+func (t *activeTransition) opacities() (from, to float64) {
+	p := t.progress()
+
+	switch t.Kind {
+	case TransitionFade:
+		const half = 0.5
+
+		if p < half {
+			return 1 - p/half, 0
+		}
+
+		return 0, (p - half) / half
+	case TransitionCrossfade:
+		return 1 - p, p
+	default:
+		return 0, 1
+	}
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long the shutdown hooks may take altogether
+const DefaultShutdownTimeout = 5 * time.Second
+
+// Shutdown orders of the engine's subsystems, hooks with a lower order run
+// first and hooks with the same order run in the order they were registered
+const (
+	// ShutdownOrderGame is for game state and scenes, which may still use
+	// everything else while stopping
+	ShutdownOrderGame = 100
+	// ShutdownOrderResources is for the configuration, archives and files
+	ShutdownOrderResources = 200
+	// ShutdownOrderBackends is for windows, audio devices and the like. These
+	// hooks run on the goroutine calling Shutdown, as the backends must be
+	// closed on the thread that created them.
+	ShutdownOrderBackends = 300
+)
+
+// ShutdownFunc releases the resources of a subsystem, it should give up when
+// the context is done
+type ShutdownFunc func(ctx context.Context) error
+
+// HookError is the error of a shutdown hook
+type HookError struct {
+	Hook string
+	Err  error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("shutdown hook %q: %v", e.Hook, e.Err)
+}
+
+// Unwrap returns the error of the hook
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownError holds the errors of every failed shutdown hook, in the order
+// the hooks ran
+type ShutdownError []*HookError
+
+func (e ShutdownError) Error() string {
+	messages := make([]string, len(e))
+
+	for idx := range e {
+		messages[idx] = e[idx].Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether the error of any hook matches the target
+func (e ShutdownError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type shutdownHook struct {
+	name  string
+	order int
+	fn    ShutdownFunc
+}
+
+type shutdownHooks struct {
+	mutex sync.Mutex
+	hooks []shutdownHook
+	done  bool
+}
+
+// OnShutdown registers a hook that runs when the engine shuts down, see the
+// ShutdownOrder constants for the order
+func (engine *Engine) OnShutdown(name string, order int, fn ShutdownFunc) {
+	engine.shutdown.mutex.Lock()
+	defer engine.shutdown.mutex.Unlock()
+
+	engine.shutdown.hooks = append(engine.shutdown.hooks, shutdownHook{name: name, order: order, fn: fn})
+}
+
+// Shutdown runs the shutdown hooks once, a failing hook doesn't keep the next
+// ones from running. When the context is done, the running hook is abandoned,
+// unless it is a backend hook, and the remaining ones are skipped. The
+// returned error is a ShutdownError.
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	engine.shutdown.mutex.Lock()
+
+	if engine.shutdown.done {
+		engine.shutdown.mutex.Unlock()
+		return nil
+	}
+
+	engine.shutdown.done = true
+	hooks := engine.shutdown.hooks
+	engine.shutdown.mutex.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].order < hooks[j].order
+	})
+
+	var errs ShutdownError
+
+	for _, hook := range hooks {
+		if err := runHook(ctx, hook); err != nil {
+			errs = append(errs, &HookError{Hook: hook.name, Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func runHook(ctx context.Context, hook shutdownHook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if hook.order >= ShutdownOrderBackends {
+		return hook.fn(ctx)
+	}
+
+	result := make(chan error, 1)
+
+	go func() {
+		result <- hook.fn(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
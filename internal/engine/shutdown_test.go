@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/graphicsbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/backends/inputbackend/headlessinputbackend"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/configuration"
+	"github.com/OpenDiablo2/AbyssEngine/internal/engine/scenemanager"
+	testify "github.com/stretchr/testify/assert"
+)
+
+type testInput struct {
+	inputbackend.Interface
+	processed int
+	quitAfter int
+}
+
+func (i *testInput) Process() error {
+	i.processed++
+	if i.quitAfter > 0 && i.processed >= i.quitAfter {
+		return inputbackend.ErrQuitRequested
+	}
+
+	return nil
+}
+
+type testGraphics struct {
+	graphicsbackend.Interface
+	rendered int
+}
+
+func (g *testGraphics) Render() error {
+	g.rendered++
+	return nil
+}
+
+// testLoader has no game files
+type testLoader struct{}
+
+func (testLoader) ReadFile(path string) ([]byte, error) {
+	return nil, fmt.Errorf("%s not found", path)
+}
+
+func newTestEngine(t *testing.T, input *testInput) *Engine {
+	if input.Interface == nil {
+		input.Interface = &headlessinputbackend.HeadlessInputBackend{}
+	}
+
+	config := configuration.DefaultConfig()
+	config.FpsCap = 1000
+
+	gfx := &testGraphics{}
+
+	result, err := New(config, testLoader{}, gfx, input, scenemanager.New(gfx))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return result
+}
+
+func TestEngine_RunStopsOnQuitRequest(t *testing.T) {
+	input := &testInput{quitAfter: 3}
+	e := newTestEngine(t, input)
+
+	testify.NoError(t, e.Run(context.Background()))
+	testify.Equal(t, 3, input.processed)
+}
+
+func TestEngine_RunStopsOnContext(t *testing.T) {
+	e := newTestEngine(t, &testInput{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+
+	go func() {
+		stopped <- e.Run(ctx)
+	}()
+
+	cancel()
+
+	select {
+	case err := <-stopped:
+		testify.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't stop")
+	}
+}
+
+func TestEngine_Quit(t *testing.T) {
+	e := newTestEngine(t, &testInput{})
+
+	e.Quit()
+	e.Quit()
+
+	testify.NoError(t, e.Run(context.Background()))
+}
+
+func TestEngine_ShutdownOrder(t *testing.T) {
+	assert := testify.New(t)
+	e := newTestEngine(t, &testInput{})
+
+	var ran []string
+
+	hook := func(name string, err error) ShutdownFunc {
+		return func(context.Context) error {
+			ran = append(ran, name)
+			return err
+		}
+	}
+
+	errSave := errors.New("save")
+
+	e.OnShutdown("window", ShutdownOrderBackends, hook("window", nil))
+	e.OnShutdown("config", ShutdownOrderResources, hook("config", errSave))
+	e.OnShutdown("scene", ShutdownOrderGame, hook("scene", nil))
+	e.OnShutdown("archives", ShutdownOrderResources, hook("archives", nil))
+
+	err := e.Shutdown(context.Background())
+	assert.Equal([]string{"scene", "config", "archives", "window"}, ran)
+	assert.True(errors.Is(err, errSave))
+	assert.EqualError(err, `shutdown hook "config": save`)
+
+	assert.NoError(e.Shutdown(context.Background()), "hooks only run once")
+	assert.Len(ran, 4)
+}
+
+func TestEngine_ShutdownTimeout(t *testing.T) {
+	assert := testify.New(t)
+	e := newTestEngine(t, &testInput{})
+
+	block := make(chan struct{})
+	defer close(block)
+
+	skippedRan := false
+
+	e.OnShutdown("stuck", ShutdownOrderGame, func(context.Context) error {
+		<-block
+		return nil
+	})
+	e.OnShutdown("skipped", ShutdownOrderBackends, func(context.Context) error {
+		skippedRan = true
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := e.Shutdown(ctx)
+
+	var shutdownErr ShutdownError
+
+	assert.True(errors.As(err, &shutdownErr))
+	assert.Len(shutdownErr, 2)
+	assert.Equal("stuck", shutdownErr[0].Hook)
+	assert.Equal("skipped", shutdownErr[1].Hook)
+	assert.True(errors.Is(shutdownErr[1], context.DeadlineExceeded))
+	assert.False(skippedRan)
+}
+
+// goroutineID reads the id of the calling goroutine from its stack trace
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	return strings.Fields(string(buf))[1]
+}
+
+func TestEngine_ShutdownBackendsOnCaller(t *testing.T) {
+	assert := testify.New(t)
+	e := newTestEngine(t, &testInput{})
+
+	ran := make(map[string]string)
+
+	for name, order := range map[string]int{"scene": ShutdownOrderGame, "window": ShutdownOrderBackends} {
+		name := name
+
+		e.OnShutdown(name, order, func(context.Context) error {
+			ran[name] = goroutineID()
+			return nil
+		})
+	}
+
+	assert.NoError(e.Shutdown(context.Background()))
+	assert.Equal(goroutineID(), ran["window"], "the backends are closed on the thread that created them")
+	assert.NotEqual(goroutineID(), ran["scene"])
+}
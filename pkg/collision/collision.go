@@ -0,0 +1,210 @@
+package collision
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+
+	d2ds1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/ds1file"
+	d2dt1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/dt1file"
+)
+
+// SubTilesPerTile is the number of sub-tiles along each side of a map cell
+const SubTilesPerTile = 5
+
+// Map holds the combined sub-tile flags of a level, it is indexed by sub-tile
+// coordinates. Sub-tiles outside of the map block everything.
+type Map struct {
+	width  int
+	height int
+	flags  []d2dt1.SubTileFlags
+}
+
+// NewMap creates an empty collision map of the given size in sub-tiles, where
+// every sub-tile is walkable
+func NewMap(width, height int) *Map {
+	return &Map{width: width, height: height, flags: make([]d2dt1.SubTileFlags, width*height)}
+}
+
+// FromDS1 builds the collision map of a DS1. The flags of the floor and wall
+// tiles of every map cell are merged, tile variants are selected with the
+// seed like the map renderer does. Hidden records still collide, roofs and
+// shadows do not. Map cells without a floor can not be walked on.
+func FromDS1(ds1 *d2ds1.DS1, tiles *d2dt1.TileLibrary, seed uint64) (*Map, error) {
+	if ds1.Width < 1 || ds1.Height < 1 || len(ds1.Tiles) != int(ds1.Height) {
+		return nil, errors.New("invalid DS1 dimensions")
+	}
+
+	m := NewMap(int(ds1.Width)*SubTilesPerTile, int(ds1.Height)*SubTilesPerTile)
+
+	for y := range ds1.Tiles {
+		for x := range ds1.Tiles[y] {
+			m.addCell(&ds1.Tiles[y][x], tiles, x, y, seed)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Map) addCell(record *d2ds1.TileRecord, tiles *d2dt1.TileLibrary, x, y int, seed uint64) {
+	hasFloor := false
+
+	for idx := range record.Floors {
+		floor := &record.Floors[idx]
+		if floor.Prop1 == 0 {
+			continue
+		}
+
+		tile := tiles.Select(int32(d2enum.TileFloor), int32(floor.Style), int32(floor.Sequence),
+			d2dt1.CellSeed(seed, x, y, d2dt1.FloorSeedLayer+idx))
+		if tile == nil {
+			continue
+		}
+
+		hasFloor = true
+
+		m.combineTile(tile, x, y)
+	}
+
+	for idx := range record.Walls {
+		wall := &record.Walls[idx]
+		if wall.Prop1 == 0 || wall.Type == d2enum.TileRoof {
+			continue
+		}
+
+		tile := tiles.Select(int32(wall.Type), int32(wall.Style), int32(wall.Sequence),
+			d2dt1.CellSeed(seed, x, y, d2dt1.WallSeedLayer+idx))
+		if tile == nil {
+			continue
+		}
+
+		m.combineTile(tile, x, y)
+	}
+
+	if hasFloor {
+		return
+	}
+
+	for subY := 0; subY < SubTilesPerTile; subY++ {
+		for subX := 0; subX < SubTilesPerTile; subX++ {
+			m.Combine(x*SubTilesPerTile+subX, y*SubTilesPerTile+subY, d2dt1.SubTileFlags{BlockWalk: true})
+		}
+	}
+}
+
+func (m *Map) combineTile(tile *d2dt1.Tile, x, y int) {
+	for subY := 0; subY < SubTilesPerTile; subY++ {
+		for subX := 0; subX < SubTilesPerTile; subX++ {
+			m.Combine(x*SubTilesPerTile+subX, y*SubTilesPerTile+subY, tile.SubTileFlags[subTileIndex(subX, subY)])
+		}
+	}
+}
+
+// subTileIndex returns the index of a sub-tile in Tile.SubTileFlags, the
+// flags are stored from the bottom row of the tile to the top row
+func subTileIndex(x, y int) int {
+	return (SubTilesPerTile-1-y)*SubTilesPerTile + x
+}
+
+// Width returns the width of the map in sub-tiles
+func (m *Map) Width() int {
+	return m.width
+}
+
+// Height returns the height of the map in sub-tiles
+func (m *Map) Height() int {
+	return m.height
+}
+
+// Contains returns true when the sub-tile is inside of the map
+func (m *Map) Contains(x, y int) bool {
+	return x >= 0 && y >= 0 && x < m.width && y < m.height
+}
+
+// Flags returns the flags of a sub-tile, sub-tiles outside of the map block everything
+func (m *Map) Flags(x, y int) d2dt1.SubTileFlags {
+	if !m.Contains(x, y) {
+		return d2dt1.NewSubTileFlags(0xff) //nolint:gomnd // all flags
+	}
+
+	return m.flags[y*m.width+x]
+}
+
+// Set replaces the flags of a sub-tile, sub-tiles outside of the map are ignored
+func (m *Map) Set(x, y int, flags d2dt1.SubTileFlags) {
+	if m.Contains(x, y) {
+		m.flags[y*m.width+x] = flags
+	}
+}
+
+// Combine merges flags into the flags of a sub-tile, sub-tiles outside of the map are ignored
+func (m *Map) Combine(x, y int, flags d2dt1.SubTileFlags) {
+	if m.Contains(x, y) {
+		m.flags[y*m.width+x].Combine(flags)
+	}
+}
+
+// Walkable returns true when monsters can walk on the sub-tile
+func (m *Map) Walkable(x, y int) bool {
+	return !m.Flags(x, y).BlockWalk
+}
+
+// PlayerWalkable returns true when players can walk on the sub-tile
+func (m *Map) PlayerWalkable(x, y int) bool {
+	flags := m.Flags(x, y)
+
+	return !flags.BlockWalk && !flags.BlockPlayerWalk
+}
+
+// BlocksLOS returns true when the sub-tile blocks the line of sight
+func (m *Map) BlocksLOS(x, y int) bool {
+	return m.Flags(x, y).BlockLOS
+}
+
+// BlocksMissile returns true when missiles collide with the sub-tile. Missiles
+// fly over everything that can be jumped over and that does not block the
+// line of sight.
+func (m *Map) BlocksMissile(x, y int) bool {
+	flags := m.Flags(x, y)
+
+	return flags.BlockLOS || flags.BlockJump
+}
+
+// DebugString returns the map as text, one character per sub-tile:
+//
+//	. walkable
+//	p walkable by monsters only
+//	x blocks walking
+//	o blocks the line of sight only
+//	# blocks walking and the line of sight
+func (m *Map) DebugString() string {
+	var sb strings.Builder
+
+	sb.Grow((m.width + 1) * m.height)
+
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			sb.WriteByte(debugChar(m.flags[y*m.width+x]))
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+func debugChar(flags d2dt1.SubTileFlags) byte {
+	switch {
+	case flags.BlockWalk && flags.BlockLOS:
+		return '#'
+	case flags.BlockWalk:
+		return 'x'
+	case flags.BlockLOS:
+		return 'o'
+	case flags.BlockPlayerWalk:
+		return 'p'
+	default:
+		return '.'
+	}
+}
@@ -0,0 +1,138 @@
+package collision
+
+import (
+	"testing"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+
+	d2ds1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/ds1file"
+	d2dt1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/dt1file"
+)
+
+// testTile creates a tile whose sub-tile flags are given row by row from the
+// top of the tile, using the characters of DebugString
+func testTile(tileType d2enum.TileType, rows ...string) d2dt1.Tile {
+	tile := d2dt1.Tile{Type: int32(tileType)}
+
+	for y, row := range rows {
+		for x := range row {
+			var flags d2dt1.SubTileFlags
+
+			switch row[x] {
+			case '#':
+				flags = d2dt1.SubTileFlags{BlockWalk: true, BlockLOS: true}
+			case 'x':
+				flags = d2dt1.SubTileFlags{BlockWalk: true}
+			case 'o':
+				flags = d2dt1.SubTileFlags{BlockLOS: true}
+			case 'p':
+				flags = d2dt1.SubTileFlags{BlockPlayerWalk: true}
+			case 'j':
+				flags = d2dt1.SubTileFlags{BlockJump: true}
+			}
+
+			tile.SubTileFlags[subTileIndex(x, y)] = flags
+		}
+	}
+
+	return tile
+}
+
+func testMap(t *testing.T) *Map {
+	tiles := d2dt1.NewTileLibrary(&d2dt1.DT1{Tiles: []d2dt1.Tile{
+		testTile(d2enum.TileFloor,
+			".....",
+			".p...",
+			".....",
+			"...j.",
+			"....."),
+		testTile(d2enum.TileLeftWall,
+			"#####",
+			".....",
+			".....",
+			".....",
+			"....o"),
+		testTile(d2enum.TileRoof,
+			"#####",
+			"#####",
+			"#####",
+			"#####",
+			"#####"),
+	}})
+
+	ds1, err := d2ds1.NewDS1(18, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ds1.SetNumberOfWalls(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for x := 0; x < 2; x++ {
+		if err := ds1.SetFloor(x, 0, 0, d2ds1.FloorShadowRecord{Prop1: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ds1.SetWall(1, 0, 0, d2ds1.WallRecord{Prop1: 1, Type: d2enum.TileLeftWall}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ds1.SetWall(0, 0, 1, d2ds1.WallRecord{Prop1: 1, Type: d2enum.TileRoof}); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := FromDS1(ds1, tiles, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func TestFromDS1(t *testing.T) {
+	m := testMap(t)
+
+	expected := "" +
+		".....#####xxxxx\n" +
+		".p....p...xxxxx\n" +
+		"..........xxxxx\n" +
+		"..........xxxxx\n" + // blocking jumps is not shown
+		".........oxxxxx\n"
+
+	if m.Width() != 15 || m.Height() != 5 {
+		t.Fatalf("expected a 15x5 map, got %dx%d", m.Width(), m.Height())
+	}
+
+	if actual := m.DebugString(); actual != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, actual)
+	}
+}
+
+func TestMap_Queries(t *testing.T) {
+	m := testMap(t)
+
+	tests := []struct {
+		name                     string
+		x, y                     int
+		walkable, player         bool
+		blocksLOS, blocksMissile bool
+	}{
+		{"floor", 0, 0, true, true, false, false},
+		{"player blocked", 1, 1, true, false, false, false},
+		{"jump blocked", 3, 3, true, true, false, true},
+		{"wall", 7, 0, false, false, true, true},
+		{"LOS blocked", 9, 4, true, true, true, true},
+		{"no floor", 12, 2, false, false, false, false},
+		{"outside", -1, 0, false, false, true, true},
+	}
+
+	for _, tt := range tests {
+		if m.Walkable(tt.x, tt.y) != tt.walkable || m.PlayerWalkable(tt.x, tt.y) != tt.player ||
+			m.BlocksLOS(tt.x, tt.y) != tt.blocksLOS || m.BlocksMissile(tt.x, tt.y) != tt.blocksMissile {
+			flags := m.Flags(tt.x, tt.y)
+			t.Errorf("%s: unexpected flags %s", tt.name, flags.DebugString())
+		}
+	}
+}
@@ -0,0 +1,3 @@
+// Package collision builds level wide collision maps from the sub-tile flags
+// of the DT1 tiles placed by a DS1 map.
+package collision
@@ -0,0 +1,143 @@
+package collision
+
+import "image"
+
+// LineOfSight tests if the sub-tile to can be seen from the sub-tile from.
+// The sub-tiles on the line between them are visited with Bresenham's
+// algorithm, a sub-tile that blocks the line of sight stops the line and is
+// returned as blocker. The end points do not block, so walls themselves can be
+// seen. A diagonal step between two sub-tiles that block the line of sight is
+// blocked as well, the line can not slip through the gap.
+func (m *Map) LineOfSight(from, to image.Point) (visible bool, blocker image.Point) {
+	visible, blocker = true, to
+
+	WalkLine(from, to, func(p, step image.Point) bool {
+		if step.X != 0 && step.Y != 0 && m.BlocksLOS(p.X+step.X, p.Y) && m.BlocksLOS(p.X, p.Y+step.Y) {
+			visible, blocker = false, image.Point{X: p.X + step.X, Y: p.Y}
+			return false
+		}
+
+		p = p.Add(step)
+
+		if p != to && m.BlocksLOS(p.X, p.Y) {
+			visible, blocker = false, p
+			return false
+		}
+
+		return true
+	})
+
+	return visible, blocker
+}
+
+// WalkLine calls fn for every step along the line from one sub-tile to
+// another with Bresenham's algorithm, until fn returns false. Steps may be
+// diagonal.
+func WalkLine(from, to image.Point, fn func(p, step image.Point) bool) {
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := sign(to.X-from.X), sign(to.Y-from.Y)
+	err := dx + dy
+	p := from
+
+	for p != to {
+		step := image.Point{}
+		e2 := 2 * err //nolint:gomnd // Bresenham
+
+		if e2 >= dy {
+			err += dy
+			step.X = sx
+		}
+
+		if e2 <= dx {
+			err += dx
+			step.Y = sy
+		}
+
+		if !fn(p, step) {
+			return
+		}
+
+		p = p.Add(step)
+	}
+}
+
+// FieldOfView is the set of sub-tiles that can be seen from an origin within a radius
+type FieldOfView struct {
+	Origin  image.Point
+	Radius  int
+	visible []bool
+}
+
+// FOV computes the sub-tiles of the map that can be seen from the origin. A
+// sub-tile is visible when it is inside of the map, at most radius sub-tiles
+// away from the origin and LineOfSight to it succeeds.
+func (m *Map) FOV(origin image.Point, radius int) *FieldOfView {
+	if radius < 0 {
+		radius = 0
+	}
+
+	side := 2*radius + 1
+	fov := &FieldOfView{Origin: origin, Radius: radius, visible: make([]bool, side*side)}
+
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			p := origin.Add(image.Point{X: x, Y: y})
+
+			if x*x+y*y > radius*radius || !m.Contains(p.X, p.Y) {
+				continue
+			}
+
+			if visible, _ := m.LineOfSight(origin, p); visible {
+				fov.visible[(y+radius)*side+x+radius] = true
+			}
+		}
+	}
+
+	return fov
+}
+
+// Visible returns true when the sub-tile is in the field of view
+func (f *FieldOfView) Visible(x, y int) bool {
+	x, y = x-f.Origin.X+f.Radius, y-f.Origin.Y+f.Radius
+	side := 2*f.Radius + 1
+
+	if x < 0 || y < 0 || x >= side || y >= side {
+		return false
+	}
+
+	return f.visible[y*side+x]
+}
+
+// Points returns the visible sub-tiles, row by row
+func (f *FieldOfView) Points() []image.Point {
+	var result []image.Point
+
+	side := 2*f.Radius + 1
+
+	for idx, visible := range f.visible {
+		if visible {
+			result = append(result, f.Origin.Add(image.Point{X: idx%side - f.Radius, Y: idx/side - f.Radius}))
+		}
+	}
+
+	return result
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
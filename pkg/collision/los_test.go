@@ -0,0 +1,130 @@
+package collision
+
+import (
+	"image"
+	"testing"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+
+	d2ds1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/ds1file"
+	d2dt1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/dt1file"
+)
+
+// losMap is three map cells wide, the middle cell has a wall that blocks the
+// line of sight everywhere but on the bottom row of sub-tiles:
+//
+//	.......#.......
+//	.......#.......
+//	.......#.......
+//	.......#.......
+//	...............
+func losMap(t *testing.T) *Map {
+	tiles := d2dt1.NewTileLibrary(&d2dt1.DT1{Tiles: []d2dt1.Tile{
+		testTile(d2enum.TileFloor),
+		testTile(d2enum.TileLeftWall,
+			"..#..",
+			"..#..",
+			"..#..",
+			"..#..",
+			"....."),
+	}})
+
+	ds1, err := d2ds1.NewDS1(18, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for x := 0; x < 3; x++ {
+		if err := ds1.SetFloor(x, 0, 0, d2ds1.FloorShadowRecord{Prop1: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ds1.SetWall(1, 0, 0, d2ds1.WallRecord{Prop1: 1, Type: d2enum.TileLeftWall}); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := FromDS1(ds1, tiles, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func TestMap_LineOfSight(t *testing.T) {
+	m := losMap(t)
+
+	tests := []struct {
+		name     string
+		from, to image.Point
+		visible  bool
+		blocker  image.Point
+	}{
+		{"same sub-tile", image.Pt(2, 2), image.Pt(2, 2), true, image.Pt(2, 2)},
+		{"same side", image.Pt(0, 0), image.Pt(6, 3), true, image.Pt(6, 3)},
+		{"through the wall", image.Pt(2, 2), image.Pt(12, 2), false, image.Pt(7, 2)},
+		{"back through the wall", image.Pt(12, 1), image.Pt(2, 1), false, image.Pt(7, 1)},
+		{"below the wall", image.Pt(0, 4), image.Pt(14, 4), true, image.Pt(14, 4)},
+		{"the wall itself", image.Pt(2, 0), image.Pt(7, 0), true, image.Pt(7, 0)},
+		{"diagonal", image.Pt(5, 0), image.Pt(9, 4), false, image.Pt(7, 2)},
+	}
+
+	for _, tt := range tests {
+		visible, blocker := m.LineOfSight(tt.from, tt.to)
+		if visible != tt.visible || blocker != tt.blocker {
+			t.Errorf("%s: expected %v at %v, got %v at %v", tt.name, tt.visible, tt.blocker, visible, blocker)
+		}
+	}
+}
+
+func TestMap_LineOfSightDiagonalGap(t *testing.T) {
+	m := NewMap(2, 2)
+	m.Set(1, 0, d2dt1.SubTileFlags{BlockLOS: true})
+
+	if visible, _ := m.LineOfSight(image.Pt(0, 0), image.Pt(1, 1)); !visible {
+		t.Error("a single blocking sub-tile should not block a diagonal step")
+	}
+
+	m.Set(0, 1, d2dt1.SubTileFlags{BlockLOS: true})
+
+	visible, blocker := m.LineOfSight(image.Pt(0, 0), image.Pt(1, 1))
+	if visible || blocker != image.Pt(1, 0) {
+		t.Errorf("expected the gap to be blocked by (1,0), got %v at %v", visible, blocker)
+	}
+}
+
+func TestMap_FOV(t *testing.T) {
+	m := losMap(t)
+	fov := m.FOV(image.Pt(4, 2), 5)
+
+	// the wall is visible, the sub-tiles behind it are not
+	expected := "" +
+		"xxxxxxxx.......\n" +
+		"xxxxxxxx.......\n" +
+		"xxxxxxxx.......\n" +
+		"xxxxxxxx.......\n" +
+		"xxxxxxxxx......\n"
+
+	actual := ""
+
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			if fov.Visible(x, y) {
+				actual += "x"
+			} else {
+				actual += "."
+			}
+		}
+
+		actual += "\n"
+	}
+
+	if actual != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, actual)
+	}
+
+	if len(fov.Points()) != 41 {
+		t.Errorf("expected 41 visible sub-tiles, got %d", len(fov.Points()))
+	}
+}
@@ -0,0 +1,324 @@
+package d2dat
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2interface"
+)
+
+const (
+	datSize       = numColors * o
+	actSize       = numColors * o
+	actFooterSize = 4
+	swatchColumns = 16
+
+	jascHeader    = "JASC-PAL"
+	jascVersion   = "0100"
+	gplHeader     = "GIMP Palette"
+	gplNamePrefix = "Name:"
+	gplColsPrefix = "Columns:"
+)
+
+// FromColors creates a palette from up to 256 colors, missing colors are black
+// and the alpha of the colors is ignored
+func FromColors(colors []color.Color) (d2interface.Palette, error) {
+	if len(colors) > numColors {
+		return nil, fmt.Errorf("a palette has at most %d colors, got %d", numColors, len(colors))
+	}
+
+	palette := &DATPalette{}
+
+	for i := range palette.colors {
+		c := &DATColor{}
+
+		if i < len(colors) {
+			if colors[i] == nil {
+				return nil, fmt.Errorf("color %d is nil", i)
+			}
+
+			// not premultiplied, a translucent color keeps its hue and the
+			// alpha is dropped
+			nrgba := color.NRGBAModel.Convert(colors[i]).(color.NRGBA)
+			c.r, c.g, c.b = nrgba.R, nrgba.G, nrgba.B
+		}
+
+		palette.colors[i] = c
+	}
+
+	return palette, nil
+}
+
+// ToColors returns the colors of a palette, the colors are opaque
+func ToColors(palette d2interface.Palette) ([]color.RGBA, error) {
+	result := make([]color.RGBA, palette.NumColors())
+
+	for i := range result {
+		c, err := palette.GetColor(i)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = color.RGBA{R: c.R(), G: c.G(), B: c.B(), A: mask}
+	}
+
+	return result, nil
+}
+
+// Marshal encodes a palette in the DAT format read by Load, 256 BGR triplets
+func Marshal(palette d2interface.Palette) ([]byte, error) {
+	colors, err := ToColors(palette)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, datSize)
+
+	for i := 0; i < numColors && i < len(colors); i++ {
+		data[i*o+b], data[i*o+g], data[i*o+r] = colors[i].B, colors[i].G, colors[i].R
+	}
+
+	return data, nil
+}
+
+// MarshalJASC encodes a palette as a JASC-PAL file, as used by Paint Shop Pro
+func MarshalJASC(palette d2interface.Palette) ([]byte, error) {
+	colors, err := ToColors(palette)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s\r\n%s\r\n%d\r\n", jascHeader, jascVersion, len(colors))
+
+	for _, c := range colors {
+		fmt.Fprintf(&buf, "%d %d %d\r\n", c.R, c.G, c.B)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJASC decodes a JASC-PAL file
+func UnmarshalJASC(data []byte) (d2interface.Palette, error) {
+	lines := readLines(data)
+
+	if len(lines) < 3 || lines[0] != jascHeader { //nolint:gomnd // header lines
+		return nil, errors.New("not a JASC-PAL file")
+	}
+
+	if lines[1] != jascVersion {
+		return nil, fmt.Errorf("unsupported JASC-PAL version %q", lines[1])
+	}
+
+	count, err := strconv.Atoi(lines[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JASC-PAL color count %q", lines[2])
+	}
+
+	lines = lines[3:]
+	if count > len(lines) {
+		return nil, fmt.Errorf("expected %d colors, got %d", count, len(lines))
+	}
+
+	colors := make([]color.Color, 0, count)
+
+	for i := 0; i < count; i++ {
+		c, err := parseRGB(strings.Fields(lines[i]))
+		if err != nil {
+			return nil, fmt.Errorf("color %d: %v", i, err)
+		}
+
+		colors = append(colors, c)
+	}
+
+	return FromColors(colors)
+}
+
+// MarshalGPL encodes a palette as a GIMP palette with the given name
+func MarshalGPL(palette d2interface.Palette, name string) ([]byte, error) {
+	colors, err := ToColors(palette)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s\n%s %s\n%s %d\n#\n", gplHeader, gplNamePrefix, name, gplColsPrefix, swatchColumns)
+
+	for i, c := range colors {
+		fmt.Fprintf(&buf, "%3d %3d %3d\tIndex %d\n", c.R, c.G, c.B, i)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalGPL decodes a GIMP palette, the color names are ignored
+func UnmarshalGPL(data []byte) (d2interface.Palette, error) {
+	lines := readLines(data)
+
+	if len(lines) == 0 || lines[0] != gplHeader {
+		return nil, errors.New("not a GIMP palette")
+	}
+
+	var colors []color.Color
+
+	for i, line := range lines[1:] {
+		if line == "" || strings.HasPrefix(line, "#") ||
+			strings.HasPrefix(line, gplNamePrefix) || strings.HasPrefix(line, gplColsPrefix) {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 { //nolint:gomnd // r, g and b
+			return nil, fmt.Errorf("line %d: expected a color, got %q", i+2, line) //nolint:gomnd // 1-based, after the header
+		}
+
+		c, err := parseRGB(fields[:3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+2, err) //nolint:gomnd // 1-based, after the header
+		}
+
+		colors = append(colors, c)
+	}
+
+	return FromColors(colors)
+}
+
+// MarshalACT encodes a palette as an Adobe color table, 256 RGB triplets
+func MarshalACT(palette d2interface.Palette) ([]byte, error) {
+	colors, err := ToColors(palette)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, actSize)
+
+	for i := 0; i < numColors && i < len(colors); i++ {
+		data[i*o], data[i*o+1], data[i*o+2] = colors[i].R, colors[i].G, colors[i].B
+	}
+
+	return data, nil
+}
+
+// UnmarshalACT decodes an Adobe color table. The optional footer holds the
+// number of colors, colors after it are black.
+func UnmarshalACT(data []byte) (d2interface.Palette, error) {
+	if len(data) != actSize && len(data) != actSize+actFooterSize {
+		return nil, fmt.Errorf("expected %d or %d bytes, got %d", actSize, actSize+actFooterSize, len(data))
+	}
+
+	count := numColors
+
+	if len(data) == actSize+actFooterSize {
+		if n := int(binary.BigEndian.Uint16(data[actSize:])); n > 0 && n < numColors {
+			count = n
+		}
+	}
+
+	colors := make([]color.Color, count)
+
+	for i := range colors {
+		colors[i] = color.RGBA{R: data[i*o], G: data[i*o+1], B: data[i*o+2], A: mask}
+	}
+
+	return FromColors(colors)
+}
+
+// MarshalPNG encodes a palette as a 16x16 swatch, every color is a square
+// of cellSize pixels, colors are ordered row by row
+func MarshalPNG(palette d2interface.Palette, cellSize int) ([]byte, error) {
+	if cellSize < 1 {
+		return nil, fmt.Errorf("invalid cell size %d", cellSize)
+	}
+
+	colors, err := ToColors(palette)
+	if err != nil {
+		return nil, err
+	}
+
+	pngPalette := make(color.Palette, len(colors))
+	for i := range colors {
+		pngPalette[i] = colors[i]
+	}
+
+	size := swatchColumns * cellSize
+	img := image.NewPaletted(image.Rect(0, 0, size, size), pngPalette)
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetColorIndex(x, y, uint8((y/cellSize)*swatchColumns+x/cellSize))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalPNG decodes a 16x16 swatch written by MarshalPNG or drawn by hand,
+// the color of every cell is read from its center
+func UnmarshalPNG(data []byte) (d2interface.Palette, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() < swatchColumns || bounds.Dy() < swatchColumns ||
+		bounds.Dx()%swatchColumns != 0 || bounds.Dy()%swatchColumns != 0 {
+		return nil, fmt.Errorf("expected a swatch of 16x16 cells, got %dx%d pixels", bounds.Dx(), bounds.Dy())
+	}
+
+	cellWidth, cellHeight := bounds.Dx()/swatchColumns, bounds.Dy()/swatchColumns
+	colors := make([]color.Color, numColors)
+
+	for i := range colors {
+		x := bounds.Min.X + (i%swatchColumns)*cellWidth + cellWidth/2   //nolint:gomnd // center
+		y := bounds.Min.Y + (i/swatchColumns)*cellHeight + cellHeight/2 //nolint:gomnd // center
+		colors[i] = img.At(x, y)
+	}
+
+	return FromColors(colors)
+}
+
+func readLines(data []byte) []string {
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+
+	return lines
+}
+
+func parseRGB(fields []string) (color.Color, error) {
+	if len(fields) != 3 { //nolint:gomnd // r, g and b
+		return nil, fmt.Errorf("expected 3 components, got %d", len(fields))
+	}
+
+	var components [3]uint8
+
+	for i, field := range fields {
+		v, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid color component %q", field)
+		}
+
+		components[i] = uint8(v)
+	}
+
+	return color.RGBA{R: components[0], G: components[1], B: components[2], A: mask}, nil
+}
@@ -0,0 +1,174 @@
+package d2dat
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2interface"
+)
+
+func testPalette(t *testing.T) d2interface.Palette {
+	colors := make([]color.Color, numColors)
+
+	for i := range colors {
+		colors[i] = color.RGBA{R: uint8(i), G: uint8(i * 7), B: uint8(255 - i), A: mask}
+	}
+
+	palette, err := FromColors(colors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return palette
+}
+
+func assertSamePalette(t *testing.T, expected, actual d2interface.Palette) {
+	t.Helper()
+
+	expectedColors, err := ToColors(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actualColors, err := ToColors(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testify.Equal(t, expectedColors, actualColors)
+}
+
+func TestMarshal(t *testing.T) {
+	palette := testPalette(t)
+
+	data, err := Marshal(palette)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testify.Len(t, data, datSize)
+	testify.Equal(t, []byte{255 - 1, 7, 1}, data[3:6], "colors are stored as BGR")
+
+	loaded, err := Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertSamePalette(t, palette, loaded)
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	formats := []struct {
+		name      string
+		marshal   func(d2interface.Palette) ([]byte, error)
+		unmarshal func([]byte) (d2interface.Palette, error)
+	}{
+		{"JASC-PAL", MarshalJASC, UnmarshalJASC},
+		{"GPL", func(p d2interface.Palette) ([]byte, error) { return MarshalGPL(p, "test") }, UnmarshalGPL},
+		{"ACT", MarshalACT, UnmarshalACT},
+		{"PNG", func(p d2interface.Palette) ([]byte, error) { return MarshalPNG(p, 4) }, UnmarshalPNG},
+	}
+
+	palette := testPalette(t)
+
+	for _, format := range formats {
+		data, err := format.marshal(palette)
+		if err != nil {
+			t.Errorf("%s: %v", format.name, err)
+			continue
+		}
+
+		converted, err := format.unmarshal(data)
+		if err != nil {
+			t.Errorf("%s: %v", format.name, err)
+			continue
+		}
+
+		assertSamePalette(t, palette, converted)
+	}
+}
+
+func TestUnmarshalGPL_HandWritten(t *testing.T) {
+	assert := testify.New(t)
+	data := strings.Join([]string{
+		"GIMP Palette",
+		"Name: act1",
+		"Columns: 4",
+		"# a comment",
+		"  0   0   0 transparent",
+		"255 128   7\tOrange",
+		"",
+	}, "\n")
+
+	palette, err := UnmarshalGPL([]byte(data))
+	if !assert.NoError(err) {
+		return
+	}
+
+	c, err := palette.GetColor(1)
+	if assert.NoError(err) {
+		assert.Equal([]uint8{255, 128, 7}, []uint8{c.R(), c.G(), c.B()})
+	}
+
+	c, err = palette.GetColor(255)
+	if assert.NoError(err) {
+		assert.Equal(uint32(0), c.RGBA()&0xffffff00, "missing colors are black")
+	}
+}
+
+func TestUnmarshalACT_Footer(t *testing.T) {
+	data := make([]byte, actSize+actFooterSize)
+	data[3], data[4], data[5] = 1, 2, 3
+	data[6] = 0xff
+	data[actSize+1] = 2 // two colors
+
+	palette, err := UnmarshalACT(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := palette.GetColor(1)
+	testify.Equal(t, []uint8{1, 2, 3}, []uint8{c.R(), c.G(), c.B()})
+
+	c, _ = palette.GetColor(2)
+	testify.Equal(t, uint8(0), c.R(), "colors after the count are black")
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	assert := testify.New(t)
+
+	_, err := UnmarshalJASC([]byte("JASC-PAL\r\n0100\r\n2\r\n1 2 3\r\n"))
+	assert.Error(err, "missing color")
+
+	_, err = UnmarshalJASC([]byte("JASC-PAL\r\n0100\r\n1\r\n1 2 300\r\n"))
+	assert.Error(err, "component out of range")
+
+	_, err = UnmarshalGPL([]byte("not a palette"))
+	assert.Error(err)
+
+	_, err = UnmarshalACT(make([]byte, 10))
+	assert.Error(err)
+
+	_, err = FromColors(make([]color.Color, numColors+1))
+	assert.Error(err)
+
+	_, err = FromColors([]color.Color{color.Black, nil})
+	assert.EqualError(err, "color 1 is nil")
+}
+
+func TestFromColors_Translucent(t *testing.T) {
+	palette, err := FromColors([]color.Color{color.NRGBA{R: 200, G: 100, B: 50, A: 128}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	colors, err := ToColors(palette)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testify.Equal(t, color.RGBA{R: 200, G: 100, B: 50, A: mask}, colors[0], "the alpha is dropped, not multiplied in")
+}
@@ -146,6 +146,13 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 		}
 	}
 
+	if ds1.Version < v4 {
+		// old versions always carry exactly one wall, floor and substitution layer
+		ds1.NumberOfWalls = 1
+		ds1.NumberOfFloors = 1
+		ds1.NumberOfSubstitutionLayers = 1
+	}
+
 	layerStream := ds1.setupStreamLayerTypes()
 
 	ds1.Tiles = make([][]TileRecord, ds1.Height)
@@ -153,10 +160,7 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 	for y := range ds1.Tiles {
 		ds1.Tiles[y] = make([]TileRecord, ds1.Width)
 		for x := 0; x < int(ds1.Width); x++ {
-			ds1.Tiles[y][x].Walls = make([]WallRecord, ds1.NumberOfWalls)
-			ds1.Tiles[y][x].Floors = make([]FloorShadowRecord, ds1.NumberOfFloors)
-			ds1.Tiles[y][x].Shadows = make([]FloorShadowRecord, ds1.NumberOfShadowLayers)
-			ds1.Tiles[y][x].Substitutions = make([]SubstitutionRecord, ds1.NumberOfSubstitutionLayers)
+			ds1.Tiles[y][x] = ds1.newTileRecord()
 		}
 	}
 
@@ -420,11 +424,7 @@ func (ds1 *DS1) loadNpcPaths(br *d2datautils.StreamReader, objIdx, numPaths int)
 func (ds1 *DS1) loadLayerStreams(br *d2datautils.StreamReader, layerStream []d2enum.LayerStreamType) error {
 	var err error
 
-	var dirLookup = []int32{
-		0x00, 0x01, 0x02, 0x01, 0x02, 0x03, 0x03, 0x05, 0x05, 0x06,
-		0x06, 0x07, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E,
-		0x0F, 0x10, 0x11, 0x12, 0x14,
-	}
+	dirLookup := getDirLookup()
 
 	for lIdx := range layerStream {
 		layerStreamType := layerStream[lIdx]
@@ -482,3 +482,13 @@ func (ds1 *DS1) loadLayerStreams(br *d2datautils.StreamReader, layerStream []d2e
 
 	return err
 }
+
+// getDirLookup returns the table used to translate the orientation values of
+// DS1 files older than version 7 into tile types.
+func getDirLookup() []int32 {
+	return []int32{
+		0x00, 0x01, 0x02, 0x01, 0x02, 0x03, 0x03, 0x05, 0x05, 0x06,
+		0x06, 0x07, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E,
+		0x0F, 0x10, 0x11, 0x12, 0x14,
+	}
+}
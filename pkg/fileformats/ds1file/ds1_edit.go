@@ -0,0 +1,218 @@
+package d2ds1
+
+import (
+	"fmt"
+)
+
+// NewDS1 creates an empty DS1 of the given version and size (in tiles), with
+// one wall, floor and shadow layer.
+func NewDS1(version, width, height int32) (*DS1, error) {
+	if version < minVersion || version > maxVersion {
+		return nil, fmt.Errorf("unsupported DS1 version %d, expected %d to %d", version, minVersion, maxVersion)
+	}
+
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("invalid DS1 dimensions %dx%d", width, height)
+	}
+
+	ds1 := &DS1{
+		Version:              version,
+		Act:                  1,
+		NumberOfWalls:        1,
+		NumberOfFloors:       1,
+		NumberOfShadowLayers: 1,
+		Objects:              make([]Object, 0),
+		SubstitutionGroups:   make([]SubstitutionGroup, 0),
+	}
+
+	if version >= v3 {
+		ds1.Files = make([]string, 0)
+	}
+
+	if version < v4 {
+		ds1.NumberOfSubstitutionLayers = 1
+	}
+
+	ds1.Resize(width, height)
+
+	return ds1, nil
+}
+
+// Resize changes the size of the map (in tiles). Existing tiles keep their
+// position, tiles outside of the new bounds are dropped and new tiles are empty.
+func (ds1 *DS1) Resize(width, height int32) {
+	tiles := make([][]TileRecord, height)
+
+	for y := range tiles {
+		tiles[y] = make([]TileRecord, width)
+
+		for x := range tiles[y] {
+			if y < len(ds1.Tiles) && x < len(ds1.Tiles[y]) {
+				tiles[y][x] = ds1.Tiles[y][x]
+				continue
+			}
+
+			tiles[y][x] = ds1.newTileRecord()
+		}
+	}
+
+	ds1.Tiles = tiles
+	ds1.Width = width
+	ds1.Height = height
+}
+
+// SetNumberOfWalls changes the number of wall layers of every tile
+func (ds1 *DS1) SetNumberOfWalls(n int32) error {
+	if n < 0 || n > maxWallLayers {
+		return fmt.Errorf("invalid number of wall layers %d, expected 0 to %d", n, maxWallLayers)
+	}
+
+	ds1.NumberOfWalls = n
+
+	for y := range ds1.Tiles {
+		for x := range ds1.Tiles[y] {
+			walls := make([]WallRecord, n)
+			copy(walls, ds1.Tiles[y][x].Walls)
+			ds1.Tiles[y][x].Walls = walls
+		}
+	}
+
+	return nil
+}
+
+// SetNumberOfFloors changes the number of floor layers of every tile
+func (ds1 *DS1) SetNumberOfFloors(n int32) error {
+	if n < 0 || n > maxFloorLayers {
+		return fmt.Errorf("invalid number of floor layers %d, expected 0 to %d", n, maxFloorLayers)
+	}
+
+	ds1.NumberOfFloors = n
+
+	for y := range ds1.Tiles {
+		for x := range ds1.Tiles[y] {
+			floors := make([]FloorShadowRecord, n)
+			copy(floors, ds1.Tiles[y][x].Floors)
+			ds1.Tiles[y][x].Floors = floors
+		}
+	}
+
+	return nil
+}
+
+// SetSubstitutionType sets the substitution type and adds or removes the
+// substitution layer accordingly
+func (ds1 *DS1) SetSubstitutionType(substitutionType int32) {
+	ds1.SubstitutionType = substitutionType
+	ds1.NumberOfSubstitutionLayers = 0
+
+	if substitutionType == subType1 || substitutionType == subType2 {
+		ds1.NumberOfSubstitutionLayers = 1
+	}
+
+	for y := range ds1.Tiles {
+		for x := range ds1.Tiles[y] {
+			substitutions := make([]SubstitutionRecord, ds1.NumberOfSubstitutionLayers)
+			copy(substitutions, ds1.Tiles[y][x].Substitutions)
+			ds1.Tiles[y][x].Substitutions = substitutions
+		}
+	}
+}
+
+// SetFloor sets the floor record of the given layer at the given tile
+func (ds1 *DS1) SetFloor(x, y, layer int, floor FloorShadowRecord) error {
+	tile, err := ds1.tileAt(x, y)
+	if err != nil {
+		return err
+	}
+
+	if layer < 0 || layer >= len(tile.Floors) {
+		return fmt.Errorf("floor layer %d out of range, map has %d floor layers", layer, len(tile.Floors))
+	}
+
+	tile.Floors[layer] = floor
+
+	return nil
+}
+
+// SetWall sets the wall record of the given layer at the given tile
+func (ds1 *DS1) SetWall(x, y, layer int, wall WallRecord) error {
+	tile, err := ds1.tileAt(x, y)
+	if err != nil {
+		return err
+	}
+
+	if layer < 0 || layer >= len(tile.Walls) {
+		return fmt.Errorf("wall layer %d out of range, map has %d wall layers", layer, len(tile.Walls))
+	}
+
+	tile.Walls[layer] = wall
+
+	return nil
+}
+
+// SetShadow sets the shadow record at the given tile
+func (ds1 *DS1) SetShadow(x, y int, shadow FloorShadowRecord) error {
+	tile, err := ds1.tileAt(x, y)
+	if err != nil {
+		return err
+	}
+
+	if len(tile.Shadows) == 0 {
+		return fmt.Errorf("map has no shadow layer")
+	}
+
+	tile.Shadows[0] = shadow
+
+	return nil
+}
+
+// SetSubstitution sets the substitution record at the given tile
+func (ds1 *DS1) SetSubstitution(x, y int, substitution SubstitutionRecord) error {
+	tile, err := ds1.tileAt(x, y)
+	if err != nil {
+		return err
+	}
+
+	if len(tile.Substitutions) == 0 {
+		return fmt.Errorf("map has no substitution layer")
+	}
+
+	tile.Substitutions[0] = substitution
+
+	return nil
+}
+
+// AddObject appends an object to the map and returns its index
+func (ds1 *DS1) AddObject(obj Object) int {
+	ds1.Objects = append(ds1.Objects, obj)
+
+	return len(ds1.Objects) - 1
+}
+
+// RemoveObject removes the object at the given index
+func (ds1 *DS1) RemoveObject(idx int) error {
+	if idx < 0 || idx >= len(ds1.Objects) {
+		return fmt.Errorf("object index %d out of range, map has %d objects", idx, len(ds1.Objects))
+	}
+
+	ds1.Objects = append(ds1.Objects[:idx], ds1.Objects[idx+1:]...)
+
+	return nil
+}
+
+func (ds1 *DS1) tileAt(x, y int) (*TileRecord, error) {
+	if y < 0 || y >= len(ds1.Tiles) || x < 0 || x >= len(ds1.Tiles[y]) {
+		return nil, fmt.Errorf("tile %d,%d is outside of the %dx%d map", x, y, ds1.Width, ds1.Height)
+	}
+
+	return &ds1.Tiles[y][x], nil
+}
+
+func (ds1 *DS1) newTileRecord() TileRecord {
+	return TileRecord{
+		Walls:         make([]WallRecord, ds1.NumberOfWalls),
+		Floors:        make([]FloorShadowRecord, ds1.NumberOfFloors),
+		Shadows:       make([]FloorShadowRecord, ds1.NumberOfShadowLayers),
+		Substitutions: make([]SubstitutionRecord, ds1.NumberOfSubstitutionLayers),
+	}
+}
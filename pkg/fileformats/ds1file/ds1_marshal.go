@@ -0,0 +1,364 @@
+package d2ds1
+
+import (
+	"fmt"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2datautils"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+)
+
+const (
+	minVersion     = v2
+	maxVersion     = v18
+	maxWallLayers  = 4
+	maxFloorLayers = 2
+)
+
+// Marshal encodes the DS1 using the version stored in ds1.Version
+func (ds1 *DS1) Marshal() ([]byte, error) {
+	return ds1.MarshalVersion(ds1.Version)
+}
+
+// MarshalVersion encodes the DS1 for the given target version. An error is
+// returned when the DS1 holds data that the target version cannot store.
+func (ds1 *DS1) MarshalVersion(version int32) ([]byte, error) {
+	if err := ds1.validateVersion(version); err != nil {
+		return nil, err
+	}
+
+	out := *ds1
+	out.Version = version
+	out.setLayerCounts()
+
+	sw := d2datautils.CreateStreamWriter()
+
+	out.encodeHeader(sw)
+
+	if err := out.encodeLayerStreams(sw, out.setupStreamLayerTypes()); err != nil {
+		return nil, err
+	}
+
+	out.encodeObjects(sw)
+	out.encodeSubstitutions(sw)
+	out.encodeNPCs(sw)
+
+	return sw.GetBytes(), nil
+}
+
+// setLayerCounts sets the number of layers LoadDS1 expects for the version.
+// Only the walls of version 4 and later and the floors of version 16 and
+// later are stored in the file, the other counts follow from the version and
+// the substitution type.
+func (ds1 *DS1) setLayerCounts() {
+	ds1.NumberOfShadowLayers = 1
+	ds1.NumberOfSubstitutionLayers = 0
+
+	if ds1.Version >= v10 && (ds1.SubstitutionType == subType1 || ds1.SubstitutionType == subType2) {
+		ds1.NumberOfSubstitutionLayers = 1
+	}
+
+	if ds1.Version < v16 {
+		ds1.NumberOfFloors = 1
+	}
+
+	if ds1.Version < v4 {
+		ds1.NumberOfWalls, ds1.NumberOfFloors, ds1.NumberOfSubstitutionLayers = 1, 1, 1
+	}
+}
+
+// hasSubstitutionData tells whether a tile holds a substitution value
+func (ds1 *DS1) hasSubstitutionData() bool {
+	for y := range ds1.Tiles {
+		for x := range ds1.Tiles[y] {
+			for _, substitution := range ds1.Tiles[y][x].Substitutions {
+				if substitution.Unknown != 0 {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+//nolint:gocyclo // one check per version-dependant feature
+func (ds1 *DS1) validateVersion(version int32) error {
+	if version < minVersion || version > maxVersion {
+		return fmt.Errorf("unsupported DS1 version %d, expected %d to %d", version, minVersion, maxVersion)
+	}
+
+	if ds1.Width < 1 || ds1.Height < 1 || len(ds1.Tiles) != int(ds1.Height) {
+		return fmt.Errorf("invalid DS1 dimensions %dx%d", ds1.Width, ds1.Height)
+	}
+
+	if ds1.NumberOfWalls > maxWallLayers || ds1.NumberOfFloors > maxFloorLayers || ds1.NumberOfShadowLayers > 1 {
+		return fmt.Errorf("too many layers: %d walls, %d floors, %d shadows",
+			ds1.NumberOfWalls, ds1.NumberOfFloors, ds1.NumberOfShadowLayers)
+	}
+
+	// the substitution layer is stored by the versions before 4, and from
+	// version 10 for the substitution types 1 and 2
+	storesSubstitutions := version < v4 ||
+		(version >= v10 && (ds1.SubstitutionType == subType1 || ds1.SubstitutionType == subType2))
+
+	const fmtErr = "DS1 version %d cannot store %s"
+
+	switch {
+	case version < v4 && ds1.NumberOfWalls > 1:
+		return fmt.Errorf(fmtErr, version, "more than one wall layer")
+	case version < v16 && ds1.NumberOfFloors > 1:
+		return fmt.Errorf(fmtErr, version, "more than one floor layer")
+	case !storesSubstitutions && ds1.hasSubstitutionData():
+		return fmt.Errorf(fmtErr, version, "substitution layers")
+	case version < v10 && ds1.SubstitutionType != 0:
+		return fmt.Errorf(fmtErr, version, "a substitution type")
+	case version < v8 && ds1.Act != 1:
+		return fmt.Errorf(fmtErr, version, "an act other than 1")
+	case version < v3 && len(ds1.Files) > 0:
+		return fmt.Errorf(fmtErr, version, "file references")
+	case version < v12 && len(ds1.SubstitutionGroups) > 0:
+		return fmt.Errorf(fmtErr, version, "substitution groups")
+	}
+
+	for idx := range ds1.Objects {
+		obj := &ds1.Objects[idx]
+
+		if version < v14 && len(obj.Paths) > 0 {
+			return fmt.Errorf(fmtErr, version, "NPC paths")
+		}
+
+		for pathIdx := range obj.Paths {
+			if version < v15 && obj.Paths[pathIdx].Action != 0 {
+				return fmt.Errorf(fmtErr, version, "NPC path actions")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ds1 *DS1) encodeHeader(sw *d2datautils.StreamWriter) {
+	sw.PushInt32(ds1.Version)
+	sw.PushInt32(ds1.Width - 1)
+	sw.PushInt32(ds1.Height - 1)
+
+	if ds1.Version >= v8 {
+		sw.PushInt32(ds1.Act - 1)
+	}
+
+	if ds1.Version >= v10 {
+		sw.PushInt32(ds1.SubstitutionType)
+	}
+
+	if ds1.Version >= v3 {
+		sw.PushInt32(int32(len(ds1.Files)))
+
+		for _, file := range ds1.Files {
+			sw.PushBytes([]byte(file)...)
+			sw.PushBytes(0)
+		}
+	}
+
+	if ds1.Version >= v9 && ds1.Version <= v13 {
+		sw.PushBytes(make([]byte, 8)...) //nolint:gomnd // We don't know what's here
+	}
+
+	if ds1.Version >= v4 {
+		sw.PushInt32(ds1.NumberOfWalls)
+
+		if ds1.Version >= v16 {
+			sw.PushInt32(ds1.NumberOfFloors)
+		}
+	}
+}
+
+func (ds1 *DS1) encodeLayerStreams(sw *d2datautils.StreamWriter, layerStream []d2enum.LayerStreamType) error {
+	for _, layerStreamType := range layerStream {
+		for y := 0; y < int(ds1.Height); y++ {
+			if len(ds1.Tiles[y]) != int(ds1.Width) {
+				return fmt.Errorf("tile row %d has %d tiles, expected %d", y, len(ds1.Tiles[y]), ds1.Width)
+			}
+
+			for x := 0; x < int(ds1.Width); x++ {
+				dw, err := ds1.encodeLayerValue(&ds1.Tiles[y][x], layerStreamType)
+				if err != nil {
+					return fmt.Errorf("tile %d,%d: %v", x, y, err)
+				}
+
+				sw.PushUint32(dw)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ds1 *DS1) encodeLayerValue(tile *TileRecord, layerStreamType d2enum.LayerStreamType) (uint32, error) {
+	switch layerStreamType {
+	case d2enum.LayerStreamWall1, d2enum.LayerStreamWall2, d2enum.LayerStreamWall3, d2enum.LayerStreamWall4:
+		wallIndex := int(layerStreamType) - int(d2enum.LayerStreamWall1)
+		if wallIndex >= len(tile.Walls) {
+			return 0, nil
+		}
+
+		return tile.Walls[wallIndex].encode(), nil
+	case d2enum.LayerStreamOrientation1, d2enum.LayerStreamOrientation2,
+		d2enum.LayerStreamOrientation3, d2enum.LayerStreamOrientation4:
+		wallIndex := int(layerStreamType) - int(d2enum.LayerStreamOrientation1)
+		if wallIndex >= len(tile.Walls) {
+			return 0, nil
+		}
+
+		return tile.Walls[wallIndex].encodeOrientation(ds1.Version)
+	case d2enum.LayerStreamFloor1, d2enum.LayerStreamFloor2:
+		floorIndex := int(layerStreamType) - int(d2enum.LayerStreamFloor1)
+		if floorIndex >= len(tile.Floors) {
+			return 0, nil
+		}
+
+		return tile.Floors[floorIndex].encode(), nil
+	case d2enum.LayerStreamShadow:
+		if len(tile.Shadows) == 0 {
+			return 0, nil
+		}
+
+		return tile.Shadows[0].encode(), nil
+	case d2enum.LayerStreamSubstitute:
+		if len(tile.Substitutions) == 0 {
+			return 0, nil
+		}
+
+		return tile.Substitutions[0].Unknown, nil
+	}
+
+	return 0, fmt.Errorf("unknown layer stream type %d", layerStreamType)
+}
+
+func (ds1 *DS1) encodeObjects(sw *d2datautils.StreamWriter) {
+	sw.PushInt32(int32(len(ds1.Objects)))
+
+	for idx := range ds1.Objects {
+		obj := &ds1.Objects[idx]
+
+		sw.PushInt32(int32(obj.Type))
+		sw.PushInt32(int32(obj.ID))
+		sw.PushInt32(int32(obj.X))
+		sw.PushInt32(int32(obj.Y))
+		sw.PushInt32(int32(obj.Flags))
+	}
+}
+
+func (ds1 *DS1) encodeSubstitutions(sw *d2datautils.StreamWriter) {
+	hasSubstitutions := ds1.Version >= v12 && (ds1.SubstitutionType == subType1 || ds1.SubstitutionType == subType2)
+
+	if !hasSubstitutions {
+		return
+	}
+
+	if ds1.Version >= v18 {
+		sw.PushUint32(0)
+	}
+
+	sw.PushInt32(int32(len(ds1.SubstitutionGroups)))
+
+	for _, group := range ds1.SubstitutionGroups {
+		sw.PushInt32(group.TileX)
+		sw.PushInt32(group.TileY)
+		sw.PushInt32(group.WidthInTiles)
+		sw.PushInt32(group.HeightInTiles)
+		sw.PushInt32(group.Unknown)
+	}
+}
+
+func (ds1 *DS1) encodeNPCs(sw *d2datautils.StreamWriter) {
+	if ds1.Version < v14 {
+		return
+	}
+
+	numberOfNpcs := 0
+
+	for idx := range ds1.Objects {
+		if ds1.Objects[idx].Paths != nil {
+			numberOfNpcs++
+		}
+	}
+
+	sw.PushInt32(int32(numberOfNpcs))
+
+	for idx := range ds1.Objects {
+		obj := &ds1.Objects[idx]
+
+		if obj.Paths == nil {
+			continue
+		}
+
+		sw.PushInt32(int32(len(obj.Paths)))
+		sw.PushInt32(int32(obj.X))
+		sw.PushInt32(int32(obj.Y))
+
+		for pathIdx := range obj.Paths {
+			path := &obj.Paths[pathIdx]
+
+			sw.PushInt32(int32(path.Position.X()))
+			sw.PushInt32(int32(path.Position.Y()))
+
+			if ds1.Version >= v15 {
+				sw.PushInt32(int32(path.Action))
+			}
+		}
+	}
+}
+
+//nolint:gomnd // Bitmask
+func (f *FloorShadowRecord) encode() uint32 {
+	dw := uint32(f.Prop1)
+	dw |= uint32(f.Sequence&0x3F) << 8
+	dw |= uint32(f.Unknown1&0x3F) << 14
+	dw |= uint32(f.Style&0x3F) << 20
+	dw |= uint32(f.Unknown2&0x1F) << 26
+
+	if f.Hidden {
+		dw |= 0x80000000
+	}
+
+	return dw
+}
+
+//nolint:gomnd // Bitmask
+func (w *WallRecord) encode() uint32 {
+	dw := uint32(w.Prop1)
+	dw |= uint32(w.Sequence&0x3F) << 8
+	dw |= uint32(w.Unknown1&0x3F) << 14
+	dw |= uint32(w.Style&0x3F) << 20
+	dw |= uint32(w.Unknown2&0x1F) << 26
+
+	if w.Hidden {
+		dw |= 0x80000000
+	}
+
+	return dw
+}
+
+func (w *WallRecord) encodeOrientation(version int32) (uint32, error) {
+	c := int32(w.Type)
+
+	if version < v7 {
+		dirLookup := getDirLookup()
+		found := c >= int32(len(dirLookup))
+
+		for idx, value := range dirLookup {
+			if value == c {
+				c = int32(idx)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return 0, fmt.Errorf("tile type %d cannot be stored in DS1 version %d", w.Type, version)
+		}
+	}
+
+	return uint32(c&0xFF) | uint32(w.Zero)<<8, nil //nolint:gomnd // Bitmask
+}
@@ -0,0 +1,279 @@
+package d2ds1
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2math/d2vector"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2path"
+)
+
+func exampleDS1(t *testing.T, version int32) *DS1 {
+	ds1, err := NewDS1(version, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version >= v3 {
+		ds1.Files = []string{"/d2/data/global/tiles/act1/town/floor.tg1", "/d2/data/global/tiles/act1/town/fence.tg1"}
+	}
+
+	if version >= v4 {
+		if err := ds1.SetNumberOfWalls(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if version >= v8 {
+		ds1.Act = 3
+	}
+
+	if version >= v16 {
+		if err := ds1.SetNumberOfFloors(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if version >= v12 {
+		ds1.SetSubstitutionType(subType2)
+		ds1.SubstitutionGroups = append(ds1.SubstitutionGroups, SubstitutionGroup{
+			TileX: 1, TileY: 1, WidthInTiles: 2, HeightInTiles: 2, Unknown: 7,
+		})
+	}
+
+	for y := 0; y < int(ds1.Height); y++ {
+		for x := 0; x < int(ds1.Width); x++ {
+			floor := FloorShadowRecord{Prop1: byte(x + 1), Sequence: byte(y), Style: 3, Unknown2: 1, Hidden: x == y}
+			if err := ds1.SetFloor(x, y, 0, floor); err != nil {
+				t.Fatal(err)
+			}
+
+			wall := WallRecord{Type: d2enum.TileRightWall, Prop1: 1, Style: byte(x), Sequence: byte(y), Zero: 2}
+			if err := ds1.SetWall(x, y, 0, wall); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ds1.SetShadow(x, y, FloorShadowRecord{Prop1: 1, Unknown1: 5}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if version >= v14 {
+		action := 0
+		if version >= v15 {
+			action = 2
+		}
+
+		ds1.AddObject(Object{Type: 1, ID: 146, X: 5, Y: 6, Flags: 0, Paths: []d2path.Path{
+			{Position: d2vector.NewPosition(5, 6), Action: action},
+			{Position: d2vector.NewPosition(9, 6), Action: action},
+		}})
+	}
+
+	ds1.AddObject(Object{Type: 2, ID: 17, X: 2, Y: 3, Flags: 1})
+
+	return ds1
+}
+
+func TestDS1_MarshalRoundTrip(t *testing.T) {
+	for version := int32(minVersion); version <= maxVersion; version++ {
+		data, err := exampleDS1(t, version).Marshal()
+		if err != nil {
+			t.Fatalf("v%d: %v", version, err)
+		}
+
+		loaded, err := LoadDS1(data)
+		if err != nil {
+			t.Fatalf("v%d: %v", version, err)
+		}
+
+		encoded, err := loaded.Marshal()
+		if err != nil {
+			t.Fatalf("v%d: %v", version, err)
+		}
+
+		if !bytes.Equal(data, encoded) {
+			t.Errorf("v%d: re-encoded data differs from the original", version)
+		}
+
+		reloaded, err := LoadDS1(encoded)
+		if err != nil {
+			t.Fatalf("v%d: %v", version, err)
+		}
+
+		if !reflect.DeepEqual(loaded, reloaded) {
+			t.Errorf("v%d: Load->Save->Load produced a different DS1", version)
+		}
+	}
+}
+
+func TestDS1_MarshalVersion(t *testing.T) {
+	ds1 := exampleDS1(t, v18)
+
+	if _, err := ds1.MarshalVersion(v14); err == nil {
+		t.Error("expected an error when storing two floor layers in version 14")
+	}
+
+	if err := ds1.SetNumberOfFloors(1); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ds1.MarshalVersion(v15)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadDS1(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Version != v15 || loaded.Act != ds1.Act || len(loaded.Objects[0].Paths) != 2 {
+		t.Errorf("unexpected DS1 after converting to version 15: %+v", loaded)
+	}
+}
+
+// sameContent compares what both versions store: the tiles, except for the
+// substitution layer whose presence depends on the version, the files, which
+// version 2 doesn't store and loads as nil, and the objects
+func sameContent(a, b *DS1) bool {
+	if len(a.Files) > 0 || len(b.Files) > 0 {
+		if !reflect.DeepEqual(a.Files, b.Files) {
+			return false
+		}
+	}
+
+	if a.Width != b.Width || a.Height != b.Height || a.Act != b.Act || !reflect.DeepEqual(a.Objects, b.Objects) ||
+		!reflect.DeepEqual(a.SubstitutionGroups, b.SubstitutionGroups) {
+		return false
+	}
+
+	for y := range a.Tiles {
+		for x := range a.Tiles[y] {
+			ta, tb := &a.Tiles[y][x], &b.Tiles[y][x]
+
+			if !reflect.DeepEqual(ta.Walls, tb.Walls) || !reflect.DeepEqual(ta.Floors, tb.Floors) ||
+				!reflect.DeepEqual(ta.Shadows, tb.Shadows) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func marshalAndLoad(t *testing.T, ds1 *DS1, version int32) ([]byte, *DS1) {
+	data, err := ds1.MarshalVersion(version)
+	if err != nil {
+		t.Fatalf("v%d to v%d: %v", ds1.Version, version, err)
+	}
+
+	loaded, err := LoadDS1(data)
+	if err != nil {
+		t.Fatalf("v%d to v%d: %v", ds1.Version, version, err)
+	}
+
+	return data, loaded
+}
+
+func TestDS1_MarshalCrossVersion(t *testing.T) {
+	for from := int32(minVersion); from <= maxVersion; from++ {
+		for to := int32(minVersion); to <= maxVersion; to++ {
+			// the example of the older version holds nothing the newer one
+			// can't store
+			oldest := from
+			if to < oldest {
+				oldest = to
+			}
+
+			original, loaded := marshalAndLoad(t, exampleDS1(t, oldest), from)
+			_, converted := marshalAndLoad(t, loaded, to)
+
+			if converted.Version != to || !sameContent(loaded, converted) {
+				t.Errorf("v%d to v%d: the content changed", from, to)
+			}
+
+			back, _ := marshalAndLoad(t, converted, from)
+			if !bytes.Equal(original, back) {
+				t.Errorf("v%d to v%d and back: the data differs from the original", from, to)
+			}
+		}
+	}
+}
+
+func TestDS1_MarshalVersion_Layers(t *testing.T) {
+	// a v3 DS1 has a substitution layer but no substitution type, the layer
+	// is dropped in version 18 and added back in version 3
+	_, old := marshalAndLoad(t, exampleDS1(t, v3), v3)
+	_, converted := marshalAndLoad(t, old, v18)
+
+	if converted.NumberOfSubstitutionLayers != 0 || converted.NumberOfFloors != 1 || !sameContent(old, converted) {
+		t.Errorf("unexpected DS1 after converting v3 to v18: %+v", converted)
+	}
+
+	old.Tiles[1][2].Substitutions[0].Unknown = 9
+
+	if _, err := old.MarshalVersion(v18); err == nil {
+		t.Error("expected an error when dropping substitution data")
+	}
+
+	// a v18 DS1 stripped of what v7 can't store
+	ds1 := exampleDS1(t, v18)
+	ds1.Act = 1
+	ds1.SetSubstitutionType(0)
+	ds1.SubstitutionGroups = nil
+	ds1.Objects = ds1.Objects[1:]
+
+	if err := ds1.SetNumberOfFloors(1); err != nil {
+		t.Fatal(err)
+	}
+
+	ds1.NumberOfShadowLayers = 0
+
+	_, converted = marshalAndLoad(t, ds1, v7)
+
+	if converted.NumberOfShadowLayers != 1 || converted.NumberOfSubstitutionLayers != 0 || len(converted.Tiles[0][0].Walls) != 2 {
+		t.Errorf("unexpected layers after converting v18 to v7: %+v", converted)
+	}
+
+	if converted.Tiles[2][3].Walls[0] != ds1.Tiles[2][3].Walls[0] || converted.Tiles[2][3].Floors[0] != ds1.Tiles[2][3].Floors[0] {
+		t.Error("the tiles changed when converting v18 to v7")
+	}
+}
+
+func TestDS1_Edit(t *testing.T) {
+	ds1 := exampleDS1(t, v18)
+
+	ds1.Resize(6, 2)
+
+	if ds1.Width != 6 || ds1.Height != 2 || len(ds1.Tiles) != 2 || len(ds1.Tiles[1]) != 6 {
+		t.Fatalf("unexpected size after resize: %dx%d", ds1.Width, ds1.Height)
+	}
+
+	if ds1.Tiles[1][3].Floors[0].Prop1 != 4 || len(ds1.Tiles[1][5].Walls) != 2 {
+		t.Error("resize should keep existing tiles and allocate layers for new ones")
+	}
+
+	if err := ds1.SetFloor(6, 0, 0, FloorShadowRecord{}); err == nil {
+		t.Error("expected an error when setting a tile outside of the map")
+	}
+
+	if err := ds1.SetWall(0, 0, 2, WallRecord{}); err == nil {
+		t.Error("expected an error when setting a missing wall layer")
+	}
+
+	if err := ds1.RemoveObject(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ds1.Objects) != 1 || ds1.Objects[0].ID != 17 {
+		t.Errorf("unexpected objects after removal: %+v", ds1.Objects)
+	}
+
+	if err := ds1.RemoveObject(1); err == nil {
+		t.Error("expected an error when removing a missing object")
+	}
+}
@@ -0,0 +1,124 @@
+package d2dt1
+
+import (
+	"fmt"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2datautils"
+)
+
+const (
+	headerSize      = 4 + 4 + numUnknownHeaderBytes + 4 + 4
+	tileHeaderSize  = 96
+	blockHeaderSize = 20
+
+	isometricBlockLength = blockDataLength
+)
+
+// Marshal encodes the DT1. The block headers and data of every tile are
+// written after all tile headers, the block header pointers, block header
+// sizes, block lengths and file offsets are computed from the blocks.
+func (d *DT1) Marshal() ([]byte, error) {
+	sw := d2datautils.CreateStreamWriter()
+
+	sw.PushInt32(knownMajorVersion)
+	sw.PushInt32(knownMinorVersion)
+	sw.PushBytes(make([]byte, numUnknownHeaderBytes)...)
+	sw.PushInt32(int32(len(d.Tiles)))
+	sw.PushInt32(headerSize)
+
+	pointer := int32(headerSize + tileHeaderSize*len(d.Tiles))
+
+	for idx := range d.Tiles {
+		tile := &d.Tiles[idx]
+
+		if err := tile.validateBlocks(); err != nil {
+			return nil, fmt.Errorf("tile %d: %v", idx, err)
+		}
+
+		size := tile.blocksSize()
+		tile.encodeHeader(sw, pointer, size)
+		pointer += size
+	}
+
+	for idx := range d.Tiles {
+		d.Tiles[idx].encodeBlocks(sw)
+	}
+
+	return sw.GetBytes(), nil
+}
+
+func (t *Tile) validateBlocks() error {
+	for idx := range t.Blocks {
+		block := &t.Blocks[idx]
+
+		if block.Format == BlockFormatIsometric && len(block.EncodedData) != isometricBlockLength {
+			return fmt.Errorf("isometric block %d has %d bytes of data, expected %d",
+				idx, len(block.EncodedData), isometricBlockLength)
+		}
+
+		if block.Format != BlockFormatIsometric && len(block.EncodedData) == 0 {
+			return fmt.Errorf("RLE block %d has no data", idx)
+		}
+	}
+
+	return nil
+}
+
+// blocksSize returns the size of the block headers and the block data of the tile
+func (t *Tile) blocksSize() int32 {
+	size := int32(blockHeaderSize * len(t.Blocks))
+
+	for idx := range t.Blocks {
+		size += int32(len(t.Blocks[idx].EncodedData))
+	}
+
+	return size
+}
+
+func (t *Tile) encodeHeader(sw *d2datautils.StreamWriter, blocksPointer, blocksSize int32) {
+	sw.PushInt32(t.Direction)
+	sw.PushInt16(t.RoofHeight)
+	sw.PushUint16(t.MaterialFlags.Encode())
+	sw.PushInt32(t.Height)
+	sw.PushInt32(t.Width)
+	sw.PushBytes(make([]byte, numUnknownTileBytes1)...)
+	sw.PushInt32(t.Type)
+	sw.PushInt32(t.Style)
+	sw.PushInt32(t.Sequence)
+	sw.PushInt32(t.RarityFrameIndex)
+	sw.PushBytes(make([]byte, numUnknownTileBytes2)...)
+
+	for idx := range t.SubTileFlags {
+		sw.PushBytes(t.SubTileFlags[idx].Encode())
+	}
+
+	sw.PushBytes(make([]byte, numUnknownTileBytes3)...)
+	sw.PushInt32(blocksPointer)
+	sw.PushInt32(blocksSize)
+	sw.PushInt32(int32(len(t.Blocks)))
+	sw.PushBytes(make([]byte, numUnknownTileBytes4)...)
+}
+
+func (t *Tile) encodeBlocks(sw *d2datautils.StreamWriter) {
+	offset := int32(blockHeaderSize * len(t.Blocks))
+
+	for idx := range t.Blocks {
+		block := &t.Blocks[idx]
+		length := int32(len(block.EncodedData))
+
+		sw.PushInt16(block.X)
+		sw.PushInt16(block.Y)
+		sw.PushBytes(0, 0)
+		sw.PushBytes(block.GridX, block.GridY)
+		sw.PushInt16(int16(block.Format))
+		sw.PushInt32(length)
+		sw.PushBytes(0, 0)
+		sw.PushInt32(offset)
+
+		offset += length
+	}
+
+	for idx := range t.Blocks {
+		sw.PushBytes(t.Blocks[idx].EncodedData...)
+	}
+}
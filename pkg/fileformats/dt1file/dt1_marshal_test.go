@@ -0,0 +1,194 @@
+package d2dt1
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+const (
+	testWallWidth  = 160
+	testWallHeight = 100
+)
+
+func testPalette() color.Palette {
+	return color.Palette{
+		color.RGBA{},
+		color.RGBA{R: 0xff, A: 0xff},
+		color.RGBA{G: 0xff, A: 0xff},
+		color.RGBA{B: 0xff, A: 0xff},
+	}
+}
+
+// testFloorImage is a diamond with a different color in each quarter
+func testFloorImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, floorTileWidth, floorTileWidth/2))
+	palette := testPalette()
+
+	for y := 0; y < floorTileWidth/2; y++ {
+		for x := 0; x < floorTileWidth; x++ {
+			dx, dy := abs(x-floorTileWidth/2), abs(y-floorTileWidth/4)
+			if dx+2*dy >= floorTileWidth/2 {
+				continue
+			}
+
+			img.Set(x, y, palette[1+(x/(floorTileWidth/2)+y/(floorTileWidth/4))%3])
+		}
+	}
+
+	return img
+}
+
+// testWallImage is an indexed image with transparent holes and long runs
+func testWallImage() []byte {
+	pixels := make([]byte, testWallWidth*testWallHeight)
+
+	for y := 0; y < testWallHeight; y++ {
+		for x := 0; x < testWallWidth; x++ {
+			if (x+y)%7 != 0 && x > y/2 {
+				pixels[y*testWallWidth+x] = byte(1 + (x/10)%3)
+			}
+		}
+	}
+
+	return pixels
+}
+
+func testDT1() *DT1 {
+	floorPixels, width, _ := ImageToIndexed(testFloorImage(), testPalette())
+
+	floor := Tile{
+		Width:            floorTileWidth,
+		Height:           floorTileWidth / 2,
+		Style:            3,
+		Sequence:         1,
+		RarityFrameIndex: 2,
+		MaterialFlags:    NewMaterialFlags(0x0421),
+		Blocks:           EncodeFloor(floorPixels, width),
+	}
+
+	for idx := range floor.SubTileFlags {
+		floor.SubTileFlags[idx] = NewSubTileFlags(byte(idx * 9))
+	}
+
+	wall := Tile{
+		Direction:  1,
+		RoofHeight: 12,
+		Width:      testWallWidth,
+		Height:     -testWallHeight,
+		Type:       1,
+		Blocks:     EncodeWall(testWallImage(), testWallWidth, testWallHeight),
+	}
+
+	return &DT1{Tiles: []Tile{floor, wall}}
+}
+
+func TestDT1_MarshalRoundTrip(t *testing.T) {
+	assert := testify.New(t)
+	dt1 := testDT1()
+
+	data, err := dt1.Marshal()
+	if !assert.NoError(err) {
+		return
+	}
+
+	loaded, err := LoadDT1(data)
+	if !assert.NoError(err) || !assert.Len(loaded.Tiles, len(dt1.Tiles)) {
+		return
+	}
+
+	for idx := range dt1.Tiles {
+		expected, actual := dt1.Tiles[idx], loaded.Tiles[idx]
+
+		assert.NotZero(actual.blockHeaderPointer)
+		assert.Equal(actual.blocksSize(), actual.blockHeaderSize)
+
+		for blockIdx := range actual.Blocks {
+			actual.Blocks[blockIdx].FileOffset = 0
+		}
+
+		actual.blockHeaderPointer, actual.blockHeaderSize = 0, 0
+		assert.Equal(expected, actual)
+	}
+
+	again, err := loaded.Marshal()
+	if assert.NoError(err) {
+		assert.Equal(data, again)
+	}
+}
+
+func TestDT1_MarshalInvalidBlock(t *testing.T) {
+	dt1 := testDT1()
+	dt1.Tiles[0].Blocks[0].EncodedData = dt1.Tiles[0].Blocks[0].EncodedData[:10]
+
+	_, err := dt1.Marshal()
+	testify.Error(t, err)
+}
+
+func TestEncodeFloor(t *testing.T) {
+	assert := testify.New(t)
+	pixels, width, height := ImageToIndexed(testFloorImage(), testPalette())
+
+	blocks := EncodeFloor(pixels, width)
+	assert.Len(blocks, subTilesPerSide*subTilesPerSide)
+
+	actual := make([]byte, width*height)
+	DecodeTileGfxData(blocks, &actual, 0, int32(width))
+
+	// the blocks only cover the isometric diamond of the tile
+	coverage := make([]byte, width*height)
+	DecodeTileGfxData(EncodeFloor(bytes.Repeat([]byte{1}, width*height), width), &coverage, 0, int32(width))
+
+	expected := make([]byte, width*height)
+	for idx := range pixels {
+		expected[idx] = pixels[idx] * coverage[idx]
+	}
+
+	assert.Equal(expected, actual)
+	assert.Greater(width*height-bytes.Count(actual, []byte{0}), width*height/3)
+}
+
+func TestEncodeWall(t *testing.T) {
+	assert := testify.New(t)
+	expected := testWallImage()
+
+	blocks := EncodeWall(expected, testWallWidth, testWallHeight)
+
+	top := 0
+	for _, block := range blocks {
+		assert.Equal(BlockFormatRLE, block.Format)
+		assert.LessOrEqual(int(block.Y)+rleBlockSize, 0)
+
+		if int(block.Y) < top {
+			top = int(block.Y)
+		}
+	}
+
+	actual := make([]byte, testWallWidth*-top)
+	DecodeTileGfxData(blocks, &actual, int32(-top), testWallWidth)
+
+	assert.Equal(expected, actual[len(actual)-len(expected):])
+}
+
+func TestFlagsEncode(t *testing.T) {
+	assert := testify.New(t)
+
+	for data := 0; data < 256; data++ {
+		flags := NewSubTileFlags(byte(data))
+		assert.Equal(byte(data), flags.Encode())
+	}
+
+	const allMaterials = 0x05ff
+	assert.Equal(uint16(allMaterials), NewMaterialFlags(allMaterials).Encode())
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
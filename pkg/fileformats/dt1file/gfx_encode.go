@@ -0,0 +1,190 @@
+package d2dt1
+
+import (
+	"image"
+	"image/color"
+)
+
+const (
+	subTilesPerSide      = 5
+	isometricBlockWidth  = 32
+	isometricBlockHeight = 15
+	floorTileWidth       = 160
+	rleBlockSize         = 32
+	maxRunLength         = 255
+	transparentIndex     = 0
+	opaqueThreshold      = 0x8000
+)
+
+// ImageToIndexed converts an image to palette indices. Paletted images keep
+// their indices, other images are mapped to the closest color of the palette.
+// Index 0 is transparent, it is used for transparent pixels only.
+func ImageToIndexed(img image.Image, palette color.Palette) (pixels []byte, width, height int) {
+	bounds := img.Bounds()
+	width, height = bounds.Dx(), bounds.Dy()
+	pixels = make([]byte, width*height)
+
+	if paletted, ok := img.(*image.Paletted); ok {
+		for y := 0; y < height; y++ {
+			copy(pixels[y*width:(y+1)*width], paletted.Pix[paletted.PixOffset(bounds.Min.X, bounds.Min.Y+y):])
+		}
+
+		return pixels, width, height
+	}
+
+	var opaquePalette color.Palette
+	if len(palette) > 1 {
+		opaquePalette = palette[1:]
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := img.At(bounds.Min.X+x, bounds.Min.Y+y)
+
+			if _, _, _, a := c.RGBA(); a < opaqueThreshold || opaquePalette == nil {
+				continue
+			}
+
+			pixels[y*width+x] = byte(opaquePalette.Index(c) + 1)
+		}
+	}
+
+	return pixels, width, height
+}
+
+// EncodeFloor encodes a 160x80 indexed floor image into the 25 isometric
+// blocks of a floor tile, the blocks are placed like the blocks of the
+// original floor tiles
+func EncodeFloor(pixels []byte, width int) []Block {
+	blocks := make([]Block, 0, subTilesPerSide*subTilesPerSide)
+
+	for gridY := 0; gridY < subTilesPerSide; gridY++ {
+		for gridX := 0; gridX < subTilesPerSide; gridX++ {
+			x := (floorTileWidth-isometricBlockWidth)/2 + (gridX-gridY)*isometricBlockWidth/2
+			y := (gridX + gridY) * (isometricBlockHeight + 1) / 2
+
+			blocks = append(blocks, Block{
+				X:           int16(x),
+				Y:           int16(y),
+				GridX:       byte(gridX),
+				GridY:       byte(gridY),
+				Format:      BlockFormatIsometric,
+				EncodedData: EncodeIsometricBlock(pixels, width, x, y),
+				Length:      isometricBlockLength,
+			})
+		}
+	}
+
+	return blocks
+}
+
+// EncodeIsometricBlock encodes the diamond shaped area of an indexed image
+// whose bounding box starts at x, y. Pixels outside of the image are transparent.
+func EncodeIsometricBlock(pixels []byte, width, x, y int) []byte {
+	xjump := []int{14, 12, 10, 8, 6, 4, 2, 0, 2, 4, 6, 8, 10, 12, 14}
+	nbpix := []int{4, 8, 12, 16, 20, 24, 28, 32, 28, 24, 20, 16, 12, 8, 4}
+	data := make([]byte, 0, isometricBlockLength)
+
+	for row := range nbpix {
+		for col := xjump[row]; col < xjump[row]+nbpix[row]; col++ {
+			data = append(data, pixelAt(pixels, width, x+col, y+row))
+		}
+	}
+
+	return data
+}
+
+// EncodeWall encodes an indexed image into RLE blocks of 32x32 pixels. The
+// bottom of the image is placed at y = 0, like the blocks of the original
+// wall tiles. Blocks without opaque pixels are left out.
+func EncodeWall(pixels []byte, width, height int) []Block {
+	var blocks []Block
+
+	rows := (height + rleBlockSize - 1) / rleBlockSize
+	top := height - rows*rleBlockSize
+
+	for row := 0; row < rows; row++ {
+		for x := 0; x < width; x += rleBlockSize {
+			y := top + row*rleBlockSize
+			w := min(rleBlockSize, width-x)
+
+			data := EncodeRLEBlock(pixels, width, x, y, w, rleBlockSize)
+			if data == nil {
+				continue
+			}
+
+			blocks = append(blocks, Block{
+				X:           int16(x),
+				Y:           int16(y - height),
+				GridX:       byte(x / rleBlockSize),
+				GridY:       byte(row),
+				Format:      BlockFormatRLE,
+				EncodedData: data,
+				Length:      int32(len(data)),
+			})
+		}
+	}
+
+	return blocks
+}
+
+// EncodeRLEBlock run length encodes an area of an indexed image, index 0 is
+// transparent. Returns nil when the area has no opaque pixels.
+func EncodeRLEBlock(pixels []byte, width, x, y, w, h int) []byte {
+	var data []byte
+
+	opaque := false
+
+	for row := 0; row < h; row++ {
+		col := 0
+
+		for col < w {
+			skip := 0
+			for col < w && skip < maxRunLength && pixelAt(pixels, width, x+col, y+row) == transparentIndex {
+				skip++
+				col++
+			}
+
+			start := col
+			for col < w && col-start < maxRunLength && pixelAt(pixels, width, x+col, y+row) != transparentIndex {
+				col++
+			}
+
+			if col == start && col == w {
+				// only transparent pixels are left in this row
+				break
+			}
+
+			data = append(data, byte(skip), byte(col-start))
+			for idx := start; idx < col; idx++ {
+				data = append(data, pixelAt(pixels, width, x+idx, y+row))
+			}
+
+			opaque = opaque || col > start
+		}
+
+		data = append(data, 0, 0)
+	}
+
+	if !opaque {
+		return nil
+	}
+
+	return data
+}
+
+func pixelAt(pixels []byte, width, x, y int) byte {
+	if x < 0 || y < 0 || x >= width || y*width+x >= len(pixels) {
+		return transparentIndex
+	}
+
+	return pixels[y*width+x]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,127 @@
+package d2dt1
+
+// TileKey identifies the variants of a tile, DS1 records reference tiles by these values
+type TileKey struct {
+	Type     int32
+	Style    int32
+	Sequence int32
+}
+
+// TileLibrary indexes the tiles of one or more DT1 files by type, style and sequence
+type TileLibrary struct {
+	variants map[TileKey][]*Tile
+}
+
+// NewTileLibrary creates a tile library containing the tiles of the given DT1 files
+func NewTileLibrary(dt1s ...*DT1) *TileLibrary {
+	library := &TileLibrary{variants: make(map[TileKey][]*Tile)}
+
+	for _, dt1 := range dt1s {
+		library.Add(dt1)
+	}
+
+	return library
+}
+
+// Add merges the tiles of a DT1 file into the library. Variants keep the order
+// in which they were added, which keeps the random selection stable.
+func (l *TileLibrary) Add(dt1 *DT1) {
+	for idx := range dt1.Tiles {
+		tile := &dt1.Tiles[idx]
+		key := TileKey{Type: tile.Type, Style: tile.Style, Sequence: tile.Sequence}
+		l.variants[key] = append(l.variants[key], tile)
+	}
+}
+
+// Len returns the number of distinct tiles (type, style and sequence) in the library
+func (l *TileLibrary) Len() int {
+	return len(l.variants)
+}
+
+// Variants returns all tiles with the given type, style and sequence
+func (l *TileLibrary) Variants(tileType, style, sequence int32) []*Tile {
+	return l.variants[TileKey{Type: tileType, Style: style, Sequence: sequence}]
+}
+
+// Lookup returns the variant with the given rarity/frame index, or nil when
+// there is no such tile
+func (l *TileLibrary) Lookup(tileType, style, sequence, rarityFrameIndex int32) *Tile {
+	for _, tile := range l.Variants(tileType, style, sequence) {
+		if tile.RarityFrameIndex == rarityFrameIndex {
+			return tile
+		}
+	}
+
+	return nil
+}
+
+// Select picks one of the variants with the given type, style and sequence.
+// The rarity of a variant is its weight, so a variant with a rarity of 2 is
+// picked twice as often as one with a rarity of 1, variants with a rarity of 0
+// are only used when all variants have a rarity of 0. The result only depends
+// on the seed and the tiles in the library. Returns nil when there is no such tile.
+func (l *TileLibrary) Select(tileType, style, sequence int32, seed uint64) *Tile {
+	variants := l.Variants(tileType, style, sequence)
+	if len(variants) == 0 {
+		return nil
+	}
+
+	var totalWeight uint64
+
+	for _, tile := range variants {
+		if tile.RarityFrameIndex > 0 {
+			totalWeight += uint64(tile.RarityFrameIndex)
+		}
+	}
+
+	if totalWeight == 0 {
+		return variants[0]
+	}
+
+	random := splitMix64(seed) % totalWeight
+
+	for _, tile := range variants {
+		if tile.RarityFrameIndex <= 0 {
+			continue
+		}
+
+		weight := uint64(tile.RarityFrameIndex)
+		if random < weight {
+			return tile
+		}
+
+		random -= weight
+	}
+
+	return variants[0]
+}
+
+// Layers of the records of a map cell, used with CellSeed so that renderers
+// and collision maps select the same variants
+const (
+	FloorSeedLayer  = 0
+	ShadowSeedLayer = 8
+	WallSeedLayer   = 16
+)
+
+// CellSeed derives the seed of a map cell from a map seed, so that every cell
+// and layer of a map gets its own, reproducible selection
+func CellSeed(seed uint64, x, y, layer int) uint64 {
+	const (
+		primeX     = 0x9E3779B185EBCA87
+		primeY     = 0xC2B2AE3D27D4EB4F
+		primeLayer = 0x165667B19E3779F9
+	)
+
+	return splitMix64(seed ^ uint64(x)*primeX ^ uint64(y)*primeY ^ uint64(layer)*primeLayer)
+}
+
+// splitMix64 is a small, well distributed hash. It is used instead of
+// math/rand so that the selection never depends on the Go version or platform.
+func splitMix64(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+
+	return x ^ (x >> 31)
+}
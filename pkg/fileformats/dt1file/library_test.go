@@ -0,0 +1,70 @@
+package d2dt1
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func testLibrary() *TileLibrary {
+	first := &DT1{Tiles: []Tile{
+		{Type: 0, Style: 1, Sequence: 2, RarityFrameIndex: 1},
+		{Type: 0, Style: 1, Sequence: 2, RarityFrameIndex: 3},
+		{Type: 1, Style: 0, Sequence: 0, RarityFrameIndex: 0},
+	}}
+	second := &DT1{Tiles: []Tile{
+		{Type: 0, Style: 1, Sequence: 2, RarityFrameIndex: 0},
+		{Type: 1, Style: 0, Sequence: 0, RarityFrameIndex: 0},
+	}}
+
+	return NewTileLibrary(first, second)
+}
+
+func TestTileLibrary_Lookup(t *testing.T) {
+	assert := testify.New(t)
+	library := testLibrary()
+
+	assert.Equal(2, library.Len())
+	assert.Len(library.Variants(0, 1, 2), 3)
+	assert.Len(library.Variants(1, 0, 0), 2)
+	assert.Empty(library.Variants(2, 0, 0))
+
+	tile := library.Lookup(0, 1, 2, 3)
+	if assert.NotNil(tile) {
+		assert.Equal(int32(3), tile.RarityFrameIndex)
+	}
+
+	assert.Nil(library.Lookup(0, 1, 2, 7))
+}
+
+func TestTileLibrary_Select(t *testing.T) {
+	assert := testify.New(t)
+	library := testLibrary()
+	counts := make(map[int32]int)
+
+	const samples = 4000
+
+	for seed := uint64(0); seed < samples; seed++ {
+		tile := library.Select(0, 1, 2, seed)
+		assert.Same(tile, library.Select(0, 1, 2, seed), "selection must be deterministic")
+
+		counts[tile.RarityFrameIndex]++
+	}
+
+	assert.Zero(counts[0], "variants with a rarity of 0 should not be picked")
+	assert.InDelta(samples/4, counts[1], samples/20)
+	assert.InDelta(samples*3/4, counts[3], samples/20)
+
+	// all variants have a rarity of 0, the first one is used
+	assert.Same(library.Variants(1, 0, 0)[0], library.Select(1, 0, 0, 42))
+	assert.Nil(library.Select(5, 5, 5, 42))
+}
+
+func TestCellSeed(t *testing.T) {
+	assert := testify.New(t)
+
+	assert.Equal(CellSeed(1, 2, 3, 0), CellSeed(1, 2, 3, 0))
+	assert.NotEqual(CellSeed(1, 2, 3, 0), CellSeed(1, 3, 2, 0))
+	assert.NotEqual(CellSeed(1, 2, 3, 0), CellSeed(1, 2, 3, 1))
+	assert.NotEqual(CellSeed(1, 2, 3, 0), CellSeed(2, 2, 3, 0))
+}
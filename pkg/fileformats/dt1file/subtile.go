@@ -77,3 +77,20 @@ func NewSubTileFlags(data byte) SubTileFlags {
 		Unknown3:        data&128 == 128,
 	}
 }
+
+// Encode returns the binary representation of the sub-tile flags
+//nolint:gomnd // binary flags
+func (s *SubTileFlags) Encode() byte {
+	var data byte
+
+	for bit, set := range [...]bool{
+		s.BlockWalk, s.BlockLOS, s.BlockJump, s.BlockPlayerWalk,
+		s.Unknown1, s.BlockLight, s.Unknown2, s.Unknown3,
+	} {
+		if set {
+			data |= 1 << bit
+		}
+	}
+
+	return data
+}
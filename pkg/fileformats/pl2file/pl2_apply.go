@@ -0,0 +1,156 @@
+package d2pl2
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// TransformType selects one of the transform tables of a PL2
+type TransformType int
+
+// Transform types. Remap transforms map every source index through one table,
+// blend transforms combine a source index with the index already in the
+// destination.
+const (
+	// TransformLightLevel remaps through LightLevelVariations, index is the light level 0-31
+	TransformLightLevel TransformType = iota
+	// TransformInvColor remaps through InvColorVariations, index is 0-15
+	TransformInvColor
+	// TransformSelectedUnit remaps through SelectedUintShift
+	TransformSelectedUnit
+	// TransformAlphaBlend blends through AlphaBlend, index is the blend level 0-2 (25%, 50% and 75%)
+	TransformAlphaBlend
+	// TransformAdditiveBlend blends through AdditiveBlend
+	TransformAdditiveBlend
+	// TransformMultiplicativeBlend blends through MultiplicativeBlend
+	TransformMultiplicativeBlend
+	// TransformHue remaps through HueVariations, index is 0-110
+	TransformHue
+	// TransformRedTones remaps through RedTones
+	TransformRedTones
+	// TransformGreenTones remaps through GreenTones
+	TransformGreenTones
+	// TransformBlueTones remaps through BlueTones
+	TransformBlueTones
+	// TransformMaxComponentBlend blends through MaxComponentBlend
+	TransformMaxComponentBlend
+	// TransformDarkenedColorShift remaps through DarkendColorShift
+	TransformDarkenedColorShift
+	// TransformTextColor remaps through TextColorShifts, index is the text color 0-12
+	TransformTextColor
+)
+
+// Remap maps every palette index of src through the transform into dst
+func (t *PL2PaletteTransform) Remap(dst, src []byte) {
+	for idx, c := range src {
+		dst[idx] = t.Indices[c]
+	}
+}
+
+// Apply runs an indexed image through a transform of the PL2. Remap transforms
+// overwrite dst with the remapped indices of src. Blend transforms combine the
+// indices of src with the indices of dst, index 0 of src is transparent and
+// leaves dst unchanged. dst and src may be the same slice for remap transforms.
+func (p *PL2) Apply(transformType TransformType, index int, dst, src []byte) error {
+	if len(dst) != len(src) {
+		return fmt.Errorf("destination has %d pixels, source has %d", len(dst), len(src))
+	}
+
+	if blend := p.blendTable(transformType, index); blend != nil {
+		for idx, c := range src {
+			if c != 0 {
+				dst[idx] = blend[c].Indices[dst[idx]]
+			}
+		}
+
+		return nil
+	}
+
+	transform, err := p.remapTable(transformType, index)
+	if err != nil {
+		return err
+	}
+
+	transform.Remap(dst, src)
+
+	return nil
+}
+
+// blendTable returns the table of a blend transform, indexed by the source index
+func (p *PL2) blendTable(transformType TransformType, index int) *[256]PL2PaletteTransform {
+	switch transformType {
+	case TransformAlphaBlend:
+		if index >= 0 && index < len(p.AlphaBlend) {
+			return &p.AlphaBlend[index]
+		}
+	case TransformAdditiveBlend:
+		return &p.AdditiveBlend
+	case TransformMultiplicativeBlend:
+		return &p.MultiplicativeBlend
+	case TransformMaxComponentBlend:
+		return &p.MaxComponentBlend
+	}
+
+	return nil
+}
+
+func (p *PL2) remapTable(transformType TransformType, index int) (*PL2PaletteTransform, error) {
+	var table []PL2PaletteTransform
+
+	switch transformType {
+	case TransformLightLevel:
+		table = p.LightLevelVariations[:]
+	case TransformInvColor:
+		table = p.InvColorVariations[:]
+	case TransformHue:
+		table = p.HueVariations[:]
+	case TransformTextColor:
+		table = p.TextColorShifts[:]
+	case TransformSelectedUnit:
+		return &p.SelectedUintShift, nil
+	case TransformRedTones:
+		return &p.RedTones, nil
+	case TransformGreenTones:
+		return &p.GreenTones, nil
+	case TransformBlueTones:
+		return &p.BlueTones, nil
+	case TransformDarkenedColorShift:
+		return &p.DarkendColorShift, nil
+	case TransformAlphaBlend:
+		return nil, fmt.Errorf("alpha blend level %d out of range 0-%d", index, len(p.AlphaBlend)-1)
+	default:
+		return nil, fmt.Errorf("unknown transform type %d", transformType)
+	}
+
+	if index < 0 || index >= len(table) {
+		return nil, fmt.Errorf("transform index %d out of range 0-%d", index, len(table)-1)
+	}
+
+	return &table[index], nil
+}
+
+// ToRGBA converts an indexed image to RGBA using the palette, index 0 is
+// transparent. The pixels are read row by row, a partial last row is dropped
+// and a width below 1 gives an empty image.
+func (p *PL2Palette) ToRGBA(pixels []byte, width int) *image.RGBA {
+	const opaque = 0xff
+
+	if width < 1 {
+		return image.NewRGBA(image.Rectangle{})
+	}
+
+	height := len(pixels) / width
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for idx, c := range pixels[:width*height] {
+		if c == 0 {
+			continue
+		}
+
+		pc := p.Colors[c]
+		img.SetRGBA(idx%width, idx/width, color.RGBA{R: pc.R, G: pc.G, B: pc.B, A: opaque})
+	}
+
+	return img
+}
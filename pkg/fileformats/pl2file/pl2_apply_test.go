@@ -0,0 +1,98 @@
+package d2pl2
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func testPL2() *PL2 {
+	p := &PL2{}
+
+	for i := 0; i < 256; i++ {
+		p.BasePalette.Colors[i] = PL2Color{R: uint8(i), G: uint8(255 - i), B: 7}
+		p.LightLevelVariations[3].Indices[i] = uint8(i / 2)
+		p.HueVariations[110].Indices[i] = uint8(255 - i)
+		p.TextColorShifts[4].Indices[i] = 4
+		p.RedTones.Indices[i] = 1
+
+		for j := 0; j < 256; j++ {
+			// blends of source i and destination j
+			p.AlphaBlend[1][i].Indices[j] = uint8((i + j) / 2)
+			p.AdditiveBlend[i].Indices[j] = uint8(min(i+j, 255))
+		}
+	}
+
+	return p
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func TestPL2_ApplyRemap(t *testing.T) {
+	assert := testify.New(t)
+	p := testPL2()
+	src := []byte{0, 10, 20, 255}
+	dst := make([]byte, len(src))
+
+	assert.NoError(p.Apply(TransformLightLevel, 3, dst, src))
+	assert.Equal([]byte{0, 5, 10, 127}, dst)
+
+	assert.NoError(p.Apply(TransformHue, 110, dst, src))
+	assert.Equal([]byte{255, 245, 235, 0}, dst)
+
+	assert.NoError(p.Apply(TransformTextColor, 4, dst, src))
+	assert.Equal([]byte{4, 4, 4, 4}, dst)
+
+	assert.NoError(p.Apply(TransformRedTones, 0, src, src), "remapping in place")
+	assert.Equal([]byte{1, 1, 1, 1}, src)
+}
+
+func TestPL2_ApplyBlend(t *testing.T) {
+	assert := testify.New(t)
+	p := testPL2()
+	src := []byte{0, 10, 100, 200}
+
+	dst := []byte{50, 50, 50, 100}
+	assert.NoError(p.Apply(TransformAlphaBlend, 1, dst, src))
+	assert.Equal([]byte{50, 30, 75, 150}, dst, "index 0 is transparent")
+
+	dst = []byte{50, 50, 50, 100}
+	assert.NoError(p.Apply(TransformAdditiveBlend, 0, dst, src))
+	assert.Equal([]byte{50, 60, 150, 255}, dst)
+}
+
+func TestPL2_ApplyErrors(t *testing.T) {
+	assert := testify.New(t)
+	p := testPL2()
+
+	assert.Error(p.Apply(TransformLightLevel, 0, make([]byte, 2), make([]byte, 3)))
+	assert.Error(p.Apply(TransformLightLevel, 32, make([]byte, 2), make([]byte, 2)))
+	assert.Error(p.Apply(TransformAlphaBlend, 3, make([]byte, 2), make([]byte, 2)))
+	assert.Error(p.Apply(TransformType(-1), 0, make([]byte, 2), make([]byte, 2)))
+}
+
+func TestPL2Palette_ToRGBA(t *testing.T) {
+	assert := testify.New(t)
+	p := testPL2()
+
+	img := p.BasePalette.ToRGBA([]byte{0, 1, 2, 3, 4, 5}, 3)
+
+	assert.Equal(3, img.Bounds().Dx())
+	assert.Equal(2, img.Bounds().Dy())
+	assert.Equal(color.RGBA{}, img.RGBAAt(0, 0))
+	assert.Equal(color.RGBA{R: 5, G: 250, B: 7, A: 0xff}, img.RGBAAt(2, 1))
+
+	assert.Equal(image.Rect(0, 0, 4, 1), p.BasePalette.ToRGBA([]byte{1, 2, 3, 4, 5}, 4).Bounds(), "partial row")
+
+	for _, width := range []int{0, -3} {
+		assert.Equal(image.Rectangle{}, p.BasePalette.ToRGBA([]byte{1, 2, 3}, width).Bounds(), "width %d", width)
+	}
+}
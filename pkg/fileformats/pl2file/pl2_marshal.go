@@ -0,0 +1,14 @@
+package d2pl2
+
+import (
+	"encoding/binary"
+
+	"github.com/go-restruct/restruct"
+)
+
+// Marshal packs the PL2 into the binary layout read by Load
+func (p *PL2) Marshal() ([]byte, error) {
+	restruct.EnableExprBeta()
+
+	return restruct.Pack(binary.LittleEndian, p)
+}
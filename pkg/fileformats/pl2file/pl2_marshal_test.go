@@ -0,0 +1,24 @@
+package d2pl2
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestPL2_Marshal(t *testing.T) {
+	assert := testify.New(t)
+	p := testPL2()
+	p.TextColors[12] = PL2Color24Bits{R: 1, G: 2, B: 3}
+	p.DarkendColorShift.Indices[9] = 8
+
+	data, err := p.Marshal()
+	if !assert.NoError(err) {
+		return
+	}
+
+	loaded, err := Load(data)
+	if assert.NoError(err) {
+		assert.Equal(p, loaded)
+	}
+}
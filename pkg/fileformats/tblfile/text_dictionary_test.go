@@ -0,0 +1,89 @@
+package d2tbl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestTextDictionary_MarshalRoundTrip(t *testing.T) {
+	assert := testify.New(t)
+
+	td := TextDictionary{
+		"ModStr1a":  "+%d to Strength",
+		"strUnique": "Unique",
+		"empty":     "",
+		"#3":        "unnamed string",
+		"#40":       "another unnamed string",
+	}
+
+	for i := 0; i < 200; i++ {
+		td[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value %d", i)
+	}
+
+	data, err := td.Marshal()
+	if !assert.NoError(err) {
+		return
+	}
+
+	loaded, err := LoadTextDictionary(data)
+	if assert.NoError(err) {
+		assert.Equal(td, loaded)
+	}
+
+	assert.Equal(uint16(len(td)), binary.LittleEndian.Uint16(data[2:]))
+	assert.Equal(uint32(len(data)), binary.LittleEndian.Uint32(data[17:]), "file size")
+
+	again, err := loaded.Marshal()
+	if assert.NoError(err) {
+		assert.Equal(data, again, "the output is deterministic")
+	}
+}
+
+func TestTextDictionary_MarshalHash(t *testing.T) {
+	assert := testify.New(t)
+
+	data, err := TextDictionary{"a": "b"}.Marshal()
+	if !assert.NoError(err) {
+		return
+	}
+
+	size := binary.LittleEndian.Uint32(data[4:])
+	slot := hashString("a") % size
+	entry := data[headerSize+2+int(slot)*hashEntrySize:]
+
+	assert.Equal(uint32('a'), hashString("a"))
+	assert.Equal(byte(1), entry[0], "the string is stored in its hash slot")
+	assert.Equal(uint16(slot), binary.LittleEndian.Uint16(data[headerSize:]), "element index")
+}
+
+func TestHashString(t *testing.T) {
+	// long keys overflow into the top nibble, which is folded back in
+	testify.Equal(t, uint32(0x0fd449f5), hashString("strUniqueItemName"))
+}
+
+func TestTextDictionary_MarshalEmpty(t *testing.T) {
+	data, err := TextDictionary{}.Marshal()
+	if !testify.NoError(t, err) {
+		return
+	}
+
+	loaded, err := LoadTextDictionary(data)
+	if testify.NoError(t, err) {
+		testify.Empty(t, loaded)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	str := TextDictionary{"a": "string", "b": "string"}
+	expansion := TextDictionary{"b": "expansion", "c": "expansion"}
+	patch := TextDictionary{"c": "patch"}
+
+	merged := Merge(str, expansion, patch)
+
+	testify.Equal(t, TextDictionary{"a": "string", "b": "expansion", "c": "patch"}, merged)
+	testify.Equal(t, "string", str["a"], "the inputs are not modified")
+	testify.Len(t, str, 2)
+}
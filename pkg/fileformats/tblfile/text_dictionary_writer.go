@@ -0,0 +1,212 @@
+package d2tbl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2datautils"
+)
+
+const (
+	headerSize         = 21
+	hashEntrySize      = 17
+	hashOverflowMask   = 0xF0000000
+	hashOverflowShift  = 24
+	hashShift          = 4
+	maxElements        = 0xffff
+	indexedKeyPrefix   = "#"
+	indexedKeyValue    = "x"
+	hashTableLoadRatio = 2 // the hash table is twice as large as the number of strings
+)
+
+// Merge layers dictionaries, the strings of later dictionaries replace the
+// strings of earlier ones. The game looks strings up in patchstring.tbl first,
+// then in expansionstring.tbl and string.tbl, so pass the dictionaries of
+// string.tbl, expansionstring.tbl and patchstring.tbl in that order.
+func Merge(dictionaries ...TextDictionary) TextDictionary {
+	result := make(TextDictionary)
+
+	for _, td := range dictionaries {
+		for key, value := range td {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+// Marshal encodes the dictionary as a tbl file. The strings are stored sorted
+// by key and the hash table is rebuilt with the hash the game uses. Keys of
+// the form #N, which LoadTextDictionary creates for the unnamed "x" strings,
+// are written back as "x" in hash table slot N. The CRC is not computed.
+func (td TextDictionary) Marshal() ([]byte, error) {
+	if len(td) > maxElements {
+		return nil, fmt.Errorf("a tbl file can store at most %d strings, got %d", maxElements, len(td))
+	}
+
+	keys := make([]string, 0, len(td))
+	for key := range td {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	entries, maxTries := td.buildHashTable(keys)
+
+	dataStart := headerSize + 2*len(keys) + hashEntrySize*len(entries) //nolint:gomnd // uint16 element indices
+	data := make([]byte, 0)
+
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+
+		entry.IndexString = uint32(dataStart + len(data))
+		data = append(append(data, entry.key...), 0)
+
+		entry.NameString = uint32(dataStart + len(data))
+		entry.NameLength = uint16(len(entry.value) + 1)
+		data = append(append(data, entry.value...), 0)
+	}
+
+	sw := d2datautils.CreateStreamWriter()
+
+	sw.PushUint16(0) // CRC
+	sw.PushUint16(uint16(len(keys)))
+	sw.PushUint32(uint32(len(entries)))
+	sw.PushBytes(0) // Version
+	sw.PushUint32(uint32(dataStart))
+	sw.PushUint32(uint32(maxTries))
+	sw.PushUint32(uint32(dataStart + len(data)))
+
+	elementIndex := make([]uint16, len(keys))
+
+	for slot, entry := range entries {
+		if entry != nil {
+			elementIndex[entry.Index] = uint16(slot)
+		}
+	}
+
+	for _, slot := range elementIndex {
+		sw.PushUint16(slot)
+	}
+
+	for _, entry := range entries {
+		if entry == nil {
+			sw.PushBytes(make([]byte, hashEntrySize)...)
+			continue
+		}
+
+		sw.PushBytes(1)
+		sw.PushUint16(entry.Index)
+		sw.PushUint32(entry.HashValue)
+		sw.PushUint32(entry.IndexString)
+		sw.PushUint32(entry.NameString)
+		sw.PushUint16(entry.NameLength)
+	}
+
+	sw.PushBytes(data...)
+
+	return sw.GetBytes(), nil
+}
+
+type hashTableEntry struct {
+	textDictionaryHashEntry
+	key   string
+	value string
+}
+
+// buildHashTable places the strings in the hash table, strings with #N keys
+// go to slot N and the others are placed by linear probing
+func (td TextDictionary) buildHashTable(keys []string) (entries []*hashTableEntry, maxTries int) {
+	size := len(keys) * hashTableLoadRatio
+
+	indexed := make(map[string]int)
+
+	for _, key := range keys {
+		if slot, ok := indexedSlot(key); ok {
+			indexed[key] = slot
+
+			if slot >= size {
+				size = slot + 1
+			}
+		}
+	}
+
+	if size == 0 {
+		return nil, 0
+	}
+
+	entries = make([]*hashTableEntry, size)
+
+	// the indexed strings first, their slots are fixed
+	for idx, key := range keys {
+		if slot, ok := indexed[key]; ok {
+			entries[slot] = newHashTableEntry(idx, indexedKeyValue, td[key])
+		}
+	}
+
+	for idx, key := range keys {
+		if _, ok := indexed[key]; ok {
+			continue
+		}
+
+		entry := newHashTableEntry(idx, key, td[key])
+		slot := int(entry.HashValue % uint32(size))
+		tries := 1
+
+		for entries[slot] != nil {
+			slot = (slot + 1) % size
+			tries++
+		}
+
+		entries[slot] = entry
+
+		if tries > maxTries {
+			maxTries = tries
+		}
+	}
+
+	return entries, maxTries
+}
+
+func newHashTableEntry(index int, key, value string) *hashTableEntry {
+	entry := &hashTableEntry{key: key, value: value}
+	entry.IsActive = true
+	entry.Index = uint16(index)
+	entry.HashValue = hashString(key)
+
+	return entry
+}
+
+// indexedSlot returns the hash table slot of a #N key
+func indexedSlot(key string) (int, bool) {
+	if !strings.HasPrefix(key, indexedKeyPrefix) {
+		return 0, false
+	}
+
+	slot, err := strconv.Atoi(key[len(indexedKeyPrefix):])
+	if err != nil || slot < 0 || strconv.Itoa(slot) != key[len(indexedKeyPrefix):] {
+		return 0, false
+	}
+
+	return slot, true
+}
+
+// hashString is the hash of the game's string tables, the slot of a string is
+// the hash modulo the size of the hash table
+func hashString(key string) uint32 {
+	var hash uint32
+
+	for idx := 0; idx < len(key); idx++ {
+		hash = hash<<hashShift + uint32(key[idx])
+
+		if overflow := hash & hashOverflowMask; overflow != 0 {
+			hash = (hash ^ overflow>>hashOverflowShift) &^ hashOverflowMask
+		}
+	}
+
+	return hash
+}
@@ -0,0 +1,3 @@
+// Package d2tiled converts DS1 maps to and from the TMX (XML) and JSON map
+// formats of the Tiled map editor (https://www.mapeditor.org).
+package d2tiled
@@ -0,0 +1,687 @@
+package d2tiled
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2math/d2vector"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2path"
+
+	d2ds1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/ds1file"
+)
+
+const (
+	tileWidth       = 160
+	tileHeight      = 80
+	subTilesPerTile = 5
+	subTileSize     = tileHeight / subTilesPerTile
+	firstGID        = 1
+	defaultVersion  = 18
+	tilesetColumns  = 8
+)
+
+// TilesetImage is the image of the ds1 tileset, which the map references by this
+// name. RenderTileset draws it, it belongs next to the map file.
+const TilesetImage = "ds1.png"
+
+// Names of the layers, object groups and properties written by FromDS1
+const (
+	LayerFloor        = "floor"
+	LayerWall         = "wall"
+	LayerShadow       = "shadow"
+	LayerSubstitution = "substitution"
+
+	GroupObjects            = "objects"
+	GroupSubstitutionGroups = "substitution groups"
+
+	ObjectTypeObject       = "object"
+	ObjectTypePath         = "path"
+	ObjectTypeSubstitution = "substitution"
+
+	tilesetName = "ds1"
+
+	propVersion          = "version"
+	propAct              = "act"
+	propSubstitutionType = "substitutionType"
+	propFiles            = "files"
+	propLayer            = "layer"
+	propProp1            = "prop1"
+	propSequence         = "sequence"
+	propStyle            = "style"
+	propUnknown1         = "unknown1"
+	propUnknown2         = "unknown2"
+	propHidden           = "hidden"
+	propType             = "type"
+	propZero             = "zero"
+	propValue            = "value"
+	propID               = "id"
+	propFlags            = "flags"
+	propObject           = "object"
+	propActions          = "actions"
+	propUnknown          = "unknown"
+)
+
+// tileRecord is one distinct DS1 record, stored as a tile of the ds1 tileset
+type tileRecord struct {
+	layer        string
+	floor        d2ds1.FloorShadowRecord
+	wall         d2ds1.WallRecord
+	substitution d2ds1.SubstitutionRecord
+}
+
+// FromDS1 converts a DS1 into an isometric Tiled map. Every distinct floor, wall,
+// shadow and substitution record becomes a tile of the "ds1" tileset, whose image
+// is TilesetImage. Objects and NPC paths are placed on the "objects" object group.
+func FromDS1(ds1 *d2ds1.DS1) *Map {
+	m := &Map{
+		Orientation: orientationIso,
+		RenderOrder: renderOrderRD,
+		Width:       int(ds1.Width),
+		Height:      int(ds1.Height),
+		TileWidth:   tileWidth,
+		TileHeight:  tileHeight,
+		Properties: []Property{
+			intProperty(propVersion, int(ds1.Version)),
+			intProperty(propAct, int(ds1.Act)),
+			intProperty(propSubstitutionType, int(ds1.SubstitutionType)),
+			stringProperty(propFiles, strings.Join(ds1.Files, "\n")),
+		},
+	}
+
+	tileset := &Tileset{FirstGID: firstGID, Name: tilesetName, TileWidth: tileWidth, TileHeight: tileHeight}
+	gids := make(map[tileRecord]uint32)
+
+	for idx := 0; idx < int(ds1.NumberOfFloors); idx++ {
+		m.addTileLayer(ds1, LayerFloor+strconv.Itoa(idx+1), tileset, gids, func(t *d2ds1.TileRecord) tileRecord {
+			return tileRecord{layer: LayerFloor, floor: t.Floors[idx]}
+		})
+	}
+
+	for idx := 0; idx < int(ds1.NumberOfWalls); idx++ {
+		m.addTileLayer(ds1, LayerWall+strconv.Itoa(idx+1), tileset, gids, func(t *d2ds1.TileRecord) tileRecord {
+			return tileRecord{layer: LayerWall, wall: t.Walls[idx]}
+		})
+	}
+
+	if ds1.NumberOfShadowLayers > 0 {
+		m.addTileLayer(ds1, LayerShadow, tileset, gids, func(t *d2ds1.TileRecord) tileRecord {
+			return tileRecord{layer: LayerShadow, floor: t.Shadows[0]}
+		})
+	}
+
+	if ds1.NumberOfSubstitutionLayers > 0 {
+		m.addTileLayer(ds1, LayerSubstitution, tileset, gids, func(t *d2ds1.TileRecord) tileRecord {
+			return tileRecord{layer: LayerSubstitution, substitution: t.Substitutions[0]}
+		})
+	}
+
+	rows := (len(tileset.Tiles) + tilesetColumns - 1) / tilesetColumns
+	if rows == 0 {
+		rows = 1
+	}
+
+	tileset.Columns = tilesetColumns
+	tileset.Image = TilesetImage
+	tileset.ImageWidth, tileset.ImageHeight = tilesetColumns*tileWidth, rows*tileHeight
+
+	m.Tilesets = []Tileset{*tileset}
+	m.addObjects(ds1)
+	m.addSubstitutionGroups(ds1)
+
+	m.NextLayerID = len(m.Layers) + len(m.ObjectGroups) + 1
+
+	return m
+}
+
+func (m *Map) addTileLayer(ds1 *d2ds1.DS1, name string, tileset *Tileset, gids map[tileRecord]uint32,
+	recordAt func(t *d2ds1.TileRecord) tileRecord) {
+	layer := TileLayer{
+		ID:      len(m.Layers) + 1,
+		Name:    name,
+		Width:   m.Width,
+		Height:  m.Height,
+		Visible: true,
+		Data:    make([]uint32, 0, m.Width*m.Height),
+	}
+
+	for y := range ds1.Tiles {
+		for x := range ds1.Tiles[y] {
+			record := recordAt(&ds1.Tiles[y][x]).fileFields()
+
+			if record.empty() {
+				layer.Data = append(layer.Data, 0)
+				continue
+			}
+
+			gid, found := gids[record]
+			if !found {
+				gid = tileset.FirstGID + uint32(len(tileset.Tiles))
+				gids[record] = gid
+				tileset.Tiles = append(tileset.Tiles, Tile{ID: gid - tileset.FirstGID, Properties: record.properties()})
+			}
+
+			layer.Data = append(layer.Data, gid)
+		}
+	}
+
+	m.Layers = append(m.Layers, layer)
+}
+
+func (m *Map) addObjects(ds1 *d2ds1.DS1) {
+	group := ObjectGroup{ID: len(m.Layers) + len(m.ObjectGroups) + 1, Name: GroupObjects, Visible: true}
+	objectIDs := make([]int, len(ds1.Objects))
+
+	for idx := range ds1.Objects {
+		obj := &ds1.Objects[idx]
+		objectIDs[idx] = m.nextObjectID()
+
+		group.Objects = append(group.Objects, Object{
+			ID:    objectIDs[idx],
+			Type:  ObjectTypeObject,
+			X:     float64(obj.X * subTileSize),
+			Y:     float64(obj.Y * subTileSize),
+			Point: true,
+			Properties: []Property{
+				intProperty(propType, obj.Type),
+				intProperty(propID, obj.ID),
+				intProperty(propFlags, obj.Flags),
+			},
+		})
+	}
+
+	for idx := range ds1.Objects {
+		obj := &ds1.Objects[idx]
+
+		if obj.Paths == nil {
+			continue
+		}
+
+		points := make([]Point, len(obj.Paths))
+		actions := make([]string, len(obj.Paths))
+
+		for pathIdx := range obj.Paths {
+			path := &obj.Paths[pathIdx]
+			points[pathIdx] = Point{
+				X: (path.Position.X() - float64(obj.X)) * subTileSize,
+				Y: (path.Position.Y() - float64(obj.Y)) * subTileSize,
+			}
+			actions[pathIdx] = strconv.Itoa(path.Action)
+		}
+
+		group.Objects = append(group.Objects, Object{
+			ID:       m.nextObjectID(),
+			Type:     ObjectTypePath,
+			X:        float64(obj.X * subTileSize),
+			Y:        float64(obj.Y * subTileSize),
+			Polyline: points,
+			Properties: []Property{
+				objectProperty(propObject, objectIDs[idx]),
+				stringProperty(propActions, strings.Join(actions, ",")),
+			},
+		})
+	}
+
+	m.ObjectGroups = append(m.ObjectGroups, group)
+}
+
+func (m *Map) addSubstitutionGroups(ds1 *d2ds1.DS1) {
+	group := ObjectGroup{ID: len(m.Layers) + len(m.ObjectGroups) + 1, Name: GroupSubstitutionGroups, Visible: true}
+
+	for _, sub := range ds1.SubstitutionGroups {
+		group.Objects = append(group.Objects, Object{
+			ID:         m.nextObjectID(),
+			Type:       ObjectTypeSubstitution,
+			X:          float64(sub.TileX * tileHeight),
+			Y:          float64(sub.TileY * tileHeight),
+			Width:      float64(sub.WidthInTiles * tileHeight),
+			Height:     float64(sub.HeightInTiles * tileHeight),
+			Properties: []Property{intProperty(propUnknown, int(sub.Unknown))},
+		})
+	}
+
+	m.ObjectGroups = append(m.ObjectGroups, group)
+}
+
+func (m *Map) nextObjectID() int {
+	if m.NextObjectID < 1 {
+		m.NextObjectID = 1
+	}
+
+	id := m.NextObjectID
+	m.NextObjectID++
+
+	return id
+}
+
+// ToDS1 converts a Tiled map created by FromDS1 (and possibly edited in Tiled)
+// back into a DS1. Maps without a version property become version 18 DS1s.
+func (m *Map) ToDS1() (*d2ds1.DS1, error) {
+	version, err := m.intProperty(propVersion, defaultVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	ds1, err := d2ds1.NewDS1(int32(version), int32(m.Width), int32(m.Height))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.readHeader(ds1); err != nil {
+		return nil, err
+	}
+
+	records, err := m.tileRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	numFloors, numWalls := 0, 0
+
+	for idx := range m.Layers {
+		switch layerKind(m.Layers[idx].Name) {
+		case LayerFloor:
+			numFloors++
+		case LayerWall:
+			numWalls++
+		}
+	}
+
+	if err = ds1.SetNumberOfFloors(int32(numFloors)); err != nil {
+		return nil, err
+	}
+
+	if err = ds1.SetNumberOfWalls(int32(numWalls)); err != nil {
+		return nil, err
+	}
+
+	floorIdx, wallIdx := 0, 0
+
+	for idx := range m.Layers {
+		layer := &m.Layers[idx]
+		kind := layerKind(layer.Name)
+
+		if kind == "" {
+			continue
+		}
+
+		if layer.Width != m.Width || layer.Height != m.Height || len(layer.Data) != m.Width*m.Height {
+			return nil, fmt.Errorf("layer %q does not match the map size", layer.Name)
+		}
+
+		for tileIdx, gid := range layer.Data {
+			x, y := tileIdx%m.Width, tileIdx/m.Width
+
+			if err = setTile(ds1, records, kind, floorIdx, wallIdx, x, y, gid); err != nil {
+				return nil, fmt.Errorf("layer %q: %v", layer.Name, err)
+			}
+		}
+
+		switch kind {
+		case LayerFloor:
+			floorIdx++
+		case LayerWall:
+			wallIdx++
+		}
+	}
+
+	if err = m.readObjects(ds1); err != nil {
+		return nil, err
+	}
+
+	if err = m.readSubstitutionGroups(ds1); err != nil {
+		return nil, err
+	}
+
+	return ds1, nil
+}
+
+func (m *Map) readHeader(ds1 *d2ds1.DS1) error {
+	act, err := m.intProperty(propAct, 1)
+	if err != nil {
+		return err
+	}
+
+	ds1.Act = int32(act)
+
+	substitutionType, err := m.intProperty(propSubstitutionType, 0)
+	if err != nil {
+		return err
+	}
+
+	if substitutionType != 0 {
+		ds1.SetSubstitutionType(int32(substitutionType))
+	}
+
+	if files, found := m.Property(propFiles); found && files.Value != "" {
+		ds1.Files = strings.Split(files.Value, "\n")
+	}
+
+	return nil
+}
+
+func setTile(ds1 *d2ds1.DS1, records map[uint32]tileRecord, kind string, floorIdx, wallIdx, x, y int, gid uint32) error {
+	if gid == 0 {
+		return nil
+	}
+
+	record, found := records[gid]
+	if !found {
+		return fmt.Errorf("tile %d,%d uses unknown tile %d", x, y, gid)
+	}
+
+	if record.layer != kind {
+		return fmt.Errorf("tile %d,%d uses a %s tile", x, y, record.layer)
+	}
+
+	switch kind {
+	case LayerFloor:
+		return ds1.SetFloor(x, y, floorIdx, record.floor)
+	case LayerWall:
+		return ds1.SetWall(x, y, wallIdx, record.wall)
+	case LayerShadow:
+		return ds1.SetShadow(x, y, record.floor)
+	default:
+		return ds1.SetSubstitution(x, y, record.substitution)
+	}
+}
+
+func (m *Map) tileRecords() (map[uint32]tileRecord, error) {
+	records := make(map[uint32]tileRecord)
+
+	for _, tileset := range m.Tilesets {
+		for idx := range tileset.Tiles {
+			tile := &tileset.Tiles[idx]
+
+			record, err := parseTileRecord(tile)
+			if err != nil {
+				return nil, fmt.Errorf("tileset %q, tile %d: %v", tileset.Name, tile.ID, err)
+			}
+
+			records[tileset.FirstGID+tile.ID] = record
+		}
+	}
+
+	return records, nil
+}
+
+func (m *Map) readObjects(ds1 *d2ds1.DS1) error {
+	group := m.objectGroup(GroupObjects)
+	if group == nil {
+		return nil
+	}
+
+	objectIndex := make(map[int]int)
+
+	for idx := range group.Objects {
+		o := &group.Objects[idx]
+		if o.Type != ObjectTypeObject {
+			continue
+		}
+
+		obj := d2ds1.Object{X: toSubTile(o.X), Y: toSubTile(o.Y)}
+
+		var err error
+
+		if obj.Type, err = objectIntProperty(o, propType); err != nil {
+			return err
+		}
+
+		if obj.ID, err = objectIntProperty(o, propID); err != nil {
+			return err
+		}
+
+		if obj.Flags, err = objectIntProperty(o, propFlags); err != nil {
+			return err
+		}
+
+		objectIndex[o.ID] = ds1.AddObject(obj)
+	}
+
+	for idx := range group.Objects {
+		o := &group.Objects[idx]
+		if o.Type != ObjectTypePath {
+			continue
+		}
+
+		owner, err := objectIntProperty(o, propObject)
+		if err != nil {
+			return err
+		}
+
+		objIdx, found := objectIndex[owner]
+		if !found {
+			return fmt.Errorf("path %d references unknown object %d", o.ID, owner)
+		}
+
+		paths, err := parsePaths(o)
+		if err != nil {
+			return err
+		}
+
+		ds1.Objects[objIdx].Paths = paths
+	}
+
+	return nil
+}
+
+func parsePaths(o *Object) ([]d2path.Path, error) {
+	var actions []string
+
+	if prop, found := o.Property(propActions); found && prop.Value != "" {
+		actions = strings.Split(prop.Value, ",")
+	}
+
+	paths := make([]d2path.Path, len(o.Polyline))
+
+	for idx, p := range o.Polyline {
+		paths[idx].Position = d2vector.NewPosition(float64(toSubTile(o.X+p.X)), float64(toSubTile(o.Y+p.Y)))
+
+		if idx >= len(actions) {
+			continue
+		}
+
+		action, err := strconv.Atoi(strings.TrimSpace(actions[idx]))
+		if err != nil {
+			return nil, fmt.Errorf("path %d: invalid action %q", o.ID, actions[idx])
+		}
+
+		paths[idx].Action = action
+	}
+
+	return paths, nil
+}
+
+func (m *Map) readSubstitutionGroups(ds1 *d2ds1.DS1) error {
+	group := m.objectGroup(GroupSubstitutionGroups)
+	if group == nil {
+		return nil
+	}
+
+	for idx := range group.Objects {
+		o := &group.Objects[idx]
+
+		unknown, err := objectIntProperty(o, propUnknown)
+		if err != nil {
+			return err
+		}
+
+		ds1.SubstitutionGroups = append(ds1.SubstitutionGroups, d2ds1.SubstitutionGroup{
+			TileX:         int32(math.Round(o.X / tileHeight)),
+			TileY:         int32(math.Round(o.Y / tileHeight)),
+			WidthInTiles:  int32(math.Round(o.Width / tileHeight)),
+			HeightInTiles: int32(math.Round(o.Height / tileHeight)),
+			Unknown:       int32(unknown),
+		})
+	}
+
+	return nil
+}
+
+func (m *Map) objectGroup(name string) *ObjectGroup {
+	for idx := range m.ObjectGroups {
+		if m.ObjectGroups[idx].Name == name {
+			return &m.ObjectGroups[idx]
+		}
+	}
+
+	return nil
+}
+
+func (m *Map) intProperty(name string, defaultValue int) (int, error) {
+	prop, found := m.Property(name)
+	if !found {
+		return defaultValue, nil
+	}
+
+	return prop.Int()
+}
+
+func objectIntProperty(o *Object, name string) (int, error) {
+	prop, found := o.Property(name)
+	if !found {
+		return 0, nil
+	}
+
+	value, err := prop.Int()
+	if err != nil {
+		return 0, fmt.Errorf("object %d: %v", o.ID, err)
+	}
+
+	return value, nil
+}
+
+// layerKind returns the kind of DS1 layer stored in the tile layer with the given name
+func layerKind(name string) string {
+	for _, kind := range []string{LayerFloor, LayerWall} {
+		if strings.HasPrefix(name, kind) {
+			if _, err := strconv.Atoi(strings.TrimPrefix(name, kind)); err == nil {
+				return kind
+			}
+		}
+	}
+
+	if name == LayerShadow || name == LayerSubstitution {
+		return name
+	}
+
+	return ""
+}
+
+func toSubTile(pixels float64) int {
+	return int(math.Round(pixels / subTileSize))
+}
+
+// fileFields clears the fields of the record that are not stored in DS1 files
+func (r tileRecord) fileFields() tileRecord {
+	r.floor.RandomIndex, r.floor.Animated, r.floor.YAdjust = 0, false, 0
+	r.wall.RandomIndex, r.wall.YAdjust = 0, 0
+
+	return r
+}
+
+func (r tileRecord) empty() bool {
+	return r == tileRecord{layer: r.layer}
+}
+
+func (r tileRecord) properties() []Property {
+	props := []Property{stringProperty(propLayer, r.layer)}
+
+	switch r.layer {
+	case LayerSubstitution:
+		return append(props, intProperty(propValue, int(r.substitution.Unknown)))
+	case LayerWall:
+		return append(props,
+			intProperty(propProp1, int(r.wall.Prop1)),
+			intProperty(propSequence, int(r.wall.Sequence)),
+			intProperty(propStyle, int(r.wall.Style)),
+			intProperty(propUnknown1, int(r.wall.Unknown1)),
+			intProperty(propUnknown2, int(r.wall.Unknown2)),
+			boolProperty(propHidden, r.wall.Hidden),
+			intProperty(propType, int(r.wall.Type)),
+			intProperty(propZero, int(r.wall.Zero)),
+		)
+	default:
+		return append(props,
+			intProperty(propProp1, int(r.floor.Prop1)),
+			intProperty(propSequence, int(r.floor.Sequence)),
+			intProperty(propStyle, int(r.floor.Style)),
+			intProperty(propUnknown1, int(r.floor.Unknown1)),
+			intProperty(propUnknown2, int(r.floor.Unknown2)),
+			boolProperty(propHidden, r.floor.Hidden),
+		)
+	}
+}
+
+func parseTileRecord(tile *Tile) (tileRecord, error) {
+	layer, found := tile.Property(propLayer)
+	if !found {
+		return tileRecord{}, fmt.Errorf("missing %q property", propLayer)
+	}
+
+	r := tileRecord{layer: layer.Value}
+
+	if r.layer == LayerSubstitution {
+		value, err := tileUint(tile, propValue, math.MaxUint32)
+		r.substitution.Unknown = uint32(value)
+
+		return r, err
+	}
+
+	if r.layer != LayerFloor && r.layer != LayerWall && r.layer != LayerShadow {
+		return r, fmt.Errorf("unknown layer %q", r.layer)
+	}
+
+	var fields [7]uint64
+
+	for idx, name := range []string{propProp1, propSequence, propStyle, propUnknown1, propUnknown2, propType, propZero} {
+		value, err := tileUint(tile, name, math.MaxUint8)
+		if err != nil {
+			return r, err
+		}
+
+		fields[idx] = value
+	}
+
+	hidden := false
+
+	if prop, found := tile.Property(propHidden); found {
+		var err error
+
+		if hidden, err = prop.Bool(); err != nil {
+			return r, err
+		}
+	}
+
+	r.floor = d2ds1.FloorShadowRecord{
+		Prop1: byte(fields[0]), Sequence: byte(fields[1]), Style: byte(fields[2]),
+		Unknown1: byte(fields[3]), Unknown2: byte(fields[4]), Hidden: hidden,
+	}
+
+	if r.layer == LayerWall {
+		r.wall = d2ds1.WallRecord{
+			Prop1: r.floor.Prop1, Sequence: r.floor.Sequence, Style: r.floor.Style,
+			Unknown1: r.floor.Unknown1, Unknown2: r.floor.Unknown2, Hidden: hidden,
+			Type: d2enum.TileType(fields[5]), Zero: byte(fields[6]),
+		}
+		r.floor = d2ds1.FloorShadowRecord{}
+	}
+
+	return r, nil
+}
+
+func tileUint(tile *Tile, name string, max uint64) (uint64, error) {
+	prop, found := tile.Property(name)
+	if !found {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseUint(prop.Value, 10, 64)
+	if err != nil || value > max {
+		return 0, fmt.Errorf("property %q: invalid value %q", name, prop.Value)
+	}
+
+	return value, nil
+}
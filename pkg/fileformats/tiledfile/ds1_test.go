@@ -0,0 +1,255 @@
+package d2tiled
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2math/d2vector"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2path"
+
+	d2ds1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/ds1file"
+)
+
+func exampleDS1(t *testing.T) *d2ds1.DS1 {
+	ds1, err := d2ds1.NewDS1(defaultVersion, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds1.Act = 2
+	ds1.Files = []string{"/d2/data/global/tiles/act2/town/floor.tg1"}
+	ds1.SetSubstitutionType(2)
+	ds1.SubstitutionGroups = []d2ds1.SubstitutionGroup{{TileX: 1, TileY: 0, WidthInTiles: 2, HeightInTiles: 1, Unknown: 3}}
+
+	if err := ds1.SetNumberOfWalls(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			floor := d2ds1.FloorShadowRecord{Prop1: 1, Style: byte(x), Sequence: byte(y), Hidden: x == 2}
+			wall := d2ds1.WallRecord{Type: d2enum.TileLeftWall, Prop1: 1, Style: 4, Sequence: byte(x), Zero: 1}
+
+			if err := ds1.SetFloor(x, y, 0, floor); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ds1.SetWall(x, y, 1, wall); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ds1.SetSubstitution(x, y, d2ds1.SubstitutionRecord{Unknown: uint32(x * y)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	ds1.AddObject(d2ds1.Object{Type: 2, ID: 32, X: 4, Y: 7, Flags: 1})
+	ds1.AddObject(d2ds1.Object{Type: 1, ID: 250, X: 12, Y: 3, Paths: []d2path.Path{
+		{Position: d2vector.NewPosition(12, 3), Action: 1},
+		{Position: d2vector.NewPosition(14, 9), Action: 0},
+	}})
+
+	return ds1
+}
+
+// exampleV7DS1 is a DS1 from before the floor layers and the substitution
+// type were stored, it has one floor and no substitution layer
+func exampleV7DS1(t *testing.T) *d2ds1.DS1 {
+	ds1, err := d2ds1.NewDS1(7, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds1.Files = []string{"/d2/data/global/tiles/act1/outdoors/floor.tg1"}
+
+	if err := ds1.SetNumberOfWalls(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if err := ds1.SetFloor(x, y, 0, d2ds1.FloorShadowRecord{Prop1: 1, Style: byte(y), Sequence: byte(x)}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ds1.SetShadow(x, y, d2ds1.FloorShadowRecord{Prop1: byte(x)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := ds1.SetWall(1, 0, 1, d2ds1.WallRecord{Type: d2enum.TileRightWall, Prop1: 1, Style: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	ds1.AddObject(d2ds1.Object{Type: 2, ID: 5, X: 3, Y: 4})
+
+	return ds1
+}
+
+func testRoundTrip(t *testing.T, ds1 *d2ds1.DS1, encode func(*Map) ([]byte, error), decode func([]byte) (*Map, error)) {
+	expected, err := ds1.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := encode(FromDS1(ds1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := m.ToDS1()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := imported.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Error("DS1 changed after a round trip through Tiled")
+	}
+}
+
+func TestTMXRoundTrip(t *testing.T) {
+	testRoundTrip(t, exampleDS1(t), (*Map).MarshalTMX, UnmarshalTMX)
+	testRoundTrip(t, exampleV7DS1(t), (*Map).MarshalTMX, UnmarshalTMX)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	testRoundTrip(t, exampleDS1(t), (*Map).MarshalJSON, UnmarshalJSON)
+	testRoundTrip(t, exampleV7DS1(t), (*Map).MarshalJSON, UnmarshalJSON)
+}
+
+func TestFromDS1(t *testing.T) {
+	m := FromDS1(exampleDS1(t))
+
+	names := make([]string, 0, len(m.Layers))
+	for _, l := range m.Layers {
+		names = append(names, l.Name)
+	}
+
+	expected := []string{"floor1", "wall1", "wall2", "shadow", "substitution"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected layers %v, got %v", expected, names)
+	}
+
+	for idx := range expected {
+		if names[idx] != expected[idx] {
+			t.Fatalf("expected layers %v, got %v", expected, names)
+		}
+	}
+
+	if gid := m.Layers[1].Data[0]; gid != 0 {
+		t.Errorf("empty wall layer should not reference tiles, got gid %d", gid)
+	}
+
+	objects := m.objectGroup(GroupObjects)
+	if objects == nil || len(objects.Objects) != 3 {
+		t.Fatal("expected two objects and one NPC path")
+	}
+
+	path := objects.Objects[2]
+	if path.Type != ObjectTypePath || len(path.Polyline) != 2 || path.Polyline[1] != (Point{X: 32, Y: 96}) {
+		t.Errorf("unexpected NPC path %+v", path)
+	}
+}
+
+func TestFromDS1_V7(t *testing.T) {
+	m := FromDS1(exampleV7DS1(t))
+
+	expected := []string{"floor1", "wall1", "wall2", "shadow"}
+	if len(m.Layers) != len(expected) {
+		t.Fatalf("expected %d layers, got %d", len(expected), len(m.Layers))
+	}
+
+	for idx := range expected {
+		if m.Layers[idx].Name != expected[idx] {
+			t.Errorf("expected layer %q, got %q", expected[idx], m.Layers[idx].Name)
+		}
+	}
+
+	if version, _ := m.intProperty(propVersion, 0); version != 7 {
+		t.Errorf("expected version 7, got %d", version)
+	}
+
+	ds1, err := m.ToDS1()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ds1.Version != 7 || ds1.NumberOfFloors != 1 || ds1.NumberOfSubstitutionLayers != 0 {
+		t.Errorf("unexpected DS1 after the import: version %d, %d floors, %d substitution layers",
+			ds1.Version, ds1.NumberOfFloors, ds1.NumberOfSubstitutionLayers)
+	}
+}
+
+func TestFromDS1_TilesetImage(t *testing.T) {
+	m := FromDS1(exampleDS1(t))
+	tileset := m.Tilesets[0]
+
+	// 6 floors, 3 walls and 2 substitution values, the shadows are empty
+	if tileset.Image != TilesetImage || tileset.Columns != 8 || tileset.ImageWidth != 8*160 || tileset.ImageHeight != 2*80 {
+		t.Errorf("unexpected tileset image %q, %d columns, %dx%d",
+			tileset.Image, tileset.Columns, tileset.ImageWidth, tileset.ImageHeight)
+	}
+
+	data, err := m.MarshalTMX()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(data, []byte(`<image source="ds1.png" width="1280" height="160"></image>`)) {
+		t.Error("the TMX tileset doesn't reference its image")
+	}
+
+	img, err := m.RenderTileset()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if img.Bounds() != image.Rect(0, 0, 1280, 160) {
+		t.Fatalf("unexpected tileset image bounds %v", img.Bounds())
+	}
+
+	// the first tile is the floor with style 0 and sequence 0
+	if c := img.RGBAAt(80, 40); c != placeholderColors[LayerFloor] {
+		t.Errorf("expected the floor color at the center of the first tile, got %v", c)
+	}
+
+	if c := img.RGBAAt(2, 2); c != (color.RGBA{}) {
+		t.Errorf("expected the corners of the tiles to be transparent, got %v", c)
+	}
+
+	for idx := len(tileset.Tiles); idx < 16; idx++ {
+		if c := img.RGBAAt(idx%8*160+80, idx/8*80+40); c != (color.RGBA{}) {
+			t.Errorf("expected the unused cell %d to be transparent, got %v", idx, c)
+		}
+	}
+
+	m.Tilesets = nil
+
+	if _, err := m.RenderTileset(); err == nil {
+		t.Error("expected an error for a map without the ds1 tileset")
+	}
+}
+
+func TestToDS1_BadTile(t *testing.T) {
+	m := FromDS1(exampleDS1(t))
+	m.Layers[0].Data[0] = 1000
+
+	if _, err := m.ToDS1(); err == nil {
+		t.Error("expected an error for an unknown tile")
+	}
+}
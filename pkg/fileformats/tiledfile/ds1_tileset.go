@@ -0,0 +1,124 @@
+package d2tiled
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// wallOutline is the width of the outline drawn for walls, relative to the
+// size of the diamond
+const wallOutline = 0.1
+
+// placeholderShades is how many shades a layer color comes in, the style and
+// sequence of a record pick one
+const placeholderShades = 8
+
+//nolint:gochecknoglobals // constant colors of the placeholder tiles
+var placeholderColors = map[string]color.RGBA{
+	LayerFloor:        {R: 0x3c, G: 0x7c, B: 0x3c, A: 0xff},
+	LayerWall:         {R: 0x9c, G: 0x6c, B: 0x3c, A: 0xff},
+	LayerShadow:       {A: 0x80},
+	LayerSubstitution: {R: 0x7c, G: 0x3c, B: 0x9c, A: 0xff},
+}
+
+// RenderTileset draws the image of the ds1 tileset of a map made by FromDS1,
+// to be saved as TilesetImage. A DS1 doesn't hold the graphics of its tiles,
+// they are in DT1 files, so each tile is drawn as a diamond showing its layer,
+// shaded by its style and sequence: floors are filled, walls are outlined,
+// shadows are translucent and substitutions are drawn smaller.
+func (m *Map) RenderTileset() (*image.RGBA, error) {
+	var tileset *Tileset
+
+	for idx := range m.Tilesets {
+		if m.Tilesets[idx].Name == tilesetName {
+			tileset = &m.Tilesets[idx]
+		}
+	}
+
+	if tileset == nil {
+		return nil, fmt.Errorf("map has no %q tileset", tilesetName)
+	}
+
+	if tileset.Columns < 1 {
+		return nil, fmt.Errorf("tileset %q has %d columns", tilesetName, tileset.Columns)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, tileset.ImageWidth, tileset.ImageHeight))
+
+	for idx := range tileset.Tiles {
+		tile := &tileset.Tiles[idx]
+
+		record, err := parseTileRecord(tile)
+		if err != nil {
+			return nil, fmt.Errorf("tileset %q, tile %d: %v", tilesetName, tile.ID, err)
+		}
+
+		column, row := int(tile.ID)%tileset.Columns, int(tile.ID)/tileset.Columns
+		cell := image.Rect(0, 0, tileset.TileWidth, tileset.TileHeight).
+			Add(image.Pt(column*tileset.TileWidth, row*tileset.TileHeight))
+
+		if !cell.In(img.Bounds()) {
+			return nil, fmt.Errorf("tileset %q, tile %d is outside of the image", tilesetName, tile.ID)
+		}
+
+		drawPlaceholder(img.SubImage(cell).(*image.RGBA), record)
+	}
+
+	return img, nil
+}
+
+func drawPlaceholder(img *image.RGBA, r tileRecord) {
+	bounds := img.Bounds()
+	halfWidth, halfHeight := float64(bounds.Dx())/2, float64(bounds.Dy())/2
+	c := r.placeholderColor()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// 0 at the center of the diamond, 1 on its edges
+			distance := math.Abs(float64(x-bounds.Min.X)+0.5-halfWidth)/halfWidth +
+				math.Abs(float64(y-bounds.Min.Y)+0.5-halfHeight)/halfHeight
+
+			if r.placeholderCovers(distance) {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+func (r tileRecord) placeholderCovers(distance float64) bool {
+	switch r.layer {
+	case LayerWall:
+		return distance >= 1-wallOutline && distance <= 1
+	case LayerSubstitution:
+		return distance <= 0.5
+	default:
+		return distance <= 1
+	}
+}
+
+func (r tileRecord) placeholderColor() color.RGBA {
+	c := placeholderColors[r.layer]
+
+	var shade int
+
+	switch r.layer {
+	case LayerShadow:
+		return c
+	case LayerSubstitution:
+		shade = int(r.substitution.Unknown % placeholderShades)
+	case LayerWall:
+		shade = (int(r.wall.Style)*7 + int(r.wall.Sequence)*3) % placeholderShades
+	default:
+		shade = (int(r.floor.Style)*7 + int(r.floor.Sequence)*3) % placeholderShades
+	}
+
+	const step = 12
+
+	c.R += uint8(shade * step)
+	c.G += uint8(shade * step)
+	c.B += uint8(shade * step)
+
+	return c
+}
@@ -0,0 +1,167 @@
+package d2tiled
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	mapVersion       = "1.4"
+	tiledVersion     = "1.4.3"
+	orientationIso   = "isometric"
+	renderOrderRD    = "right-down"
+	drawOrderIndex   = "index"
+	propertyString   = "string"
+	propertyInt      = "int"
+	propertyBool     = "bool"
+	propertyFloat    = "float"
+	propertyObject   = "object"
+	layerTypeTile    = "tilelayer"
+	layerTypeObjects = "objectgroup"
+)
+
+// Map is a Tiled map. Tile layers and object groups are kept apart, the tile
+// layers are drawn first.
+type Map struct {
+	Orientation  string
+	RenderOrder  string
+	Width        int
+	Height       int
+	TileWidth    int
+	TileHeight   int
+	NextLayerID  int
+	NextObjectID int
+	Properties   []Property
+	Tilesets     []Tileset
+	Layers       []TileLayer
+	ObjectGroups []ObjectGroup
+}
+
+// Property is a custom property, the value is stored in its string form.
+type Property struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// Tileset is a tileset embedded in the map. A tileset with an image cuts its
+// tiles from it, in rows of Columns tiles, otherwise each tile has its own image.
+type Tileset struct {
+	FirstGID    uint32
+	Name        string
+	TileWidth   int
+	TileHeight  int
+	Columns     int
+	Image       string
+	ImageWidth  int
+	ImageHeight int
+	Tiles       []Tile
+}
+
+// Tile holds the properties (and optionally an image) of a single tile of a tileset.
+type Tile struct {
+	ID          uint32
+	Image       string
+	ImageWidth  int
+	ImageHeight int
+	Properties  []Property
+}
+
+// TileLayer is a layer of global tile IDs, stored row by row. A GID of 0 is an empty cell.
+type TileLayer struct {
+	ID      int
+	Name    string
+	Width   int
+	Height  int
+	Visible bool
+	Data    []uint32
+}
+
+// ObjectGroup is a layer of objects.
+type ObjectGroup struct {
+	ID      int
+	Name    string
+	Visible bool
+	Objects []Object
+}
+
+// Object is a point, rectangle or polyline placed on an object group.
+type Object struct {
+	ID         int
+	Name       string
+	Type       string
+	X          float64
+	Y          float64
+	Width      float64
+	Height     float64
+	Point      bool
+	Polyline   []Point
+	Properties []Property
+}
+
+// Point is a polyline point, relative to the position of its object.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Property returns the property with the given name
+func (m *Map) Property(name string) (Property, bool) {
+	return findProperty(m.Properties, name)
+}
+
+// Property returns the property with the given name
+func (t *Tile) Property(name string) (Property, bool) {
+	return findProperty(t.Properties, name)
+}
+
+// Property returns the property with the given name
+func (o *Object) Property(name string) (Property, bool) {
+	return findProperty(o.Properties, name)
+}
+
+// Int returns the value of an int property
+func (p Property) Int() (int, error) {
+	n, err := strconv.Atoi(p.Value)
+	if err != nil {
+		return 0, fmt.Errorf("property %q: %v", p.Name, err)
+	}
+
+	return n, nil
+}
+
+// Bool returns the value of a bool property
+func (p Property) Bool() (bool, error) {
+	b, err := strconv.ParseBool(p.Value)
+	if err != nil {
+		return false, fmt.Errorf("property %q: %v", p.Name, err)
+	}
+
+	return b, nil
+}
+
+func findProperty(properties []Property, name string) (Property, bool) {
+	for _, p := range properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return Property{}, false
+}
+
+func intProperty(name string, value int) Property {
+	return Property{Name: name, Type: propertyInt, Value: strconv.Itoa(value)}
+}
+
+func boolProperty(name string, value bool) Property {
+	return Property{Name: name, Type: propertyBool, Value: strconv.FormatBool(value)}
+}
+
+func stringProperty(name, value string) Property {
+	return Property{Name: name, Type: propertyString, Value: value}
+}
+
+func objectProperty(name string, id int) Property {
+	return Property{Name: name, Type: propertyObject, Value: strconv.Itoa(id)}
+}
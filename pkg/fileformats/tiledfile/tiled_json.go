@@ -0,0 +1,305 @@
+package d2tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type jsonMap struct {
+	Type         string         `json:"type"`
+	Version      string         `json:"version"`
+	TiledVersion string         `json:"tiledversion"`
+	Orientation  string         `json:"orientation"`
+	RenderOrder  string         `json:"renderorder"`
+	Width        int            `json:"width"`
+	Height       int            `json:"height"`
+	TileWidth    int            `json:"tilewidth"`
+	TileHeight   int            `json:"tileheight"`
+	Infinite     bool           `json:"infinite"`
+	NextLayerID  int            `json:"nextlayerid"`
+	NextObjectID int            `json:"nextobjectid"`
+	Properties   []jsonProperty `json:"properties,omitempty"`
+	Tilesets     []jsonTileset  `json:"tilesets"`
+	Layers       []jsonLayer    `json:"layers"`
+}
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type jsonTileset struct {
+	FirstGID    uint32            `json:"firstgid"`
+	Name        string            `json:"name"`
+	TileWidth   int               `json:"tilewidth"`
+	TileHeight  int               `json:"tileheight"`
+	TileCount   int               `json:"tilecount"`
+	Columns     int               `json:"columns"`
+	Image       string            `json:"image,omitempty"`
+	ImageWidth  int               `json:"imagewidth,omitempty"`
+	ImageHeight int               `json:"imageheight,omitempty"`
+	Tiles       []jsonTilesetTile `json:"tiles,omitempty"`
+}
+
+type jsonTilesetTile struct {
+	ID          uint32         `json:"id"`
+	Image       string         `json:"image,omitempty"`
+	ImageWidth  int            `json:"imagewidth,omitempty"`
+	ImageHeight int            `json:"imageheight,omitempty"`
+	Properties  []jsonProperty `json:"properties,omitempty"`
+}
+
+type jsonLayer struct {
+	ID        int          `json:"id"`
+	Name      string       `json:"name"`
+	Type      string       `json:"type"`
+	Visible   bool         `json:"visible"`
+	Opacity   float64      `json:"opacity"`
+	X         int          `json:"x"`
+	Y         int          `json:"y"`
+	Width     int          `json:"width,omitempty"`
+	Height    int          `json:"height,omitempty"`
+	Data      []uint32     `json:"data,omitempty"`
+	DrawOrder string       `json:"draworder,omitempty"`
+	Objects   []jsonObject `json:"objects,omitempty"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	Rotation   float64        `json:"rotation"`
+	Visible    bool           `json:"visible"`
+	Point      bool           `json:"point,omitempty"`
+	Polyline   []Point        `json:"polyline,omitempty"`
+	Properties []jsonProperty `json:"properties,omitempty"`
+}
+
+// MarshalJSON encodes the map in the Tiled JSON format
+func (m *Map) MarshalJSON() ([]byte, error) {
+	out := jsonMap{
+		Type:         "map",
+		Version:      mapVersion,
+		TiledVersion: tiledVersion,
+		Orientation:  m.Orientation,
+		RenderOrder:  m.RenderOrder,
+		Width:        m.Width,
+		Height:       m.Height,
+		TileWidth:    m.TileWidth,
+		TileHeight:   m.TileHeight,
+		NextLayerID:  m.NextLayerID,
+		NextObjectID: m.NextObjectID,
+		Properties:   toJSONProperties(m.Properties),
+		Tilesets:     make([]jsonTileset, 0, len(m.Tilesets)),
+		Layers:       make([]jsonLayer, 0, len(m.Layers)+len(m.ObjectGroups)),
+	}
+
+	for _, ts := range m.Tilesets {
+		tileset := jsonTileset{
+			FirstGID:    ts.FirstGID,
+			Name:        ts.Name,
+			TileWidth:   ts.TileWidth,
+			TileHeight:  ts.TileHeight,
+			TileCount:   len(ts.Tiles),
+			Columns:     ts.Columns,
+			Image:       ts.Image,
+			ImageWidth:  ts.ImageWidth,
+			ImageHeight: ts.ImageHeight,
+		}
+
+		for _, t := range ts.Tiles {
+			tileset.Tiles = append(tileset.Tiles, jsonTilesetTile{
+				ID:          t.ID,
+				Image:       t.Image,
+				ImageWidth:  t.ImageWidth,
+				ImageHeight: t.ImageHeight,
+				Properties:  toJSONProperties(t.Properties),
+			})
+		}
+
+		out.Tilesets = append(out.Tilesets, tileset)
+	}
+
+	for _, l := range m.Layers {
+		out.Layers = append(out.Layers, jsonLayer{
+			ID:      l.ID,
+			Name:    l.Name,
+			Type:    layerTypeTile,
+			Visible: l.Visible,
+			Opacity: 1,
+			Width:   l.Width,
+			Height:  l.Height,
+			Data:    l.Data,
+		})
+	}
+
+	for _, g := range m.ObjectGroups {
+		layer := jsonLayer{
+			ID:        g.ID,
+			Name:      g.Name,
+			Type:      layerTypeObjects,
+			Visible:   g.Visible,
+			Opacity:   1,
+			DrawOrder: drawOrderIndex,
+			Objects:   make([]jsonObject, 0, len(g.Objects)),
+		}
+
+		for _, o := range g.Objects {
+			layer.Objects = append(layer.Objects, jsonObject{
+				ID:         o.ID,
+				Name:       o.Name,
+				Type:       o.Type,
+				X:          o.X,
+				Y:          o.Y,
+				Width:      o.Width,
+				Height:     o.Height,
+				Visible:    true,
+				Point:      o.Point,
+				Polyline:   o.Polyline,
+				Properties: toJSONProperties(o.Properties),
+			})
+		}
+
+		out.Layers = append(out.Layers, layer)
+	}
+
+	return json.MarshalIndent(&out, "", " ")
+}
+
+// UnmarshalJSON decodes a map in the Tiled JSON format
+func UnmarshalJSON(data []byte) (*Map, error) {
+	in := jsonMap{}
+
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Orientation:  in.Orientation,
+		RenderOrder:  in.RenderOrder,
+		Width:        in.Width,
+		Height:       in.Height,
+		TileWidth:    in.TileWidth,
+		TileHeight:   in.TileHeight,
+		NextLayerID:  in.NextLayerID,
+		NextObjectID: in.NextObjectID,
+	}
+
+	var err error
+
+	if m.Properties, err = fromJSONProperties(in.Properties); err != nil {
+		return nil, err
+	}
+
+	for _, ts := range in.Tilesets {
+		tileset := Tileset{
+			FirstGID: ts.FirstGID, Name: ts.Name, TileWidth: ts.TileWidth, TileHeight: ts.TileHeight, Columns: ts.Columns,
+			Image: ts.Image, ImageWidth: ts.ImageWidth, ImageHeight: ts.ImageHeight,
+		}
+
+		for _, t := range ts.Tiles {
+			tile := Tile{ID: t.ID, Image: t.Image, ImageWidth: t.ImageWidth, ImageHeight: t.ImageHeight}
+
+			if tile.Properties, err = fromJSONProperties(t.Properties); err != nil {
+				return nil, fmt.Errorf("tileset %q: %v", ts.Name, err)
+			}
+
+			tileset.Tiles = append(tileset.Tiles, tile)
+		}
+
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+
+	for _, l := range in.Layers {
+		switch l.Type {
+		case layerTypeTile:
+			if len(l.Data) != l.Width*l.Height {
+				return nil, fmt.Errorf("layer %q: expected %d tiles, got %d", l.Name, l.Width*l.Height, len(l.Data))
+			}
+
+			m.Layers = append(m.Layers, TileLayer{
+				ID: l.ID, Name: l.Name, Width: l.Width, Height: l.Height, Visible: l.Visible, Data: l.Data,
+			})
+		case layerTypeObjects:
+			group := ObjectGroup{ID: l.ID, Name: l.Name, Visible: l.Visible}
+
+			for _, o := range l.Objects {
+				obj := Object{
+					ID: o.ID, Name: o.Name, Type: o.Type, X: o.X, Y: o.Y,
+					Width: o.Width, Height: o.Height, Point: o.Point, Polyline: o.Polyline,
+				}
+
+				if obj.Properties, err = fromJSONProperties(o.Properties); err != nil {
+					return nil, fmt.Errorf("object %d: %v", o.ID, err)
+				}
+
+				group.Objects = append(group.Objects, obj)
+			}
+
+			m.ObjectGroups = append(m.ObjectGroups, group)
+		}
+	}
+
+	return m, nil
+}
+
+func toJSONProperties(properties []Property) []jsonProperty {
+	result := make([]jsonProperty, 0, len(properties))
+
+	for _, p := range properties {
+		prop := jsonProperty{Name: p.Name, Type: p.Type, Value: p.Value}
+
+		switch p.Type {
+		case propertyInt, propertyObject:
+			if n, err := strconv.Atoi(p.Value); err == nil {
+				prop.Value = n
+			}
+		case propertyFloat:
+			if f, err := strconv.ParseFloat(p.Value, 64); err == nil {
+				prop.Value = f
+			}
+		case propertyBool:
+			if b, err := strconv.ParseBool(p.Value); err == nil {
+				prop.Value = b
+			}
+		}
+
+		result = append(result, prop)
+	}
+
+	return result
+}
+
+func fromJSONProperties(properties []jsonProperty) ([]Property, error) {
+	result := make([]Property, 0, len(properties))
+
+	for _, p := range properties {
+		prop := Property{Name: p.Name, Type: p.Type}
+
+		switch v := p.Value.(type) {
+		case string:
+			prop.Value = v
+		case bool:
+			prop.Value = strconv.FormatBool(v)
+		case float64:
+			prop.Value = formatFloat(v)
+		case nil:
+		default:
+			return nil, fmt.Errorf("property %q has an unsupported value %v", p.Name, v)
+		}
+
+		if prop.Type == "" {
+			prop.Type = propertyString
+		}
+
+		result = append(result, prop)
+	}
+
+	return result, nil
+}
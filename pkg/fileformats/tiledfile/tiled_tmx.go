@@ -0,0 +1,478 @@
+package d2tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+const (
+	encodingCSV    = "csv"
+	encodingBase64 = "base64"
+	compressZlib   = "zlib"
+	compressGzip   = "gzip"
+	bytesPerGID    = 4
+	hiddenFlag     = "0"
+)
+
+type tmxMap struct {
+	XMLName      xml.Name         `xml:"map"`
+	Version      string           `xml:"version,attr"`
+	TiledVersion string           `xml:"tiledversion,attr"`
+	Orientation  string           `xml:"orientation,attr"`
+	RenderOrder  string           `xml:"renderorder,attr"`
+	Width        int              `xml:"width,attr"`
+	Height       int              `xml:"height,attr"`
+	TileWidth    int              `xml:"tilewidth,attr"`
+	TileHeight   int              `xml:"tileheight,attr"`
+	Infinite     int              `xml:"infinite,attr"`
+	NextLayerID  int              `xml:"nextlayerid,attr"`
+	NextObjectID int              `xml:"nextobjectid,attr"`
+	Properties   []tmxProperty    `xml:"properties>property"`
+	Tilesets     []tmxTileset     `xml:"tileset"`
+	Layers       []tmxLayer       `xml:"layer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type tmxTileset struct {
+	FirstGID   uint32    `xml:"firstgid,attr"`
+	Name       string    `xml:"name,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	TileCount  int       `xml:"tilecount,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Image      *tmxImage `xml:"image"`
+	Tiles      []tmxTile `xml:"tile"`
+}
+
+type tmxTile struct {
+	ID         uint32        `xml:"id,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Image      *tmxImage     `xml:"image"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxLayer struct {
+	ID      int     `xml:"id,attr"`
+	Name    string  `xml:"name,attr"`
+	Width   int     `xml:"width,attr"`
+	Height  int     `xml:"height,attr"`
+	Visible string  `xml:"visible,attr,omitempty"`
+	Data    tmxData `xml:"data"`
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr,omitempty"`
+	Compression string `xml:"compression,attr,omitempty"`
+	Content     string `xml:",chardata"`
+}
+
+type tmxObjectGroup struct {
+	ID        int         `xml:"id,attr"`
+	Name      string      `xml:"name,attr"`
+	Visible   string      `xml:"visible,attr,omitempty"`
+	DrawOrder string      `xml:"draworder,attr,omitempty"`
+	Objects   []tmxObject `xml:"object"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr,omitempty"`
+	Type       string        `xml:"type,attr,omitempty"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr,omitempty"`
+	Height     float64       `xml:"height,attr,omitempty"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Point      *struct{}     `xml:"point"`
+	Polyline   *tmxPolyline  `xml:"polyline"`
+}
+
+type tmxPolyline struct {
+	Points string `xml:"points,attr"`
+}
+
+// MarshalTMX encodes the map in the TMX (XML) format
+func (m *Map) MarshalTMX() ([]byte, error) {
+	out := tmxMap{
+		Version:      mapVersion,
+		TiledVersion: tiledVersion,
+		Orientation:  m.Orientation,
+		RenderOrder:  m.RenderOrder,
+		Width:        m.Width,
+		Height:       m.Height,
+		TileWidth:    m.TileWidth,
+		TileHeight:   m.TileHeight,
+		NextLayerID:  m.NextLayerID,
+		NextObjectID: m.NextObjectID,
+		Properties:   toTMXProperties(m.Properties),
+	}
+
+	for _, ts := range m.Tilesets {
+		tileset := tmxTileset{
+			FirstGID:   ts.FirstGID,
+			Name:       ts.Name,
+			TileWidth:  ts.TileWidth,
+			TileHeight: ts.TileHeight,
+			TileCount:  len(ts.Tiles),
+			Columns:    ts.Columns,
+		}
+
+		if ts.Image != "" {
+			tileset.Image = &tmxImage{Source: ts.Image, Width: ts.ImageWidth, Height: ts.ImageHeight}
+		}
+
+		for _, t := range ts.Tiles {
+			tile := tmxTile{ID: t.ID, Properties: toTMXProperties(t.Properties)}
+
+			if t.Image != "" {
+				tile.Image = &tmxImage{Source: t.Image, Width: t.ImageWidth, Height: t.ImageHeight}
+			}
+
+			tileset.Tiles = append(tileset.Tiles, tile)
+		}
+
+		out.Tilesets = append(out.Tilesets, tileset)
+	}
+
+	for _, l := range m.Layers {
+		gids := make([]string, len(l.Data))
+		for idx, gid := range l.Data {
+			gids[idx] = strconv.FormatUint(uint64(gid), 10)
+		}
+
+		out.Layers = append(out.Layers, tmxLayer{
+			ID:      l.ID,
+			Name:    l.Name,
+			Width:   l.Width,
+			Height:  l.Height,
+			Visible: visibleAttr(l.Visible),
+			Data:    tmxData{Encoding: encodingCSV, Content: csvRows(gids, l.Width)},
+		})
+	}
+
+	for _, g := range m.ObjectGroups {
+		group := tmxObjectGroup{ID: g.ID, Name: g.Name, Visible: visibleAttr(g.Visible), DrawOrder: drawOrderIndex}
+
+		for idx := range g.Objects {
+			group.Objects = append(group.Objects, toTMXObject(&g.Objects[idx]))
+		}
+
+		out.ObjectGroups = append(out.ObjectGroups, group)
+	}
+
+	data, err := xml.MarshalIndent(&out, "", " ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// UnmarshalTMX decodes a map in the TMX (XML) format
+func UnmarshalTMX(data []byte) (*Map, error) {
+	in := tmxMap{}
+
+	if err := xml.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Orientation:  in.Orientation,
+		RenderOrder:  in.RenderOrder,
+		Width:        in.Width,
+		Height:       in.Height,
+		TileWidth:    in.TileWidth,
+		TileHeight:   in.TileHeight,
+		NextLayerID:  in.NextLayerID,
+		NextObjectID: in.NextObjectID,
+		Properties:   fromTMXProperties(in.Properties),
+	}
+
+	for _, ts := range in.Tilesets {
+		tileset := Tileset{
+			FirstGID: ts.FirstGID, Name: ts.Name, TileWidth: ts.TileWidth, TileHeight: ts.TileHeight, Columns: ts.Columns,
+		}
+
+		if ts.Image != nil {
+			tileset.Image, tileset.ImageWidth, tileset.ImageHeight = ts.Image.Source, ts.Image.Width, ts.Image.Height
+		}
+
+		for _, t := range ts.Tiles {
+			tile := Tile{ID: t.ID, Properties: fromTMXProperties(t.Properties)}
+
+			if t.Image != nil {
+				tile.Image, tile.ImageWidth, tile.ImageHeight = t.Image.Source, t.Image.Width, t.Image.Height
+			}
+
+			tileset.Tiles = append(tileset.Tiles, tile)
+		}
+
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+
+	for _, l := range in.Layers {
+		gids, err := decodeTMXData(&l.Data, l.Width*l.Height)
+		if err != nil {
+			return nil, fmt.Errorf("layer %q: %v", l.Name, err)
+		}
+
+		m.Layers = append(m.Layers, TileLayer{
+			ID:      l.ID,
+			Name:    l.Name,
+			Width:   l.Width,
+			Height:  l.Height,
+			Visible: l.Visible != hiddenFlag,
+			Data:    gids,
+		})
+	}
+
+	for _, g := range in.ObjectGroups {
+		group := ObjectGroup{ID: g.ID, Name: g.Name, Visible: g.Visible != hiddenFlag}
+
+		for idx := range g.Objects {
+			obj, err := fromTMXObject(&g.Objects[idx])
+			if err != nil {
+				return nil, fmt.Errorf("object group %q: %v", g.Name, err)
+			}
+
+			group.Objects = append(group.Objects, obj)
+		}
+
+		m.ObjectGroups = append(m.ObjectGroups, group)
+	}
+
+	return m, nil
+}
+
+func toTMXObject(o *Object) tmxObject {
+	obj := tmxObject{
+		ID:         o.ID,
+		Name:       o.Name,
+		Type:       o.Type,
+		X:          o.X,
+		Y:          o.Y,
+		Width:      o.Width,
+		Height:     o.Height,
+		Properties: toTMXProperties(o.Properties),
+	}
+
+	if o.Point {
+		obj.Point = &struct{}{}
+	}
+
+	if o.Polyline != nil {
+		points := make([]string, len(o.Polyline))
+		for idx, p := range o.Polyline {
+			points[idx] = formatFloat(p.X) + "," + formatFloat(p.Y)
+		}
+
+		obj.Polyline = &tmxPolyline{Points: strings.Join(points, " ")}
+	}
+
+	return obj
+}
+
+func fromTMXObject(o *tmxObject) (Object, error) {
+	obj := Object{
+		ID:         o.ID,
+		Name:       o.Name,
+		Type:       o.Type,
+		X:          o.X,
+		Y:          o.Y,
+		Width:      o.Width,
+		Height:     o.Height,
+		Point:      o.Point != nil,
+		Properties: fromTMXProperties(o.Properties),
+	}
+
+	if o.Polyline == nil {
+		return obj, nil
+	}
+
+	obj.Polyline = make([]Point, 0)
+
+	for _, pair := range strings.Fields(o.Polyline.Points) {
+		coords := strings.Split(pair, ",")
+		if len(coords) != 2 { //nolint:gomnd // x,y
+			return obj, fmt.Errorf("object %d: invalid polyline point %q", o.ID, pair)
+		}
+
+		x, err := strconv.ParseFloat(coords[0], 64)
+		if err != nil {
+			return obj, fmt.Errorf("object %d: %v", o.ID, err)
+		}
+
+		y, err := strconv.ParseFloat(coords[1], 64)
+		if err != nil {
+			return obj, fmt.Errorf("object %d: %v", o.ID, err)
+		}
+
+		obj.Polyline = append(obj.Polyline, Point{X: x, Y: y})
+	}
+
+	return obj, nil
+}
+
+func decodeTMXData(data *tmxData, count int) ([]uint32, error) {
+	gids := make([]uint32, 0, count)
+
+	switch data.Encoding {
+	case encodingCSV:
+		for _, field := range strings.Split(data.Content, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+
+			gids = append(gids, uint32(gid))
+		}
+	case encodingBase64:
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data.Content))
+		if err != nil {
+			return nil, err
+		}
+
+		if raw, err = decompress(raw, data.Compression); err != nil {
+			return nil, err
+		}
+
+		for idx := 0; idx+bytesPerGID <= len(raw); idx += bytesPerGID {
+			gids = append(gids, binary.LittleEndian.Uint32(raw[idx:]))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported layer data encoding %q", data.Encoding)
+	}
+
+	if len(gids) != count {
+		return nil, fmt.Errorf("expected %d tiles, got %d", count, len(gids))
+	}
+
+	return gids, nil
+}
+
+func decompress(raw []byte, compression string) ([]byte, error) {
+	var (
+		r   io.Reader
+		err error
+	)
+
+	switch compression {
+	case "":
+		return raw, nil
+	case compressZlib:
+		r, err = zlib.NewReader(bytes.NewReader(raw))
+	case compressGzip:
+		r, err = gzip.NewReader(bytes.NewReader(raw))
+	default:
+		return nil, fmt.Errorf("unsupported layer data compression %q", compression)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadAll(r)
+}
+
+func toTMXProperties(properties []Property) []tmxProperty {
+	result := make([]tmxProperty, 0, len(properties))
+
+	for _, p := range properties {
+		prop := tmxProperty{Name: p.Name, Type: p.Type, Value: p.Value}
+
+		if p.Type == propertyString {
+			// string is the default type
+			prop.Type = ""
+		}
+
+		result = append(result, prop)
+	}
+
+	return result
+}
+
+func fromTMXProperties(properties []tmxProperty) []Property {
+	result := make([]Property, 0, len(properties))
+
+	for _, p := range properties {
+		prop := Property{Name: p.Name, Type: p.Type, Value: p.Value}
+
+		if prop.Type == "" {
+			prop.Type = propertyString
+		}
+
+		if prop.Value == "" {
+			// multi-line strings are stored as the element text
+			prop.Value = p.Text
+		}
+
+		result = append(result, prop)
+	}
+
+	return result
+}
+
+func csvRows(gids []string, width int) string {
+	if width <= 0 {
+		return strings.Join(gids, ",")
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("\n")
+
+	for idx := 0; idx < len(gids); idx += width {
+		end := idx + width
+		if end > len(gids) {
+			end = len(gids)
+		}
+
+		sb.WriteString(strings.Join(gids[idx:end], ","))
+
+		if end < len(gids) {
+			sb.WriteString(",")
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func visibleAttr(visible bool) string {
+	if visible {
+		return ""
+	}
+
+	return hiddenFlag
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,234 @@
+package d2txt
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	fieldSeparator = "\t"
+	lineSeparator  = "\r\n"
+	invalidChars   = "\t\r\n"
+)
+
+// Marshaler is implemented by types that encode themselves as a field
+type Marshaler interface {
+	MarshalTXT() (string, error)
+}
+
+// Table is the raw content of a txt file, the header and the rows in file
+// order, Expansion rows included
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// ReadTable reads a txt file without interpreting its fields
+func ReadTable(data []byte) (*Table, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma = '\t'
+	cr.LazyQuotes = true
+	cr.FieldsPerRecord = -1
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("couldn't read the header: empty file")
+	}
+
+	return &Table{Header: records[0], Rows: records[1:]}, nil
+}
+
+// Marshal encodes the table as a tab separated file with CRLF line endings,
+// the way the game files are stored. Short rows are padded with empty fields.
+func (t *Table) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := writeRow(&buf, headerRowNumber, t.Header, len(t.Header)); err != nil {
+		return nil, err
+	}
+
+	for idx, row := range t.Rows {
+		if err := writeRow(&buf, headerRowNumber+idx+1, row, len(t.Header)); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeRow(buf *bytes.Buffer, row int, fields []string, width int) error {
+	for idx, field := range fields {
+		if strings.ContainsAny(field, invalidChars) {
+			return fmt.Errorf("row %d, field %d: %q contains a tab or a line break", row, idx+1, field)
+		}
+	}
+
+	if len(fields) < width {
+		fields = append(fields, make([]string, width-len(fields))...)
+	}
+
+	buf.WriteString(strings.Join(fields, fieldSeparator))
+	buf.WriteString(lineSeparator)
+
+	return nil
+}
+
+// Marshal encodes a slice of tagged structs as a txt file with the given
+// columns, see Table.Encode
+func Marshal(header []string, v interface{}) ([]byte, error) {
+	table := &Table{Header: header}
+
+	if err := table.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return table.Marshal()
+}
+
+// Encode replaces the records of the table with v, a slice of the tagged
+// structs read by Decoder. The table keeps its column order and its Expansion
+// rows, which stay in front of the same record. The record at index i updates
+// the i-th row that is not an Expansion row: columns without a field and
+// fields whose value did not change keep their text, so that the file only
+// changes where the records do. Extra records are appended, rows without a
+// record are removed.
+func (t *Table) Encode(v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() == reflect.Ptr {
+		slice = slice.Elem()
+	}
+
+	if slice.Kind() != reflect.Slice || slice.Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a slice of structs, got %T", v)
+	}
+
+	fields, err := (&Decoder{}).mapColumns(t.Header, slice.Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(t.Rows))
+	next := 0
+
+	encode := func(base []string) error {
+		row, err := t.encodeRow(slice.Index(next), fields, base, len(rows)+headerRowNumber+1)
+		if err != nil {
+			return err
+		}
+
+		rows = append(rows, row)
+		next++
+
+		return nil
+	}
+
+	for _, row := range t.Rows {
+		if len(row) > 0 && row[0] == expansionRow {
+			rows = append(rows, row)
+			continue
+		}
+
+		if next == slice.Len() {
+			continue
+		}
+
+		if err := encode(row); err != nil {
+			return err
+		}
+	}
+
+	for next < slice.Len() {
+		if err := encode(nil); err != nil {
+			return err
+		}
+	}
+
+	t.Rows = rows
+
+	return nil
+}
+
+func (t *Table) encodeRow(record reflect.Value, fields []columnField, base []string, rowNumber int) ([]string, error) {
+	row := make([]string, len(t.Header))
+	copy(row, base)
+
+	for _, f := range fields {
+		value := f.value(record)
+
+		if base != nil && f.column < len(base) && unchanged(value, base[f.column]) {
+			continue
+		}
+
+		text, err := encodeField(value)
+		if err != nil {
+			return nil, &FieldError{Row: rowNumber, Column: f.name, Err: err}
+		}
+
+		row[f.column] = text
+	}
+
+	return row, nil
+}
+
+// unchanged reports whether the text decodes to the value
+func unchanged(value reflect.Value, text string) bool {
+	original := reflect.New(value.Type()).Elem()
+	if err := decodeField(original, text); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(original.Interface(), value.Interface())
+}
+
+func encodeField(v reflect.Value) (string, error) {
+	if v.CanAddr() {
+		switch m := v.Addr().Interface().(type) {
+		case Marshaler:
+			return m.MarshalTXT()
+		case encoding.TextMarshaler:
+			text, err := m.MarshalText()
+			return string(text), err
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "1", nil
+		}
+
+		return "0", nil
+	case reflect.Slice:
+		items := make([]string, v.Len())
+
+		for idx := range items {
+			text, err := encodeField(v.Index(idx))
+			if err != nil {
+				return "", fmt.Errorf("item %d: %v", idx, err)
+			}
+
+			items[idx] = text
+		}
+
+		return strings.Join(items, listSeparator), nil
+	}
+
+	return "", fmt.Errorf("unsupported field type %s", v.Type())
+}
@@ -0,0 +1,91 @@
+package d2txt
+
+import (
+	"strings"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func (c testCode) MarshalTXT() (string, error) {
+	return strings.ToLower(string(c)), nil
+}
+
+func TestTable_MarshalRoundTrip(t *testing.T) {
+	assert := testify.New(t)
+	data := testTable(
+		"Fallen\t2\t0.50\t1\tfa\tdemon,undead\t1, 2\t0",
+		"Expansion\t\t\t\t\t\t\t",
+		"Imp\t\t\t0\t\t\t\t0",
+	)
+
+	table, err := ReadTable(data)
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Len(table.Rows, 3)
+
+	written, err := table.Marshal()
+	if assert.NoError(err) {
+		assert.Equal(string(data), string(written))
+	}
+}
+
+func TestTable_Encode(t *testing.T) {
+	assert := testify.New(t)
+	data := testTable(
+		"Fallen\t2\t0.50\t1\tfa\tdemon,undead\t1, 2\t0",
+		"Expansion",
+		"Imp\t\t\t0\t\t\t\t0",
+		"Removed\t\t\t0\t\t\t\t0",
+	)
+
+	var records []testRecord
+	if !assert.NoError(Unmarshal(data, &records)) {
+		return
+	}
+
+	table, err := ReadTable(data)
+	if !assert.NoError(err) {
+		return
+	}
+
+	records[1].Level = 5
+	records[2] = testRecord{Name: "Added", Chance: 0.25, Spawned: true, Code: "AD", Sizes: []uint8{3, 4}}
+
+	if !assert.NoError(table.Encode(records)) {
+		return
+	}
+
+	written, err := table.Marshal()
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal(string(testTable(
+		"Fallen\t2\t0.50\t1\tfa\tdemon,undead\t1, 2\t0",
+		"Expansion\t\t\t\t\t\t\t",
+		"Imp\t5\t\t0\t\t\t\t0",
+		"Added\t\t0.25\t1\tad\t\t3,4\t0",
+	)), string(written), "unchanged fields and unmapped columns keep their text")
+
+	table.Header[0] = "Renamed"
+	assert.Error(table.Encode(records), "missing column")
+}
+
+func TestMarshal(t *testing.T) {
+	assert := testify.New(t)
+	header := []string{"code", "Name", "level", "chance", "spawn", "types", "sizes"}
+	records := []testRecord{{Name: "Fallen", Level: 1, Code: "FA", Types: []string{"a", "b"}}}
+
+	data, err := Marshal(header, records)
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal("code\tName\tlevel\tchance\tspawn\ttypes\tsizes\r\nfa\tFallen\t1\t0\t0\ta,b\t\r\n", string(data))
+
+	_, err = Marshal(header, []testRecord{{Name: "tab\there"}})
+	assert.Error(err)
+}
@@ -0,0 +1,335 @@
+package d2txt
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	tagName         = "txt"
+	tagIgnore       = "-"
+	tagOptional     = "optional"
+	tagBase         = "base="
+	tagIndex        = "%d"
+	expansionRow    = "Expansion"
+	listSeparator   = ","
+	headerRowNumber = 1
+)
+
+// Unmarshaler is implemented by types that decode themselves from a field
+type Unmarshaler interface {
+	UnmarshalTXT(field string) error
+}
+
+// FieldError is the error for a column of a row, rows are numbered from 1,
+// the header being row 1
+type FieldError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("row %d, column %q: %v", e.Row, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors reported in FieldError
+var (
+	ErrMissingColumn   = errors.New("missing column")
+	ErrUnknownColumn   = errors.New("unknown column")
+	ErrDuplicateColumn = errors.New("duplicate column")
+)
+
+// Decoder reads the rows of a txt file into structs. The struct fields are
+// matched to the columns by their `txt:"ColumnName"` tags, fields without a
+// tag are skipped, tagged fields must be exported. A column that is missing
+// from the file is an error unless the tag has the optional flag, as in
+// `txt:"ColumnName,optional"`, and so is a column read by a field that
+// appears more than once in the header.
+//
+// Array fields read numbered columns, the %d in their tag is replaced with
+// the array index plus the base option, `txt:"prop%d,base=1"` reads the
+// columns prop1, prop2 and so on.
+//
+// Supported field types are strings, integers, floats, bools, types that
+// implement Unmarshaler or encoding.TextUnmarshaler, and slices of those,
+// which are read from comma separated lists. Empty fields are zero values.
+type Decoder struct {
+	data []byte
+
+	// SkipExpansion skips the rows that separate the classic and expansion
+	// records, they have Expansion in their first column
+	SkipExpansion bool
+
+	// DisallowUnknownColumns makes columns without a matching field an error
+	DisallowUnknownColumns bool
+}
+
+// NewDecoder creates a decoder for the given txt file, Expansion rows are
+// skipped by default
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{
+		data:          data,
+		SkipExpansion: true,
+	}
+}
+
+// Unmarshal reads the rows of a txt file into v, which must be a pointer to a
+// slice of structs, see Decoder
+func Unmarshal(data []byte, v interface{}) error {
+	return NewDecoder(data).Decode(v)
+}
+
+// columnField maps a column to the index of a struct field, and to the index
+// of an array element for numbered columns
+type columnField struct {
+	name    string
+	column  int
+	field   int
+	element int
+}
+
+func (f *columnField) value(record reflect.Value) reflect.Value {
+	v := record.Field(f.field)
+	if f.element < 0 {
+		return v
+	}
+
+	return v.Index(f.element)
+}
+
+// Decode appends the rows of the txt file to v, which must be a pointer to a
+// slice of structs
+func (d *Decoder) Decode(v interface{}) error {
+	slice := reflect.ValueOf(v)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice ||
+		slice.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a pointer to a slice of structs, got %T", v)
+	}
+
+	slice = slice.Elem()
+	recordType := slice.Type().Elem()
+
+	cr := csv.NewReader(bytes.NewReader(d.data))
+	cr.Comma = '\t'
+	cr.LazyQuotes = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("couldn't read the header: %v", err)
+	}
+
+	fields, err := d.mapColumns(header, recordType)
+	if err != nil {
+		return err
+	}
+
+	for row := headerRowNumber + 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if d.SkipExpansion && len(record) > 0 && record[0] == expansionRow {
+			continue
+		}
+
+		value := reflect.New(recordType).Elem()
+
+		for _, f := range fields {
+			if f.column >= len(record) {
+				return &FieldError{Row: row, Column: f.name, Err: ErrMissingColumn}
+			}
+
+			if err := decodeField(f.value(value), record[f.column]); err != nil {
+				return &FieldError{Row: row, Column: f.name, Err: err}
+			}
+		}
+
+		slice.Set(reflect.Append(slice, value))
+	}
+}
+
+func (d *Decoder) mapColumns(header []string, recordType reflect.Type) ([]columnField, error) {
+	columns := make(map[string]int, len(header))
+	duplicates := make(map[string]bool)
+
+	for idx, name := range header {
+		if _, found := columns[name]; found {
+			duplicates[name] = true
+		}
+
+		columns[name] = idx
+	}
+
+	fields := make([]columnField, 0)
+	used := make(map[string]bool)
+
+	for idx := 0; idx < recordType.NumField(); idx++ {
+		field := recordType.Field(idx)
+
+		tag, found := field.Tag.Lookup(tagName)
+		if !found || tag == tagIgnore {
+			continue
+		}
+
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("field %s: unexported fields can't be decoded", field.Name)
+		}
+
+		name, optional, base, err := parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", field.Name, err)
+		}
+
+		names, elements := []string{name}, []int{-1}
+
+		if strings.Contains(name, tagIndex) {
+			if field.Type.Kind() != reflect.Array {
+				return nil, fmt.Errorf("field %s: numbered columns need an array field", field.Name)
+			}
+
+			names, elements = make([]string, field.Type.Len()), make([]int, field.Type.Len())
+
+			for element := range names {
+				names[element] = strings.Replace(name, tagIndex, strconv.Itoa(base+element), 1)
+				elements[element] = element
+			}
+		}
+
+		for i, name := range names {
+			column, found := columns[name]
+			if !found {
+				if optional {
+					continue
+				}
+
+				return nil, &FieldError{Row: headerRowNumber, Column: name, Err: ErrMissingColumn}
+			}
+
+			if duplicates[name] {
+				return nil, &FieldError{Row: headerRowNumber, Column: name, Err: ErrDuplicateColumn}
+			}
+
+			used[name] = true
+
+			fields = append(fields, columnField{name: name, column: column, field: idx, element: elements[i]})
+		}
+	}
+
+	if d.DisallowUnknownColumns {
+		for _, name := range header {
+			if !used[name] {
+				return nil, &FieldError{Row: headerRowNumber, Column: name, Err: ErrUnknownColumn}
+			}
+		}
+	}
+
+	return fields, nil
+}
+
+func parseTag(tag string) (name string, optional bool, base int, err error) {
+	parts := strings.Split(tag, ",")
+
+	for _, option := range parts[1:] {
+		switch {
+		case option == tagOptional:
+			optional = true
+		case strings.HasPrefix(option, tagBase):
+			if base, err = strconv.Atoi(strings.TrimPrefix(option, tagBase)); err != nil {
+				return "", false, 0, fmt.Errorf("invalid tag option %q", option)
+			}
+		default:
+			return "", false, 0, fmt.Errorf("unknown tag option %q", option)
+		}
+	}
+
+	return parts[0], optional, base, nil
+}
+
+func decodeField(v reflect.Value, field string) error {
+	if v.CanAddr() {
+		switch u := v.Addr().Interface().(type) {
+		case Unmarshaler:
+			return u.UnmarshalTXT(field)
+		case encoding.TextUnmarshaler:
+			return u.UnmarshalText([]byte(field))
+		}
+	}
+
+	if field == "" {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(field)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(field, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", field)
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(field, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", field)
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(field, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", field)
+		}
+
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(field)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", field)
+		}
+
+		v.SetBool(b)
+	case reflect.Slice:
+		return decodeList(v, field)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+
+	return nil
+}
+
+func decodeList(v reflect.Value, field string) error {
+	items := strings.Split(field, listSeparator)
+	list := reflect.MakeSlice(v.Type(), len(items), len(items))
+
+	for idx, item := range items {
+		if err := decodeField(list.Index(idx), strings.TrimSpace(item)); err != nil {
+			return fmt.Errorf("item %d: %v", idx, err)
+		}
+	}
+
+	v.Set(list)
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package d2txt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+type testCode string
+
+func (c *testCode) UnmarshalTXT(field string) error {
+	*c = testCode(strings.ToUpper(field))
+	return nil
+}
+
+type testRecord struct {
+	Name     string   `txt:"Name"`
+	Level    int      `txt:"level"`
+	Chance   float64  `txt:"chance"`
+	Spawned  bool     `txt:"spawn"`
+	Code     testCode `txt:"code"`
+	Types    []string `txt:"types"`
+	Sizes    []uint8  `txt:"sizes"`
+	Comment  string   `txt:"*comment,optional"`
+	internal int
+}
+
+func testTable(rows ...string) []byte {
+	header := "Name\tlevel\tchance\tspawn\tcode\ttypes\tsizes\teol"
+
+	return []byte(strings.Join(append([]string{header}, rows...), "\r\n") + "\r\n")
+}
+
+func TestUnmarshal(t *testing.T) {
+	assert := testify.New(t)
+	data := testTable(
+		"Fallen\t2\t0.5\t1\tfa\tdemon,undead\t1, 2\t0",
+		"Expansion",
+		"Imp\t\t\t0\t\t\t\t0",
+	)
+
+	var records []testRecord
+
+	if !assert.NoError(Unmarshal(data, &records)) {
+		return
+	}
+
+	assert.Equal([]testRecord{
+		{Name: "Fallen", Level: 2, Chance: 0.5, Spawned: true, Code: "FA",
+			Types: []string{"demon", "undead"}, Sizes: []uint8{1, 2}},
+		{Name: "Imp"},
+	}, records)
+}
+
+func TestDecoder_KeepExpansion(t *testing.T) {
+	data := testTable("Expansion\t1\t0\t0\tex\t\t\t0")
+
+	var records []testRecord
+
+	decoder := NewDecoder(data)
+	decoder.SkipExpansion = false
+
+	if testify.NoError(t, decoder.Decode(&records)) && testify.Len(t, records, 1) {
+		testify.Equal(t, "Expansion", records[0].Name)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	assert := testify.New(t)
+
+	var records []testRecord
+
+	err := Unmarshal(testTable("Fallen\ttwo\t0\t0\t\t\t\t0"), &records)
+
+	var fieldErr *FieldError
+	if assert.True(errors.As(err, &fieldErr), "%v", err) {
+		assert.Equal(2, fieldErr.Row)
+		assert.Equal("level", fieldErr.Column)
+	}
+
+	err = Unmarshal(testTable("Fallen\t1\t0\t0\t\t\t1,x\t0"), &records)
+	assert.EqualError(err, `row 2, column "sizes": item 1: invalid unsigned integer "x"`)
+
+	err = Unmarshal(testTable("Fallen\t1\t0\t0"), &records)
+	assert.EqualError(err, `row 2, column "code": missing column`)
+
+	err = Unmarshal([]byte("Name\tlevl\n"), &records)
+	assert.True(errors.Is(err, ErrMissingColumn))
+	assert.EqualError(err, `row 1, column "level": missing column`)
+
+	decoder := NewDecoder(testTable())
+	decoder.DisallowUnknownColumns = true
+	err = decoder.Decode(&records)
+	assert.True(errors.Is(err, ErrUnknownColumn))
+	assert.EqualError(err, `row 1, column "eol": unknown column`)
+
+	err = Unmarshal([]byte("Name\tlevel\tName\n"), &records)
+	assert.True(errors.Is(err, ErrDuplicateColumn))
+	assert.EqualError(err, `row 1, column "Name": duplicate column`)
+
+	duplicateComments := []byte("Name\tlevel\tchance\tspawn\tcode\ttypes\tsizes\teol\teol\r\n")
+	assert.NoError(Unmarshal(duplicateComments, &records), "columns without a field may repeat")
+
+	var unexported []struct {
+		name string `txt:"Name"`
+	}
+
+	assert.EqualError(Unmarshal(testTable(), &unexported), "field name: unexported fields can't be decoded")
+
+	assert.Error(Unmarshal(testTable(), records), "not a pointer")
+	assert.Error(Unmarshal(nil, &records), "no header")
+}
+
+func TestUnmarshal_NumberedColumns(t *testing.T) {
+	assert := testify.New(t)
+
+	type record struct {
+		Files [3]string `txt:"File %d,base=1"`
+		Vis   [2]int    `txt:"Vis%d"`
+		Extra [2]int    `txt:"Extra%d,optional"`
+	}
+
+	var records []record
+
+	data := []byte("File 1\tFile 2\tFile 3\tVis0\tVis1\tExtra0\r\na.dt1\tb.dt1\t0\t4\t-1\t7\r\n")
+	if assert.NoError(Unmarshal(data, &records)) && assert.Len(records, 1) {
+		assert.Equal([3]string{"a.dt1", "b.dt1", "0"}, records[0].Files)
+		assert.Equal([2]int{4, -1}, records[0].Vis)
+		assert.Equal([2]int{7, 0}, records[0].Extra, "Extra1 is optional")
+	}
+
+	err := Unmarshal([]byte("File 1\tVis0\tVis1\r\n"), &records)
+	assert.EqualError(err, `row 1, column "File 2": missing column`)
+}
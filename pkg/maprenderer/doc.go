@@ -0,0 +1,3 @@
+// Package maprenderer renders DS1 maps and their DT1 tiles to images in
+// software, without the need for a graphics backend.
+package maprenderer
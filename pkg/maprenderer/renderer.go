@@ -0,0 +1,382 @@
+package maprenderer
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2interface"
+
+	d2ds1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/ds1file"
+	d2dt1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/dt1file"
+)
+
+const (
+	tileWidth       = 160
+	tileHeight      = 80
+	halfTileWidth   = tileWidth / 2
+	halfTileHeight  = tileHeight / 2
+	subTilesPerTile = 5
+	numColors       = 256
+	shadowAlpha     = 0x80
+	opaque          = 0xff
+)
+
+// ObjectDrawer draws a DS1 object onto the image, x and y are the position of
+// the object in image coordinates.
+type ObjectDrawer func(img *image.RGBA, obj *d2ds1.Object, x, y int)
+
+// Renderer renders DS1 maps using the tiles of a set of DT1 files.
+type Renderer struct {
+	// ObjectDrawer is called for every object of the map, objects are not drawn when it is nil
+	ObjectDrawer ObjectDrawer
+
+	// Seed selects the tile variants, rendering a map with the same seed always gives the same image
+	Seed uint64
+
+	palette [numColors]color.RGBA
+	tiles   *d2dt1.TileLibrary
+	sprites map[spriteKey]*sprite
+}
+
+type spriteKey struct {
+	tile     *d2dt1.Tile
+	tileType d2enum.TileType
+}
+
+// drawOp is a sprite or an object placed on the screen, in map screen coordinates
+type drawOp struct {
+	sprite *sprite
+	object *d2ds1.Object
+	x, y   int
+	shadow bool
+}
+
+// New creates a renderer for the tiles of the given DT1 files
+func New(dt1s []*d2dt1.DT1, palette d2interface.Palette) (*Renderer, error) {
+	if palette == nil {
+		return nil, errors.New("a palette is required")
+	}
+
+	r := &Renderer{
+		tiles:   d2dt1.NewTileLibrary(dt1s...),
+		sprites: make(map[spriteKey]*sprite),
+	}
+
+	for idx := 0; idx < numColors && idx < palette.NumColors(); idx++ {
+		c, err := palette.GetColor(idx)
+		if err != nil {
+			return nil, err
+		}
+
+		r.palette[idx] = color.RGBA{R: c.R(), G: c.G(), B: c.B(), A: opaque}
+	}
+
+	return r, nil
+}
+
+// Render renders the whole map. Floors are drawn first, then shadows, lower
+// walls, objects and upper walls and finally roofs. Objects and upper walls are
+// drawn together from the back of the map to the front so that walls hide the
+// objects behind them.
+func (r *Renderer) Render(ds1 *d2ds1.DS1) (*image.RGBA, error) {
+	if ds1.Width < 1 || ds1.Height < 1 || len(ds1.Tiles) != int(ds1.Height) {
+		return nil, errors.New("invalid DS1 dimensions")
+	}
+
+	for y := range ds1.Tiles {
+		if len(ds1.Tiles[y]) != int(ds1.Width) {
+			return nil, fmt.Errorf("invalid DS1 dimensions: row %d has %d tiles instead of %d",
+				y, len(ds1.Tiles[y]), ds1.Width)
+		}
+	}
+
+	ops := r.floorOps(ds1)
+	ops = append(ops, r.shadowOps(ds1)...)
+	ops = append(ops, r.wallOps(ds1, isLowerWall)...)
+
+	objects := objectsByDiagonal(ds1)
+
+	forEachDiagonal(ds1, func(diagonal int, tiles []image.Point) {
+		for _, obj := range objects[diagonal] {
+			x, y := objectPosition(obj)
+			ops = append(ops, drawOp{object: obj, x: x, y: y})
+		}
+
+		for _, p := range tiles {
+			ops = append(ops, r.tileWallOps(ds1, p.X, p.Y, isUpperWall)...)
+		}
+	})
+
+	ops = append(ops, r.wallOps(ds1, isRoof)...)
+
+	bounds := mapBounds(ds1)
+
+	for _, op := range ops {
+		if op.sprite != nil {
+			bounds = bounds.Union(op.rect())
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	for _, op := range ops {
+		if op.object == nil {
+			r.draw(img, op, bounds.Min)
+		} else if r.ObjectDrawer != nil {
+			r.ObjectDrawer(img, op.object, op.x-bounds.Min.X, op.y-bounds.Min.Y)
+		}
+	}
+
+	return img, nil
+}
+
+func (r *Renderer) floorOps(ds1 *d2ds1.DS1) []drawOp {
+	var ops []drawOp
+
+	for layer := 0; layer < int(ds1.NumberOfFloors); layer++ {
+		for y := range ds1.Tiles {
+			for x := range ds1.Tiles[y] {
+				if layer >= len(ds1.Tiles[y][x].Floors) {
+					continue
+				}
+
+				floor := &ds1.Tiles[y][x].Floors[layer]
+				if floor.Prop1 == 0 || floor.Hidden {
+					continue
+				}
+
+				tile := r.findTile(d2enum.TileFloor, floor.Style, floor.Sequence,
+					d2dt1.CellSeed(r.Seed, x, y, d2dt1.FloorSeedLayer+layer))
+				if tile == nil {
+					continue
+				}
+
+				sx, sy := screenPosition(x, y)
+				ops = append(ops, drawOp{sprite: r.sprite(tile, d2enum.TileFloor), x: sx, y: sy})
+			}
+		}
+	}
+
+	return ops
+}
+
+func (r *Renderer) shadowOps(ds1 *d2ds1.DS1) []drawOp {
+	var ops []drawOp
+
+	for y := range ds1.Tiles {
+		for x := range ds1.Tiles[y] {
+			for idx := range ds1.Tiles[y][x].Shadows {
+				shadow := &ds1.Tiles[y][x].Shadows[idx]
+				if shadow.Prop1 == 0 || shadow.Hidden {
+					continue
+				}
+
+				tile := r.findTile(d2enum.TileShadow, shadow.Style, shadow.Sequence,
+					d2dt1.CellSeed(r.Seed, x, y, d2dt1.ShadowSeedLayer+idx))
+				if tile == nil {
+					continue
+				}
+
+				sx, sy := screenPosition(x, y)
+				ops = append(ops, drawOp{sprite: r.sprite(tile, d2enum.TileShadow), x: sx, y: sy, shadow: true})
+			}
+		}
+	}
+
+	return ops
+}
+
+func (r *Renderer) wallOps(ds1 *d2ds1.DS1, filter func(d2enum.TileType) bool) []drawOp {
+	var ops []drawOp
+
+	forEachDiagonal(ds1, func(_ int, tiles []image.Point) {
+		for _, p := range tiles {
+			ops = append(ops, r.tileWallOps(ds1, p.X, p.Y, filter)...)
+		}
+	})
+
+	return ops
+}
+
+func (r *Renderer) tileWallOps(ds1 *d2ds1.DS1, x, y int, filter func(d2enum.TileType) bool) []drawOp {
+	var ops []drawOp
+
+	for idx := range ds1.Tiles[y][x].Walls {
+		wall := &ds1.Tiles[y][x].Walls[idx]
+		if wall.Prop1 == 0 || wall.Hidden || !filter(wall.Type) {
+			continue
+		}
+
+		tile := r.findTile(wall.Type, wall.Style, wall.Sequence, d2dt1.CellSeed(r.Seed, x, y, d2dt1.WallSeedLayer+idx))
+		if tile == nil {
+			continue
+		}
+
+		sx, sy := screenPosition(x, y)
+		ops = append(ops, drawOp{sprite: r.sprite(tile, wall.Type), x: sx, y: sy})
+	}
+
+	return ops
+}
+
+// objectsByDiagonal groups the objects by the diagonal (x + y) of the map cell they stand on
+func objectsByDiagonal(ds1 *d2ds1.DS1) map[int][]*d2ds1.Object {
+	result := make(map[int][]*d2ds1.Object)
+
+	for idx := range ds1.Objects {
+		obj := &ds1.Objects[idx]
+		diagonal := obj.X/subTilesPerTile + obj.Y/subTilesPerTile
+		diagonal = max(0, min(diagonal, int(ds1.Width+ds1.Height)-2)) //nolint:gomnd // last diagonal
+
+		result[diagonal] = append(result[diagonal], obj)
+	}
+
+	return result
+}
+
+// findTile selects a variant of the tile with the given type, style and sequence
+func (r *Renderer) findTile(tileType d2enum.TileType, style, sequence byte, seed uint64) *d2dt1.Tile {
+	return r.tiles.Select(int32(tileType), int32(style), int32(sequence), seed)
+}
+
+func (r *Renderer) sprite(tile *d2dt1.Tile, tileType d2enum.TileType) *sprite {
+	key := spriteKey{tile: tile, tileType: tileType}
+
+	if s, found := r.sprites[key]; found {
+		return s
+	}
+
+	var s *sprite
+
+	switch {
+	case tileType == d2enum.TileFloor:
+		s = newFloorSprite(tile)
+	case tileType == d2enum.TileRightPartOfNorthCornerWall:
+		// the north corner is made of two tiles, the left part has its own tile type
+		tiles := []*d2dt1.Tile{tile}
+		if left := r.tiles.Lookup(int32(d2enum.TileLeftPartOfNorthCornerWall), tile.Style, tile.Sequence,
+			tile.RarityFrameIndex); left != nil {
+			tiles = append(tiles, left)
+		}
+
+		s = newWallSprite(tiles...)
+	default:
+		s = newWallSprite(tile)
+	}
+
+	r.sprites[key] = s
+
+	return s
+}
+
+func (r *Renderer) draw(img *image.RGBA, op drawOp, origin image.Point) {
+	s := op.sprite
+	left := op.x + s.offsetX - origin.X
+	top := op.y + s.offsetY - origin.Y
+
+	for y := 0; y < s.height; y++ {
+		for x := 0; x < s.width; x++ {
+			idx := s.pixels[y*s.width+x]
+			if idx == 0 {
+				continue
+			}
+
+			offset := img.PixOffset(left+x, top+y)
+
+			if op.shadow {
+				for c := 0; c < 3; c++ {
+					img.Pix[offset+c] = byte(int(img.Pix[offset+c]) * (opaque - shadowAlpha) / opaque)
+				}
+
+				img.Pix[offset+3] = byte(max(int(img.Pix[offset+3]), shadowAlpha))
+
+				continue
+			}
+
+			c := r.palette[idx]
+			img.Pix[offset], img.Pix[offset+1], img.Pix[offset+2], img.Pix[offset+3] = c.R, c.G, c.B, c.A
+		}
+	}
+}
+
+func (op drawOp) rect() image.Rectangle {
+	left, top := op.x+op.sprite.offsetX, op.y+op.sprite.offsetY
+
+	return image.Rect(left, top, left+op.sprite.width, top+op.sprite.height)
+}
+
+// screenPosition returns the position of the top corner of a map cell
+func screenPosition(x, y int) (sx, sy int) {
+	return (x - y) * halfTileWidth, (x + y) * halfTileHeight
+}
+
+// objectPosition returns the screen position of an object, objects are placed on sub-tiles
+func objectPosition(obj *d2ds1.Object) (sx, sy int) {
+	const (
+		subTileHalfWidth  = halfTileWidth / subTilesPerTile
+		subTileHalfHeight = halfTileHeight / subTilesPerTile
+	)
+
+	return (obj.X-obj.Y)*subTileHalfWidth + halfTileWidth, (obj.X + obj.Y) * subTileHalfHeight
+}
+
+// mapBounds returns the screen area covered by the floor of the map
+func mapBounds(ds1 *d2ds1.DS1) image.Rectangle {
+	w, h := int(ds1.Width), int(ds1.Height)
+
+	return image.Rect(-(h-1)*halfTileWidth, 0, (w-1)*halfTileWidth+tileWidth, (w+h-2)*halfTileHeight+tileHeight)
+}
+
+// forEachDiagonal visits the map cells from the back of the map to the front
+func forEachDiagonal(ds1 *d2ds1.DS1, fn func(diagonal int, tiles []image.Point)) {
+	w, h := int(ds1.Width), int(ds1.Height)
+
+	for diagonal := 0; diagonal < w+h-1; diagonal++ {
+		var tiles []image.Point
+
+		for x := max(0, diagonal-h+1); x <= min(diagonal, w-1); x++ {
+			tiles = append(tiles, image.Point{X: x, Y: diagonal - x})
+		}
+
+		fn(diagonal, tiles)
+	}
+}
+
+func isLowerWall(t d2enum.TileType) bool {
+	return t.LowerWall()
+}
+
+func isUpperWall(t d2enum.TileType) bool {
+	return t.UpperWall() && t != d2enum.TileLeftPartOfNorthCornerWall
+}
+
+func isRoof(t d2enum.TileType) bool {
+	return t == d2enum.TileRoof
+}
+
+// RenderPNG renders the whole map and writes it to w as a PNG image
+func (r *Renderer) RenderPNG(ds1 *d2ds1.DS1, w io.Writer) error {
+	img, err := r.Render(ds1)
+	if err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+// DrawObjectMarker is an ObjectDrawer that marks the position of every object with a small cross
+func DrawObjectMarker(img *image.RGBA, _ *d2ds1.Object, x, y int) {
+	const markerSize = 3
+
+	marker := color.RGBA{R: opaque, A: opaque}
+
+	for d := -markerSize; d <= markerSize; d++ {
+		img.SetRGBA(x+d, y, marker)
+		img.SetRGBA(x, y+d, marker)
+	}
+}
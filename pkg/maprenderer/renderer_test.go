@@ -0,0 +1,224 @@
+package maprenderer
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+
+	d2dat "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/datfile"
+	d2ds1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/ds1file"
+	d2dt1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/dt1file"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const (
+	floorColor  = 1
+	floorColor2 = 2
+	wallColor   = 3
+	roofColor   = 4
+	shadowColor = 5
+)
+
+// isoBlock returns an isometric block filled with one palette index
+func isoBlock(x, y int16, colorIndex byte) d2dt1.Block {
+	return d2dt1.Block{
+		X: x, Y: y, Format: d2dt1.BlockFormatIsometric,
+		EncodedData: bytes.Repeat([]byte{colorIndex}, 256), Length: 256,
+	}
+}
+
+// rleBlock returns a 32x32 RLE block filled with one palette index
+func rleBlock(x, y int16, colorIndex byte) d2dt1.Block {
+	const size = 32
+
+	var data []byte
+
+	for row := 0; row < size; row++ {
+		data = append(data, 0, size)
+		data = append(data, bytes.Repeat([]byte{colorIndex}, size)...)
+		data = append(data, 0, 0)
+	}
+
+	return d2dt1.Block{X: x, Y: y, Format: d2dt1.BlockFormatRLE, EncodedData: data, Length: int32(len(data))}
+}
+
+func floorTile(style, sequence int32, colorIndex byte) d2dt1.Tile {
+	tile := d2dt1.Tile{Type: int32(d2enum.TileFloor), Style: style, Sequence: sequence, Width: tileWidth, Height: tileHeight}
+
+	for gy := int16(0); gy < subTilesPerTile; gy++ {
+		for gx := int16(0); gx < subTilesPerTile; gx++ {
+			tile.Blocks = append(tile.Blocks, isoBlock(64+(gx-gy)*16, (gx+gy)*8, colorIndex))
+		}
+	}
+
+	return tile
+}
+
+func wallTile(tileType d2enum.TileType, colorIndex byte, rows int16) d2dt1.Tile {
+	tile := d2dt1.Tile{Type: int32(tileType), Width: tileWidth, Height: -int32(rows) * blockHeight}
+
+	for row := int16(1); row <= rows; row++ {
+		for x := int16(0); x < tileWidth; x += blockHeight {
+			tile.Blocks = append(tile.Blocks, rleBlock(x, -row*blockHeight, colorIndex))
+		}
+	}
+
+	return tile
+}
+
+func testRenderer(t *testing.T) *Renderer {
+	palette := make([]byte, numColors*3)
+	for idx, c := range [][3]byte{{0, 0, 0}, {40, 120, 40}, {40, 40, 160}, {160, 160, 160}, {160, 40, 40}, {0, 0, 0}} {
+		palette[idx*3], palette[idx*3+1], palette[idx*3+2] = c[2], c[1], c[0]
+	}
+
+	pal, err := d2dat.Load(palette)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roof := wallTile(d2enum.TileRoof, roofColor, 1)
+	roof.RoofHeight = 96
+
+	dt1 := &d2dt1.DT1{Tiles: []d2dt1.Tile{
+		floorTile(0, 0, floorColor),
+		floorTile(0, 1, floorColor2),
+		wallTile(d2enum.TileLeftWall, wallColor, 3),
+		wallTile(d2enum.TileShadow, shadowColor, 1),
+		roof,
+	}}
+
+	r, err := New([]*d2dt1.DT1{dt1}, pal)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.ObjectDrawer = DrawObjectMarker
+
+	return r
+}
+
+func testMap(t *testing.T) *d2ds1.DS1 {
+	ds1, err := d2ds1.NewDS1(18, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ds1.SetNumberOfWalls(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			floor := d2ds1.FloorShadowRecord{Prop1: 1, Sequence: byte((x + y) % 2)}
+			if err := ds1.SetFloor(x, y, 0, floor); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for y := 0; y < 3; y++ {
+		if err := ds1.SetWall(1, y, 0, d2ds1.WallRecord{Type: d2enum.TileLeftWall, Prop1: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ds1.SetShadow(2, y, d2ds1.FloorShadowRecord{Prop1: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ds1.SetWall(3, 2, 1, d2ds1.WallRecord{Type: d2enum.TileRoof, Prop1: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	ds1.AddObject(d2ds1.Object{Type: 1, ID: 1, X: 12, Y: 7})
+
+	return ds1
+}
+
+func TestRenderer_Render(t *testing.T) {
+	r := testRenderer(t)
+
+	var buf bytes.Buffer
+	if err := r.RenderPNG(testMap(t), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "map.png")
+
+	if *update {
+		if err := ioutil.WriteFile(golden, buf.Bytes(), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(expected, buf.Bytes()) {
+		t.Errorf("rendered map differs from %s, run the tests with -update to regenerate it", golden)
+	}
+}
+
+func TestRenderer_Deterministic(t *testing.T) {
+	ds1 := testMap(t)
+
+	first, err := testRenderer(t).Render(ds1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := testRenderer(t).Render(ds1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !first.Bounds().Eq(second.Bounds()) || !bytes.Equal(first.Pix, second.Pix) {
+		t.Error("rendering the same map twice gave different images")
+	}
+}
+
+func TestRenderer_Layers(t *testing.T) {
+	r := testRenderer(t)
+	ds1 := testMap(t)
+
+	img, err := r.Render(ds1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the wall tiles rise above the floor of the first row, so the image is
+	// taller than the floor area of the map
+	floor := mapBounds(ds1)
+	if img.Bounds().Dy() <= floor.Dy() {
+		t.Errorf("expected the walls to extend the image above the floor, got %v", img.Bounds())
+	}
+
+	// hidden records are not drawn
+	ds1.Tiles[0][0].Floors[0].Hidden = true
+
+	hidden, err := r.Render(ds1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(img.Pix, hidden.Pix) {
+		t.Error("expected hiding a floor to change the image")
+	}
+}
+
+func TestRenderer_ShortRow(t *testing.T) {
+	ds1 := testMap(t)
+	ds1.Tiles[1] = ds1.Tiles[1][:2]
+
+	if _, err := testRenderer(t).Render(ds1); err == nil {
+		t.Error("expected an error for a row shorter than the map width")
+	}
+}
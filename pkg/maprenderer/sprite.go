@@ -0,0 +1,94 @@
+package maprenderer
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+
+	d2dt1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/dt1file"
+)
+
+const (
+	blockHeight = 32
+)
+
+// sprite is a decoded, palette indexed tile. The offset is relative to the
+// screen position of the top corner of the map cell the tile is drawn in.
+type sprite struct {
+	pixels  []byte
+	width   int
+	height  int
+	offsetX int
+	offsetY int
+}
+
+// newFloorSprite decodes a floor tile, floors cover exactly one map cell
+func newFloorSprite(tile *d2dt1.Tile) *sprite {
+	width, height := int(tile.Width), abs(int(tile.Height))
+	minY, maxY := 0, 0
+
+	for idx := range tile.Blocks {
+		minY = min(minY, int(tile.Blocks[idx].Y))
+		maxY = max(maxY, int(tile.Blocks[idx].Y)+blockHeight)
+	}
+
+	height = max(height, maxY-minY)
+	pixels := make([]byte, width*height)
+	d2dt1.DecodeTileGfxData(tile.Blocks, &pixels, int32(-minY), int32(width))
+
+	return &sprite{pixels: pixels, width: width, height: height, offsetY: minY}
+}
+
+// newWallSprite decodes one or more wall, roof or shadow tiles into a single
+// sprite. Wall blocks are placed relative to the bottom of the map cell, roofs
+// are raised by the roof height of the first tile.
+func newWallSprite(tiles ...*d2dt1.Tile) *sprite {
+	minY, maxY := 0, 0
+	height := 0
+
+	for _, tile := range tiles {
+		height = max(height, abs(int(tile.Height)))
+
+		for idx := range tile.Blocks {
+			minY = min(minY, int(tile.Blocks[idx].Y))
+			maxY = max(maxY, int(tile.Blocks[idx].Y)+blockHeight)
+		}
+	}
+
+	height = max(height, maxY-minY)
+	pixels := make([]byte, tileWidth*height)
+
+	for _, tile := range tiles {
+		d2dt1.DecodeTileGfxData(tile.Blocks, &pixels, int32(-minY), tileWidth)
+	}
+
+	result := &sprite{pixels: pixels, width: tileWidth, height: height, offsetY: minY + tileHeight}
+
+	if d2enum.TileType(tiles[0].Type) == d2enum.TileRoof {
+		result.offsetY = -int(tiles[0].RoofHeight)
+	}
+
+	return result
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,3 @@
+// Package pathfinding finds paths for units on the sub-tile grid of a
+// collision map.
+package pathfinding
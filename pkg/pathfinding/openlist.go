@@ -0,0 +1,44 @@
+package pathfinding
+
+// node is a sub-tile in the open list of a search
+type node struct {
+	index     int
+	cost      int // path cost plus heuristic
+	heuristic int
+	order     int // ties are broken by insertion order so that searches are deterministic
+}
+
+// openList is a priority queue of nodes, it implements heap.Interface
+type openList []node
+
+func (l openList) Len() int {
+	return len(l)
+}
+
+func (l openList) Less(i, j int) bool {
+	if l[i].cost != l[j].cost {
+		return l[i].cost < l[j].cost
+	}
+
+	if l[i].heuristic != l[j].heuristic {
+		return l[i].heuristic < l[j].heuristic
+	}
+
+	return l[i].order < l[j].order
+}
+
+func (l openList) Swap(i, j int) {
+	l[i], l[j] = l[j], l[i]
+}
+
+func (l *openList) Push(x interface{}) {
+	*l = append(*l, x.(node))
+}
+
+func (l *openList) Pop() interface{} {
+	old := *l
+	n := old[len(old)-1]
+	*l = old[:len(old)-1]
+
+	return n
+}
@@ -0,0 +1,318 @@
+package pathfinding
+
+import (
+	"container/heap"
+	"image"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2math/d2vector"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2path"
+
+	"github.com/OpenDiablo2/AbyssEngine/pkg/collision"
+)
+
+// Mask selects the sub-tile flags that block a unit, the bits are the bits of
+// the DT1 sub-tile flags
+type Mask byte
+
+// Sub-tile flags that can block a unit
+const (
+	BlockWalk       Mask = 0x01
+	BlockLOS        Mask = 0x02
+	BlockJump       Mask = 0x04
+	BlockPlayerWalk Mask = 0x08
+)
+
+// Masks of the different kinds of units
+const (
+	MonsterMask = BlockWalk
+	PlayerMask  = BlockWalk | BlockPlayerWalk
+)
+
+const (
+	straightCost = 10
+	diagonalCost = 14
+	noParent     = -1
+)
+
+// Options configure a path search
+type Options struct {
+	// Mask selects the sub-tile flags that block the unit, MonsterMask is used when it is 0
+	Mask Mask
+
+	// CutCorners allows diagonal steps next to a blocked sub-tile. Diagonal
+	// steps between two blocked sub-tiles are never allowed.
+	CutCorners bool
+
+	// Smooth removes the waypoints that can be skipped by walking in a straight line
+	Smooth bool
+
+	// MaxNodes limits the number of sub-tiles that are visited, 0 means no limit
+	MaxNodes int
+}
+
+// Finder searches paths on a collision map. It keeps its buffers between
+// searches, a Finder must not be used by several goroutines at once.
+type Finder struct {
+	grid       *collision.Map
+	cost       []int
+	parent     []int
+	visited    []uint32
+	closed     []uint32
+	generation uint32
+	pushed     int
+	open       openList
+}
+
+// NewFinder creates a path finder for the collision map
+func NewFinder(grid *collision.Map) *Finder {
+	size := grid.Width() * grid.Height()
+
+	return &Finder{
+		grid:    grid,
+		cost:    make([]int, size),
+		parent:  make([]int, size),
+		visited: make([]uint32, size),
+		closed:  make([]uint32, size),
+	}
+}
+
+// Find searches a path from one sub-tile to another using 8-directional A*.
+// The path starts with the first step after from and ends with to, in the
+// form used by the NPC paths of DS1 objects. When to can not be reached, the
+// path leads to the reachable sub-tile closest to it and complete is false.
+func Find(grid *collision.Map, from, to image.Point, opts Options) (path []d2path.Path, complete bool) {
+	return NewFinder(grid).Find(from, to, opts)
+}
+
+// Find searches a path, see the Find function of the package
+func (f *Finder) Find(from, to image.Point, opts Options) (path []d2path.Path, complete bool) {
+	if opts.Mask == 0 {
+		opts.Mask = MonsterMask
+	}
+
+	if !f.grid.Contains(from.X, from.Y) {
+		return nil, false
+	}
+
+	points, complete := f.search(from, to, opts)
+
+	if opts.Smooth {
+		points = f.smooth(from, points, opts)
+	}
+
+	path = make([]d2path.Path, len(points))
+
+	for idx, p := range points {
+		path[idx].Position = d2vector.NewPosition(float64(p.X), float64(p.Y))
+	}
+
+	return path, complete
+}
+
+// search runs A* and returns every sub-tile of the path after from
+//
+//nolint:gocognit // A* in one place is easier to follow
+func (f *Finder) search(from, to image.Point, opts Options) ([]image.Point, bool) {
+	f.nextSearch()
+
+	start := f.index(from)
+	f.visit(start, 0, noParent)
+	f.open = f.open[:0]
+	heap.Push(&f.open, node{index: start, cost: heuristic(from, to), heuristic: heuristic(from, to)})
+
+	closest, closestHeuristic := start, heuristic(from, to)
+	visited := 0
+
+	for f.open.Len() > 0 {
+		current := heap.Pop(&f.open).(node)
+		if f.closed[current.index] == f.generation {
+			continue
+		}
+
+		f.closed[current.index] = f.generation
+		p := f.point(current.index)
+
+		if current.heuristic < closestHeuristic {
+			closest, closestHeuristic = current.index, current.heuristic
+		}
+
+		if p == to {
+			return f.trace(current.index), true
+		}
+
+		visited++
+		if opts.MaxNodes > 0 && visited >= opts.MaxNodes {
+			break
+		}
+
+		for _, dir := range directions {
+			next := p.Add(dir.step)
+			if !f.canStep(p, dir.step, opts) {
+				continue
+			}
+
+			idx := f.index(next)
+			cost := f.cost[current.index] + dir.cost
+
+			if f.closed[idx] == f.generation || (f.visited[idx] == f.generation && f.cost[idx] <= cost) {
+				continue
+			}
+
+			f.visit(idx, cost, current.index)
+
+			h := heuristic(next, to)
+			heap.Push(&f.open, node{index: idx, cost: cost + h, heuristic: h, order: f.pushed})
+			f.pushed++
+		}
+	}
+
+	return f.trace(closest), false
+}
+
+func (f *Finder) nextSearch() {
+	f.generation++
+	f.pushed = 0
+
+	if f.generation == 0 {
+		// the counter wrapped around, forget all previous searches
+		for idx := range f.visited {
+			f.visited[idx], f.closed[idx] = 0, 0
+		}
+
+		f.generation = 1
+	}
+}
+
+func (f *Finder) visit(idx, cost, parent int) {
+	f.visited[idx] = f.generation
+	f.cost[idx] = cost
+	f.parent[idx] = parent
+}
+
+func (f *Finder) trace(idx int) []image.Point {
+	var points []image.Point
+
+	for ; f.parent[idx] != noParent; idx = f.parent[idx] {
+		points = append(points, f.point(idx))
+	}
+
+	for left, right := 0, len(points)-1; left < right; left, right = left+1, right-1 {
+		points[left], points[right] = points[right], points[left]
+	}
+
+	return points
+}
+
+// smooth removes the waypoints that can be skipped by walking straight from
+// the previous waypoint to a later one, the waypoints are skipped greedily
+func (f *Finder) smooth(from image.Point, points []image.Point, opts Options) []image.Point {
+	if len(points) < 2 { //nolint:gomnd // nothing to skip
+		return points
+	}
+
+	var result []image.Point
+
+	current := from
+
+	for idx := 0; idx < len(points); {
+		next := idx
+
+		for next+1 < len(points) && f.canWalkStraight(current, points[next+1], opts) {
+			next++
+		}
+
+		current = points[next]
+		result = append(result, current)
+		idx = next + 1
+	}
+
+	return result
+}
+
+// canWalkStraight returns true when the unit can walk from one sub-tile to
+// another following the line between them
+func (f *Finder) canWalkStraight(from, to image.Point, opts Options) bool {
+	ok := true
+
+	collision.WalkLine(from, to, func(p, step image.Point) bool {
+		ok = f.canStep(p, step, opts)
+		return ok
+	})
+
+	return ok
+}
+
+// canStep returns true when the unit can step from p to the neighbouring sub-tile
+func (f *Finder) canStep(p, step image.Point, opts Options) bool {
+	next := p.Add(step)
+	if f.blocked(next.X, next.Y, opts.Mask) {
+		return false
+	}
+
+	if step.X == 0 || step.Y == 0 {
+		return true
+	}
+
+	blockedX := f.blocked(p.X+step.X, p.Y, opts.Mask)
+	blockedY := f.blocked(p.X, p.Y+step.Y, opts.Mask)
+
+	if opts.CutCorners {
+		return !blockedX || !blockedY
+	}
+
+	return !blockedX && !blockedY
+}
+
+func (f *Finder) blocked(x, y int, mask Mask) bool {
+	if !f.grid.Contains(x, y) {
+		return true
+	}
+
+	flags := f.grid.Flags(x, y)
+
+	return Mask(flags.Encode())&mask != 0
+}
+
+func (f *Finder) index(p image.Point) int {
+	return p.Y*f.grid.Width() + p.X
+}
+
+func (f *Finder) point(idx int) image.Point {
+	return image.Point{X: idx % f.grid.Width(), Y: idx / f.grid.Width()}
+}
+
+// heuristic is the octile distance between two sub-tiles
+func heuristic(a, b image.Point) int {
+	dx, dy := a.X-b.X, a.Y-b.Y
+
+	if dx < 0 {
+		dx = -dx
+	}
+
+	if dy < 0 {
+		dy = -dy
+	}
+
+	if dx < dy {
+		dx, dy = dy, dx
+	}
+
+	return straightCost*(dx-dy) + diagonalCost*dy
+}
+
+type direction struct {
+	step image.Point
+	cost int
+}
+
+//nolint:gochecknoglobals // constant table
+var directions = []direction{
+	{image.Point{X: 1}, straightCost},
+	{image.Point{Y: 1}, straightCost},
+	{image.Point{X: -1}, straightCost},
+	{image.Point{Y: -1}, straightCost},
+	{image.Point{X: 1, Y: 1}, diagonalCost},
+	{image.Point{X: -1, Y: 1}, diagonalCost},
+	{image.Point{X: -1, Y: -1}, diagonalCost},
+	{image.Point{X: 1, Y: -1}, diagonalCost},
+}
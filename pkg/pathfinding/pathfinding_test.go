@@ -0,0 +1,266 @@
+package pathfinding
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2path"
+
+	"github.com/OpenDiablo2/AbyssEngine/pkg/collision"
+	d2dt1 "github.com/OpenDiablo2/AbyssEngine/pkg/fileformats/dt1file"
+)
+
+// testGrid builds a collision map from rows of text: '#' blocks everyone and
+// 'p' blocks players only
+func testGrid(rows ...string) *collision.Map {
+	m := collision.NewMap(len(rows[0]), len(rows))
+
+	for y, row := range rows {
+		for x := range row {
+			switch row[x] {
+			case '#':
+				m.Set(x, y, d2dt1.SubTileFlags{BlockWalk: true, BlockLOS: true})
+			case 'p':
+				m.Set(x, y, d2dt1.SubTileFlags{BlockPlayerWalk: true})
+			}
+		}
+	}
+
+	return m
+}
+
+func points(path []d2path.Path) []image.Point {
+	result := make([]image.Point, len(path))
+
+	for idx := range path {
+		result[idx] = image.Pt(int(path[idx].Position.X()), int(path[idx].Position.Y()))
+	}
+
+	return result
+}
+
+func checkPath(t *testing.T, m *collision.Map, from image.Point, path []d2path.Path, opts Options) {
+	t.Helper()
+
+	f := NewFinder(m)
+	current := from
+
+	for _, p := range points(path) {
+		if !f.canWalkStraight(current, p, opts) {
+			t.Fatalf("can not walk from %v to %v", current, p)
+		}
+
+		current = p
+	}
+}
+
+func TestFind_Straight(t *testing.T) {
+	m := testGrid(
+		".....",
+		".....",
+	)
+
+	path, complete := Find(m, image.Pt(0, 0), image.Pt(4, 0), Options{})
+	if !complete {
+		t.Fatal("expected a complete path")
+	}
+
+	expected := []image.Point{{1, 0}, {2, 0}, {3, 0}, {4, 0}}
+	if actual := points(path); len(actual) != len(expected) || actual[3] != expected[3] || actual[0] != expected[0] {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestFind_AroundWall(t *testing.T) {
+	m := testGrid(
+		"..#....",
+		"..#.##.",
+		"..#..#.",
+		"......#",
+	)
+	from, to := image.Pt(0, 0), image.Pt(3, 0)
+
+	path, complete := Find(m, from, to, Options{})
+	if !complete {
+		t.Fatal("expected a complete path")
+	}
+
+	checkPath(t, m, from, path, Options{})
+
+	if last := points(path)[len(path)-1]; last != to {
+		t.Errorf("expected the path to end at %v, got %v", to, last)
+	}
+
+	// 3 steps down, 1 diagonal step past the wall and 3 steps up, corners can not be cut
+	if len(path) != 8 {
+		t.Errorf("expected 8 steps, got %v", points(path))
+	}
+}
+
+func TestFind_CornerCutting(t *testing.T) {
+	m := testGrid(
+		".#",
+		"..",
+	)
+	from, to := image.Pt(0, 0), image.Pt(1, 1)
+
+	path, _ := Find(m, from, to, Options{})
+	if len(path) != 2 {
+		t.Errorf("expected to walk around the corner, got %v", points(path))
+	}
+
+	path, _ = Find(m, from, to, Options{CutCorners: true})
+	if len(path) != 1 {
+		t.Errorf("expected to cut the corner, got %v", points(path))
+	}
+
+	m = testGrid(
+		".#",
+		"#.",
+	)
+
+	if _, complete := Find(m, from, to, Options{CutCorners: true}); complete {
+		t.Error("diagonal steps between two blocked sub-tiles must not be allowed")
+	}
+}
+
+func TestFind_Masks(t *testing.T) {
+	m := testGrid(
+		"..p..",
+		"##p##",
+		".....",
+	)
+	from, to := image.Pt(0, 0), image.Pt(0, 2)
+
+	monster, complete := Find(m, from, to, Options{Mask: MonsterMask})
+	if !complete || len(monster) != 6 {
+		t.Errorf("expected monsters to walk through, got %v", points(monster))
+	}
+
+	if _, complete := Find(m, from, to, Options{Mask: PlayerMask}); complete {
+		t.Error("expected players to be blocked")
+	}
+}
+
+func TestFind_Partial(t *testing.T) {
+	m := testGrid(
+		"...#...",
+		"...#...",
+		"...#...",
+	)
+	from, to := image.Pt(0, 1), image.Pt(6, 1)
+
+	path, complete := Find(m, from, to, Options{})
+	if complete {
+		t.Fatal("expected a partial path")
+	}
+
+	if last := points(path)[len(path)-1]; last != image.Pt(2, 1) {
+		t.Errorf("expected the path to end next to the wall, got %v", points(path))
+	}
+
+	// the start is the closest reachable sub-tile
+	m = testGrid(
+		".#.",
+		"##.",
+	)
+
+	path, complete = Find(m, image.Pt(0, 0), image.Pt(2, 1), Options{})
+	if complete || len(path) != 0 {
+		t.Errorf("expected an empty partial path, got %v", points(path))
+	}
+}
+
+func TestFind_Smooth(t *testing.T) {
+	m := testGrid(
+		"..........",
+		"..........",
+		"...####...",
+		"..........",
+		"..........",
+	)
+	from, to := image.Pt(0, 4), image.Pt(9, 0)
+
+	raw, _ := Find(m, from, to, Options{})
+	smooth, complete := Find(m, from, to, Options{Smooth: true})
+
+	if !complete {
+		t.Fatal("expected a complete path")
+	}
+
+	if len(smooth) >= len(raw) {
+		t.Errorf("expected smoothing to remove waypoints, got %v", points(smooth))
+	}
+
+	checkPath(t, m, from, smooth, Options{})
+
+	if last := points(smooth)[len(smooth)-1]; last != to {
+		t.Errorf("expected the path to end at %v, got %v", to, last)
+	}
+}
+
+func TestFinder_Reuse(t *testing.T) {
+	m := testGrid(
+		"....",
+		".##.",
+		"....",
+	)
+	f := NewFinder(m)
+
+	first, _ := f.Find(image.Pt(0, 0), image.Pt(3, 2), Options{})
+	f.Find(image.Pt(3, 0), image.Pt(0, 2), Options{})
+	second, _ := f.Find(image.Pt(0, 0), image.Pt(3, 2), Options{})
+
+	a, b := points(first), points(second)
+	if len(a) != len(b) {
+		t.Fatalf("expected the same path, got %v and %v", a, b)
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			t.Fatalf("expected the same path, got %v and %v", a, b)
+		}
+	}
+}
+
+func randomGrid(size int, density float64) *collision.Map {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec // benchmark data
+	m := collision.NewMap(size, size)
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if rng.Float64() < density {
+				m.Set(x, y, d2dt1.SubTileFlags{BlockWalk: true})
+			}
+		}
+	}
+
+	m.Set(0, 0, d2dt1.SubTileFlags{})
+	m.Set(size-1, size-1, d2dt1.SubTileFlags{})
+
+	return m
+}
+
+func benchmarkFind(b *testing.B, size int, density float64, opts Options) {
+	m := randomGrid(size, density)
+	f := NewFinder(m)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f.Find(image.Pt(0, 0), image.Pt(size-1, size-1), opts)
+	}
+}
+
+func BenchmarkFind_Open512(b *testing.B) {
+	benchmarkFind(b, 512, 0, Options{})
+}
+
+func BenchmarkFind_Random512(b *testing.B) {
+	benchmarkFind(b, 512, 0.2, Options{})
+}
+
+func BenchmarkFind_Random1024Smooth(b *testing.B) {
+	benchmarkFind(b, 1024, 0.2, Options{Smooth: true, CutCorners: true})
+}